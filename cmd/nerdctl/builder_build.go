@@ -75,6 +75,7 @@ If Dockerfile is not present and -f is not specified, it will look for Container
 	// #endregion
 
 	buildCommand.Flags().String("iidfile", "", "Write the image ID to the file")
+	buildCommand.Flags().String("metadata-file", "", "Write the build metadata (image digest and name, build ID, durations, cache hits and misses) to the file in JSON")
 	buildCommand.Flags().StringArray("label", nil, "Set metadata for an image")
 
 	return buildCommand
@@ -98,7 +99,7 @@ func processBuildCommandFlag(cmd *cobra.Command, args []string) (types.BuilderBu
 		return types.BuilderBuildOptions{}, errors.New("context needs to be specified")
 	}
 	buildContext := args[0]
-	if buildContext == "-" || strings.Contains(buildContext, "://") {
+	if strings.Contains(buildContext, "://") {
 		return types.BuilderBuildOptions{}, fmt.Errorf("unsupported build context: %q", buildContext)
 	}
 	output, err := cmd.Flags().GetString("output")
@@ -161,6 +162,10 @@ func processBuildCommandFlag(cmd *cobra.Command, args []string) (types.BuilderBu
 	if err != nil {
 		return types.BuilderBuildOptions{}, err
 	}
+	metadataFile, err := cmd.Flags().GetString("metadata-file")
+	if err != nil {
+		return types.BuilderBuildOptions{}, err
+	}
 	quiet, err := cmd.Flags().GetBool("quiet")
 	if err != nil {
 		return types.BuilderBuildOptions{}, err
@@ -213,6 +218,7 @@ func processBuildCommandFlag(cmd *cobra.Command, args []string) (types.BuilderBu
 		CacheTo:              cacheTo,
 		Rm:                   rm,
 		IidFile:              iidfile,
+		MetadataFile:         metadataFile,
 		Quiet:                quiet,
 		Platform:             platform,
 		Stdout:               cmd.OutOrStdout(),
@@ -17,6 +17,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -166,6 +167,30 @@ CMD ["echo", "nerdctl-build-test-stdin"]
 	base.Cmd("build", "-t", imageName, "-f", "-", ".").CmdOption(testutil.WithStdin(strings.NewReader(dockerfile))).AssertCombinedOutContains(imageName)
 }
 
+func TestBuildContextFromStdin(t *testing.T) {
+	t.Parallel()
+	testutil.RequiresBuild(t)
+	base := testutil.NewBase(t)
+	defer base.Cmd("builder", "prune").Run()
+	imageName := testutil.Identifier(t)
+	defer base.Cmd("rmi", imageName).Run()
+
+	dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-build-test-stdin-context"]
+	`, testutil.CommonImage)
+	base.Cmd("build", "-t", imageName, "-").CmdOption(testutil.WithStdin(strings.NewReader(dockerfile))).AssertOK()
+	base.Cmd("run", "--rm", imageName).AssertOutExactly("nerdctl-build-test-stdin-context\n")
+
+	// there is no build context to copy from
+	dockerfile = fmt.Sprintf(`FROM %s
+COPY Dockerfile /
+	`, testutil.CommonImage)
+	base.Cmd("build", "-t", imageName, "-").CmdOption(testutil.WithStdin(strings.NewReader(dockerfile))).AssertFail()
+
+	// the Dockerfile cannot be read from stdin twice
+	base.Cmd("build", "-t", imageName, "-f", "-", "-").CmdOption(testutil.WithStdin(strings.NewReader(dockerfile))).AssertFail()
+}
+
 func TestBuildWithDockerfile(t *testing.T) {
 	testutil.RequiresBuild(t)
 	base := testutil.NewBase(t)
@@ -318,6 +343,50 @@ CMD ["echo", "nerdctl-build-test-string"]
 	base.Cmd("run", "--rm", string(imageID)).AssertOutExactly("nerdctl-build-test-string\n")
 }
 
+func TestBuildWithMetadataFile(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	testutil.RequiresBuild(t)
+	base := testutil.NewBase(t)
+	defer base.Cmd("builder", "prune").Run()
+	imageName := testutil.Identifier(t)
+	defer base.Cmd("rmi", imageName).Run()
+
+	dockerfile := fmt.Sprintf(`FROM %s
+RUN echo nerdctl-build-test-metadata > /metadata.txt
+	`, testutil.CommonImage)
+	buildCtx := createBuildContext(t, dockerfile)
+	fileName := filepath.Join(t.TempDir(), "metadata.json")
+
+	type metadata struct {
+		Digest string `json:"containerimage.digest"`
+		Name   string `json:"image.name"`
+		ID     string `json:"nerdctl.build.id"`
+		Cache  *struct {
+			Hits   int `json:"hits"`
+			Misses int `json:"misses"`
+		} `json:"nerdctl.build.cache"`
+	}
+	var m metadata
+	for i := 0; i < 2; i++ {
+		base.Cmd("build", "--progress=plain", "-t", imageName, "--metadata-file", fileName, buildCtx).AssertOK()
+		b, err := os.ReadFile(fileName)
+		assert.NilError(t, err)
+		assert.NilError(t, json.Unmarshal(b, &m), string(b))
+		assert.Assert(t, strings.HasPrefix(m.Digest, "sha256:"), string(b))
+		assert.Assert(t, strings.Contains(m.Name, imageName), string(b))
+		assert.Assert(t, m.ID != "", string(b))
+		assert.Assert(t, m.Cache != nil, string(b))
+	}
+	// the RUN step of the second build is cached
+	assert.Assert(t, m.Cache.Hits > 0)
+
+	// failing to write the metadata does not fail the build
+	res := base.Cmd("build", "-t", imageName, "--metadata-file", filepath.Join(t.TempDir(), "nonexistent", "metadata.json"), buildCtx).Run()
+	assert.Equal(t, res.ExitCode, 0, res.Combined())
+	assert.Assert(t, strings.Contains(res.Combined(), "failed to write the build metadata"), res.Combined())
+}
+
 func TestBuildWithLabels(t *testing.T) {
 	t.Parallel()
 	testutil.RequiresBuild(t)
@@ -335,6 +404,15 @@ LABEL name=nerdctl-build-test-label
 	defer base.Cmd("rmi", imageName).Run()
 
 	base.Cmd("inspect", imageName, "--format", "{{json .Config.Labels }}").AssertOutExactly("{\"label\":\"test\",\"name\":\"nerdctl-build-test-label\"}\n")
+
+	// --label is additive, and overrides LABEL in the Dockerfile
+	base.Cmd("build", "-t", imageName, buildCtx,
+		"--label", "name=override",
+		"--label", "version=1.0",
+		"--label", "org.opencontainers.image.title=My App",
+		"--label", "empty").AssertOK()
+	base.Cmd("image", "inspect", imageName, "--format", "{{json .Config.Labels }}").
+		AssertOutExactly("{\"empty\":\"\",\"name\":\"override\",\"org.opencontainers.image.title\":\"My App\",\"version\":\"1.0\"}\n")
 }
 
 func TestBuildMultipleTags(t *testing.T) {
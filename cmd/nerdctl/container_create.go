@@ -89,10 +89,15 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.Userns, err = cmd.Flags().GetString("userns")
+	if err != nil {
+		return
+	}
 	opt.StopSignal, err = cmd.Flags().GetString("stop-signal")
 	if err != nil {
 		return
 	}
+	opt.StopSignalChanged = cmd.Flags().Changed("stop-signal")
 	opt.StopTimeout, err = cmd.Flags().GetInt("stop-timeout")
 	if err != nil {
 		return
@@ -141,6 +146,14 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.CPURealtimeRuntime, err = cmd.Flags().GetInt64("cpu-rt-runtime")
+	if err != nil {
+		return
+	}
+	opt.CPURealtimePeriod, err = cmd.Flags().GetUint64("cpu-rt-period")
+	if err != nil {
+		return
+	}
 	opt.CPUShares, err = cmd.Flags().GetUint64("cpu-shares")
 	if err != nil {
 		return
@@ -209,6 +222,10 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.HugetlbLimit, err = cmd.Flags().GetStringSlice("hugetlb-limit")
+	if err != nil {
+		return
+	}
 	// #endregion
 
 	// #region for intel RDT flags
@@ -236,6 +253,10 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.GroupFile, err = cmd.Flags().GetString("group-file")
+	if err != nil {
+		return
+	}
 	// #endregion
 
 	// #region for security flags
@@ -243,6 +264,14 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	noNewPrivileges, err := cmd.Flags().GetBool("no-new-privileges")
+	if err != nil {
+		return
+	}
+	if noNewPrivileges {
+		// equivalent to `--security-opt no-new-privileges`
+		opt.SecurityOpt = append(opt.SecurityOpt, "no-new-privileges")
+	}
 	opt.CapAdd, err = cmd.Flags().GetStringSlice("cap-add")
 	if err != nil {
 		return
@@ -255,6 +284,22 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.Mask, err = cmd.Flags().GetStringArray("mask")
+	if err != nil {
+		return
+	}
+	opt.Unmask, err = cmd.Flags().GetStringArray("unmask")
+	if err != nil {
+		return
+	}
+	opt.ReadonlyPaths, err = cmd.Flags().GetStringArray("readonly-paths")
+	if err != nil {
+		return
+	}
+	opt.NoReadonlyPaths, err = cmd.Flags().GetStringArray("no-readonly-paths")
+	if err != nil {
+		return
+	}
 	opt.Systemd, err = cmd.Flags().GetString("systemd")
 	if err != nil {
 		return
@@ -270,6 +315,10 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.OCISpecFile, err = cmd.Flags().GetString("oci-spec-file")
+	if err != nil {
+		return
+	}
 	// #endregion
 
 	// #region for volume flags
@@ -321,6 +370,14 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.EnvReplaceAll, err = cmd.Flags().GetBool("env-replace-all")
+	if err != nil {
+		return
+	}
+	opt.EnvAppend, err = cmd.Flags().GetBool("env-append")
+	if err != nil {
+		return
+	}
 	// #endregion
 
 	// #region for metadata flags
@@ -375,6 +432,10 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	opt.NoShm, err = cmd.Flags().GetBool("no-shm")
+	if err != nil {
+		return
+	}
 	// #endregion
 
 	// #region for gpu flags
@@ -389,6 +450,11 @@ func processContainerCreateOptions(cmd *cobra.Command) (opt types.ContainerCreat
 	if err != nil {
 		return
 	}
+	addUlimit, err := cmd.Flags().GetStringSlice("add-ulimit")
+	if err != nil {
+		return
+	}
+	opt.Ulimit = append(opt.Ulimit, addUlimit...)
 	// #endregion
 
 	// #region for ipfs flags
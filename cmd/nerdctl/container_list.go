@@ -18,16 +18,19 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"text/tabwriter"
 	"text/template"
 
+	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
 
 	"github.com/spf13/cobra"
 )
@@ -47,6 +50,8 @@ func newPsCommand() *cobra.Command {
 	psCommand.Flags().Bool("no-trunc", false, "Don't truncate output")
 	psCommand.Flags().BoolP("quiet", "q", false, "Only display container IDs")
 	psCommand.Flags().BoolP("size", "s", false, "Display total file sizes")
+	psCommand.Flags().Bool("digests", false, "Show the digest of the image each container was created from (also shown with --no-trunc)")
+	psCommand.Flags().Bool("pod", false, "Group the containers by pod (the nerdctl/pod label, set with --label)")
 
 	// Alias "-f" is reserved for "--filter"
 	psCommand.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}', 'wide'")
@@ -54,6 +59,8 @@ func newPsCommand() *cobra.Command {
 		return []string{"json", "table", "wide"}, cobra.ShellCompDirectiveNoFileComp
 	})
 	psCommand.Flags().StringSliceP("filter", "f", nil, "Filter matches containers based on given conditions")
+	psCommand.Flags().String("before", "", "Show only containers created before the given container (ID or name), same as --filter before=CONTAINER")
+	psCommand.Flags().String("since", "", "Show only containers created after the given container (ID or name), same as --filter since=CONTAINER")
 	return psCommand
 }
 
@@ -83,6 +90,15 @@ func processOptions(cmd *cobra.Command) (types.ContainerListOptions, FormattingA
 	if err != nil {
 		return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
 	}
+	for _, name := range []string{"before", "since"} {
+		ref, err := cmd.Flags().GetString(name)
+		if err != nil {
+			return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
+		}
+		if ref != "" {
+			filters = append(filters, name+"="+ref)
+		}
+	}
 
 	noTrunc, err := cmd.Flags().GetBool("no-trunc")
 	if err != nil {
@@ -99,6 +115,16 @@ func processOptions(cmd *cobra.Command) (types.ContainerListOptions, FormattingA
 		return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
 	}
 
+	digests, err := cmd.Flags().GetBool("digests")
+	if err != nil {
+		return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
+	}
+
+	pod, err := cmd.Flags().GetBool("pod")
+	if err != nil {
+		return types.ContainerListOptions{}, FormattingAndPrintingOptions{}, err
+	}
+
 	size := false
 	if !quiet {
 		size, err = cmd.Flags().GetBool("size")
@@ -115,10 +141,12 @@ func processOptions(cmd *cobra.Command) (types.ContainerListOptions, FormattingA
 			Size:     size || (format == "wide" && !quiet),
 			Filters:  filters,
 		}, FormattingAndPrintingOptions{
-			Stdout: cmd.OutOrStdout(),
-			Quiet:  quiet,
-			Format: format,
-			Size:   size,
+			Stdout:  cmd.OutOrStdout(),
+			Quiet:   quiet,
+			Format:  format,
+			Size:    size,
+			Digests: digests || noTrunc,
+			Pod:     pod,
 		}, nil
 }
 
@@ -151,9 +179,16 @@ type FormattingAndPrintingOptions struct {
 	Format string
 	// Display total file sizes.
 	Size bool
+	// Display the DIGEST column of the images.
+	Digests bool
+	// Group the containers by pod.
+	Pod bool
 }
 
 func formatAndPrintContainerInfo(containers []container.ListItem, options FormattingAndPrintingOptions) error {
+	if options.Pod {
+		return printContainerInfoByPod(containers, options)
+	}
 	w := options.Stdout
 	var (
 		wide bool
@@ -163,7 +198,11 @@ func formatAndPrintContainerInfo(containers []container.ListItem, options Format
 	case "", "table":
 		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
 		if !options.Quiet {
-			printHeader := "CONTAINER ID\tIMAGE\tCOMMAND\tCREATED\tSTATUS\tPORTS\tNAMES"
+			printHeader := "CONTAINER ID\tIMAGE\t"
+			if options.Digests {
+				printHeader += "DIGEST\t"
+			}
+			printHeader += "COMMAND\tCREATED\tSTATUS\tPORTS\tNAMES"
 			if options.Size {
 				printHeader += "\tSIZE"
 			}
@@ -171,10 +210,20 @@ func formatAndPrintContainerInfo(containers []container.ListItem, options Format
 		}
 	case "raw":
 		return errors.New("unsupported format: \"raw\"")
+	case "json", "{{json .}}":
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		return printContainerInfoJSON(w, containers)
 	case "wide":
 		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
 		if !options.Quiet {
-			fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tCOMMAND\tCREATED\tSTATUS\tPORTS\tNAMES\tRUNTIME\tPLATFORM\tSIZE")
+			printHeader := "CONTAINER ID\tIMAGE\t"
+			if options.Digests {
+				printHeader += "DIGEST\t"
+			}
+			printHeader += "COMMAND\tCREATED\tSTATUS\tPORTS\tNAMES\tRUNTIME\tPLATFORM\tSIZE"
+			fmt.Fprintln(w, printHeader)
 			wide = true
 		}
 	default:
@@ -202,16 +251,27 @@ func formatAndPrintContainerInfo(containers []container.ListItem, options Format
 				return err
 			}
 		} else {
-			format := "%s\t%s\t%s\t%s\t%s\t%s\t%s"
+			format := "%s\t%s\t"
 			args := []interface{}{
 				c.ID,
 				c.Image,
+			}
+			if options.Digests {
+				digest := c.Digest
+				if digest == "" {
+					digest = "<none>"
+				}
+				format += "%s\t"
+				args = append(args, digest)
+			}
+			format += "%s\t%s\t%s\t%s\t%s"
+			args = append(args,
 				c.Command,
 				formatter.TimeSinceInHuman(c.CreatedAt),
 				c.Status,
 				c.Ports,
 				c.Names,
-			}
+			)
 			if wide {
 				format += "\t%s\t%s\t%s\n"
 				args = append(args, c.Runtime, c.Platform, c.Size)
@@ -232,3 +292,92 @@ func formatAndPrintContainerInfo(containers []container.ListItem, options Format
 	}
 	return nil
 }
+
+// printContainerInfoByPod prints the containers grouped by pod (`--pod`).
+// The table formats have a heading for each pod, the other formats just list the containers in the order of the groups.
+func printContainerInfoByPod(containers []container.ListItem, options FormattingAndPrintingOptions) error {
+	groups := container.GroupByPod(containers)
+	options.Pod = false
+	switch options.Format {
+	case "", "table", "wide":
+		if options.Quiet || len(groups) == 0 {
+			break
+		}
+		for i, g := range groups {
+			if i > 0 {
+				fmt.Fprintln(options.Stdout)
+			}
+			heading := "UNGROUPED"
+			if g.Pod != "" {
+				heading = "POD: " + g.Pod
+			}
+			fmt.Fprintln(options.Stdout, heading)
+			if err := formatAndPrintContainerInfo(g.Containers, options); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var sorted []container.ListItem
+	for _, g := range groups {
+		sorted = append(sorted, g.Containers...)
+	}
+	return formatAndPrintContainerInfo(sorted, options)
+}
+
+// containerListItemJSON is printed by `nerdctl ps --format=json`.
+// The keys are compatible with the response of the Docker API `GET /containers/json`.
+type containerListItemJSON struct {
+	ID      string `json:"Id"`
+	Image   string
+	Command string
+	Created int64 // Unix time
+	Status  string
+	Ports   []containerPortJSON
+	Names   []string
+	Labels  map[string]string
+}
+
+// containerPortJSON is an element of "Ports" of containerListItemJSON.
+type containerPortJSON struct {
+	IP          string `json:",omitempty"`
+	PrivatePort uint16
+	PublicPort  uint16 `json:",omitempty"`
+	Type        string
+}
+
+func containerPortsJSON(labelMap map[string]string) []containerPortJSON {
+	ports, err := portutil.ParsePortsLabel(labelMap)
+	if err != nil {
+		log.L.Error(err.Error())
+	}
+	res := make([]containerPortJSON, len(ports))
+	for i, p := range ports {
+		res[i] = containerPortJSON{
+			IP:          p.HostIP,
+			PrivatePort: uint16(p.ContainerPort),
+			PublicPort:  uint16(p.HostPort),
+			Type:        p.Protocol,
+		}
+	}
+	return res
+}
+
+func printContainerInfoJSON(w io.Writer, containers []container.ListItem) error {
+	items := make([]containerListItemJSON, len(containers))
+	for i, c := range containers {
+		items[i] = containerListItemJSON{
+			ID:      c.ID,
+			Image:   c.Image,
+			Command: c.Command,
+			Created: c.CreatedAt.Unix(),
+			Status:  c.Status,
+			Ports:   containerPortsJSON(c.Labels),
+			Names:   []string{c.Names},
+			Labels:  c.Labels,
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(items)
+}
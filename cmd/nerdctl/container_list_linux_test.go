@@ -575,3 +575,20 @@ func TestContainerListWithFilter(t *testing.T) {
 		return nil
 	})
 }
+
+func TestContainerListWithNetworkIDFilter(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	netName := testutil.Identifier(t)
+	containerName := testutil.Identifier(t) + "-c"
+	base.Cmd("network", "create", netName).AssertOK()
+	defer base.Cmd("network", "rm", netName).Run()
+	base.Cmd("run", "-d", "--name", containerName, "--network", netName, testutil.CommonImage, "sleep", "infinity").AssertOK()
+	defer base.Cmd("rm", "-f", containerName).Run()
+
+	netID := strings.TrimSpace(base.Cmd("network", "inspect", "--format", "{{.ID}}", netName).Out())
+	for _, value := range []string{netName, netID, netID[:12]} {
+		base.Cmd("ps", "--filter", "network="+value, "--format", "{{.Names}}").AssertOutExactly(containerName + "\n")
+	}
+	base.Cmd("ps", "--filter", "network="+netName+"-nonexistent", "--format", "{{.Names}}").AssertOutExactly("")
+}
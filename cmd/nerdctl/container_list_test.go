@@ -17,10 +17,14 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"gotest.tools/v3/assert"
 )
 
 // https://github.com/containerd/nerdctl/issues/2598
@@ -41,3 +45,169 @@ func TestContainerListWithFormatLabel(t *testing.T) {
 		"--filter", "label="+labelK,
 		"--format", fmt.Sprintf("{{.Label %q}}", labelK)).AssertOutExactly(labelV + "\n")
 }
+
+func TestContainerListJSON(t *testing.T) {
+	testutil.DockerIncompatible(t) // Docker prints JSON lines
+	t.Parallel()
+	base := testutil.NewBase(t)
+	cID := testutil.Identifier(t)
+
+	// the host port is allocated by nerdctl
+	base.Cmd("run", "-d", "--name", cID, "-p", "80", testutil.CommonImage, "sleep", "infinity").AssertOK()
+	defer base.Cmd("rm", "-f", cID).AssertOK()
+
+	type psJSON struct {
+		ID      string `json:"Id"`
+		Image   string
+		Command string
+		Created int64
+		Status  string
+		Ports   []struct {
+			PrivatePort uint16
+			PublicPort  uint16
+			Type        string
+		}
+		Names []string
+	}
+	ps := func(args ...string) (string, []psJSON) {
+		out := base.Cmd(append([]string{"ps", "-a", "--filter", "name=" + cID}, args...)...).Out()
+		var items []psJSON
+		assert.NilError(t, json.Unmarshal([]byte(out), &items), out)
+		return out, items
+	}
+
+	out, items := ps("--format", "json")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, 12, len(items[0].ID))
+	assert.DeepEqual(t, []string{cID}, items[0].Names)
+	assert.Equal(t, testutil.CommonImage, items[0].Image)
+	assert.Assert(t, items[0].Created > 0)
+	assert.Assert(t, strings.HasPrefix(items[0].Status, "Up"))
+	assert.Equal(t, 1, len(items[0].Ports))
+	assert.Equal(t, uint16(80), items[0].Ports[0].PrivatePort)
+	assert.Assert(t, items[0].Ports[0].PublicPort > 0)
+	assert.Equal(t, "tcp", items[0].Ports[0].Type)
+	assert.Assert(t, strings.Contains(out, "\n    "), "expected the JSON to be pretty-printed")
+
+	templateOut, _ := ps("--format", "{{json .}}")
+	assert.Equal(t, out, templateOut)
+
+	_, items = ps("--format", "json", "--no-trunc")
+	assert.Equal(t, 64, len(items[0].ID))
+}
+
+func TestContainerListDigests(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	cID := testutil.Identifier(t)
+
+	base.Cmd("run", "-d", "--name", cID, testutil.CommonImage, "sleep", "infinity").AssertOK()
+	defer base.Cmd("rm", "-f", cID).AssertOK()
+	// the image may be listed once per platform
+	digest := strings.Split(base.Cmd("images", "--format", "{{.Digest}}", testutil.CommonImage).Out(), "\n")[0]
+	assert.Assert(t, strings.HasPrefix(digest, "sha256:"), digest)
+
+	ps := func(args ...string) []string {
+		out := base.Cmd(append([]string{"ps", "-a", "--filter", "name=" + cID}, args...)...).Out()
+		return strings.Split(strings.TrimSpace(out), "\n")
+	}
+	lines := ps()
+	assert.Assert(t, !strings.Contains(lines[0], "DIGEST"), lines[0])
+
+	for _, flag := range []string{"--digests", "--no-trunc"} {
+		lines = ps(flag)
+		assert.Equal(t, 2, len(lines))
+		assert.DeepEqual(t, []string{"CONTAINER", "ID", "IMAGE", "DIGEST", "COMMAND"}, strings.Fields(lines[0])[:5])
+		assert.Equal(t, digest, strings.Fields(lines[1])[2], lines[1])
+	}
+
+	base.Cmd("ps", "-a", "--filter", "name="+cID, "--format", "{{.Digest}}").AssertOutExactly(digest + "\n")
+}
+
+func TestContainerListBeforeSinceFlags(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	tID := testutil.Identifier(t)
+	names := []string{tID + "-a", tID + "-b", tID + "-c"}
+	for _, name := range names {
+		base.Cmd("create", "--name", name, testutil.CommonImage, "sleep", "infinity").AssertOK()
+		defer base.Cmd("rm", "-f", name).Run()
+	}
+	idA := strings.TrimSpace(base.Cmd("inspect", "--format", "{{.Id}}", names[0]).Out())
+
+	ps := func(args ...string) map[string]bool {
+		out := base.Cmd(append([]string{"ps", "--format", "{{.Names}}"}, args...)...).Out()
+		listed := make(map[string]bool)
+		for _, name := range strings.Fields(out) {
+			listed[name] = true
+		}
+		return listed
+	}
+
+	// created containers are not running, so they are listed only with --all
+	listed := ps("--since", names[0])
+	assert.Assert(t, !listed[names[1]] && !listed[names[2]], listed)
+
+	for _, ref := range []string{names[0], idA[:12]} {
+		listed = ps("-a", "--since", ref)
+		assert.Assert(t, !listed[names[0]] && listed[names[1]] && listed[names[2]], listed)
+	}
+
+	listed = ps("-a", "--before", names[2])
+	assert.Assert(t, listed[names[0]] && listed[names[1]] && !listed[names[2]], listed)
+
+	listed = ps("-a", "--since", names[0], "--before", names[2])
+	assert.Assert(t, !listed[names[0]] && listed[names[1]] && !listed[names[2]], listed)
+}
+
+func TestContainerListNoTrunc(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires sh of a Linux image")
+	}
+	t.Parallel()
+	base := testutil.NewBase(t)
+	cID := testutil.Identifier(t)
+
+	longComment := "# " + strings.Repeat("nerdctl-no-trunc-", 5)
+	base.Cmd("run", "-d", "--name", cID, testutil.CommonImage, "sh", "-c", "sleep infinity "+longComment).AssertOK()
+	defer base.Cmd("rm", "-f", cID).AssertOK()
+
+	base.Cmd("ps", "--no-trunc", "--filter", "name="+cID, "--format", "{{.Command}}").AssertOutContains(longComment)
+	base.Cmd("ps", "--filter", "name="+cID, "--format", "{{.Command}}").AssertOutWithFunc(func(out string) error {
+		if strings.Contains(out, longComment) {
+			return fmt.Errorf("expected COMMAND to be truncated, got %q", out)
+		}
+		return nil
+	})
+}
+
+func TestContainerListPod(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	tID := testutil.Identifier(t)
+	pod := "pod-" + tID
+	first, second, ungrouped := tID+"-first", tID+"-second", tID+"-ungrouped"
+
+	for _, name := range []string{first, second} {
+		base.Cmd("run", "-d", "--name", name, "--label", "nerdctl/pod="+pod, "--label", "test="+tID,
+			testutil.CommonImage, "sleep", "infinity").AssertOK()
+		defer base.Cmd("rm", "-f", name).Run()
+	}
+	base.Cmd("run", "-d", "--name", ungrouped, "--label", "test="+tID, testutil.CommonImage, "sleep", "infinity").AssertOK()
+	defer base.Cmd("rm", "-f", ungrouped).Run()
+
+	base.Cmd("ps", "--pod", "--filter", "label=test="+tID, "--format", "{{.Names}}").
+		AssertOutExactly(first + "\n" + second + "\n" + ungrouped + "\n")
+	base.Cmd("ps", "--pod", "--filter", "label=test="+tID).AssertOutWithFunc(func(out string) error {
+		podIdx := strings.Index(out, "POD: "+pod)
+		ungroupedIdx := strings.Index(out, "UNGROUPED")
+		if podIdx < 0 || ungroupedIdx < podIdx {
+			return fmt.Errorf("expected the heading of the pod before UNGROUPED, got %q", out)
+		}
+		if !strings.Contains(out[podIdx:ungroupedIdx], second) || !strings.Contains(out[ungroupedIdx:], ungrouped) {
+			return fmt.Errorf("unexpected groups: %q", out)
+		}
+		return nil
+	})
+}
@@ -37,6 +37,26 @@ func TestRename(t *testing.T) {
 	base.Cmd("rename", testContainerName+"_new", testContainerName+"_new").AssertFail()
 }
 
+func TestRenameUpdateAnnotation(t *testing.T) {
+	t.Parallel()
+	testutil.DockerIncompatible(t)
+	testContainerName := testutil.Identifier(t)
+	base := testutil.NewBase(t)
+
+	defer base.Cmd("rm", "-f", testContainerName).Run()
+	base.Cmd("run", "-d", "--name", testContainerName, testutil.CommonImage, "sleep", "infinity").AssertOK()
+	base.EnsureContainerStarted(testContainerName)
+
+	defer base.Cmd("rm", "-f", testContainerName+"_new").Run()
+	base.Cmd("rename", testContainerName, testContainerName+"_new").AssertOK()
+	base.Cmd("container", "inspect", "--mode=native", "--format={{index .Labels \"nerdctl/name\"}}", testContainerName+"_new").
+		AssertOutExactly(testContainerName + "_new\n")
+	base.Cmd("container", "inspect", "--mode=native", "--format={{index .Spec.Annotations \"nerdctl/name\"}}", testContainerName+"_new").
+		AssertOutExactly(testContainerName + "_new\n")
+	base.Cmd("exec", testContainerName+"_new", "true").AssertOK()
+	base.Cmd("exec", testContainerName, "true").AssertFail()
+}
+
 func TestRenameUpdateHosts(t *testing.T) {
 	t.Parallel()
 	testutil.DockerIncompatible(t)
@@ -19,6 +19,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"os"
 	"runtime"
 
 	"github.com/containerd/console"
@@ -69,6 +70,7 @@ func newRunCommand() *cobra.Command {
 	setCreateFlags(runCommand)
 
 	runCommand.Flags().BoolP("detach", "d", false, "Run container in background and print container ID")
+	runCommand.Flags().String("stdin-file", "", "Read the STDIN of the container from the file (implies -i)")
 
 	return runCommand
 }
@@ -113,6 +115,10 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc("net", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return shellCompleteNetworkNames(cmd, []string{})
 	})
+	cmd.Flags().String("net-driver", "", `Shorthand for the network of the driver ("bridge"|"host"|"none"), cannot be specified with --network`)
+	cmd.RegisterFlagCompletionFunc("net-driver", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"bridge", "host", "none"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	// dns is defined as StringSlice, not StringArray, to allow specifying "--dns=1.1.1.1,8.8.8.8" (compatible with Podman)
 	cmd.Flags().StringSlice("dns", nil, "Set custom DNS servers")
 	cmd.Flags().StringSlice("dns-search", nil, "Set custom DNS search domains")
@@ -142,6 +148,10 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().Int("oom-score-adj", 0, "Tune container’s OOM preferences (-1000 to 1000, rootless: 100 to 1000)")
 	cmd.Flags().String("pid", "", "PID namespace to use")
 	cmd.Flags().String("uts", "", "UTS namespace to use")
+	cmd.Flags().String("userns", "", `User namespace to use ("host"|"auto[:size=N]"|"keep-id")`)
+	cmd.RegisterFlagCompletionFunc("userns", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"host", "auto", "keep-id"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.RegisterFlagCompletionFunc("pid", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"host"}, cobra.ShellCompDirectiveNoFileComp
 	})
@@ -158,10 +168,14 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().Uint64("cpu-shares", 0, "CPU shares (relative weight)")
 	cmd.Flags().Int64("cpu-quota", -1, "Limit CPU CFS (Completely Fair Scheduler) quota")
 	cmd.Flags().Uint64("cpu-period", 0, "Limit CPU CFS (Completely Fair Scheduler) period")
+	cmd.Flags().Int64("cpu-rt-runtime", 0, "Limit CPU real-time runtime in microseconds")
+	cmd.Flags().Uint64("cpu-rt-period", 0, "Limit CPU real-time period in microseconds")
 	// device is defined as StringSlice, not StringArray, to allow specifying "--device=DEV1,DEV2" (compatible with Podman)
 	cmd.Flags().StringSlice("device", nil, "Add a host device to the container")
+	cmd.Flags().StringSlice("hugetlb-limit", nil, "Huge page limit, formatted as PAGESIZE:LIMIT (e.g., 2MB:256MB)")
 	// ulimit is defined as StringSlice, not StringArray, to allow specifying "--ulimit=ULIMIT1,ULIMIT2" (compatible with Podman)
 	cmd.Flags().StringSlice("ulimit", nil, "Ulimit options")
+	cmd.Flags().StringSlice("add-ulimit", nil, "Alias of --ulimit")
 	cmd.Flags().String("rdt-class", "", "Name of the RDT class (or CLOS) to associate the container with")
 	// #endregion
 
@@ -169,6 +183,7 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("user", "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
 	cmd.Flags().String("umask", "", "Set the umask inside the container. Defaults to 0022")
 	cmd.Flags().StringSlice("group-add", []string{}, "Add additional groups to join")
+	cmd.Flags().String("group-file", "", "Merge the group entries of a file into the container's /etc/group (entries of the image win on duplicate GIDs)")
 
 	// #region security flags
 	cmd.Flags().StringArray("security-opt", []string{}, "Security options")
@@ -179,12 +194,17 @@ func setCreateFlags(cmd *cobra.Command) {
 			"no-new-privileges",
 			"privileged-without-host-devices"}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmd.Flags().Bool("no-new-privileges", false, "Disallow privilege escalation, e.g., setuid and file capabilities (same as --security-opt no-new-privileges)")
 	// cap-add and cap-drop are defined as StringSlice, not StringArray, to allow specifying "--cap-add=CAP_SYS_ADMIN,CAP_NET_ADMIN" (compatible with Podman)
 	cmd.Flags().StringSlice("cap-add", []string{}, "Add Linux capabilities")
 	cmd.RegisterFlagCompletionFunc("cap-add", capShellComplete)
 	cmd.Flags().StringSlice("cap-drop", []string{}, "Drop Linux capabilities")
 	cmd.RegisterFlagCompletionFunc("cap-drop", capShellComplete)
 	cmd.Flags().Bool("privileged", false, "Give extended privileges to this container")
+	cmd.Flags().StringArray("mask", nil, "Mask a path in the container, e.g., /proc/kcore, so that it appears empty")
+	cmd.Flags().StringArray("unmask", nil, "Unmask a path masked by default, e.g., /proc/acpi (\"ALL\" to unmask all the default masked paths)")
+	cmd.Flags().StringArray("readonly-paths", nil, "Make a path in the container read-only, e.g., /proc/sys")
+	cmd.Flags().StringArray("no-readonly-paths", nil, "Clear the default readonly paths (\"ALL\")")
 	cmd.Flags().String("systemd", "false", "Allow running systemd in this container (default: false)")
 	// #endregion
 
@@ -192,6 +212,7 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().String("runtime", defaults.Runtime, "Runtime to use for this container, e.g. \"crun\", or \"io.containerd.runsc.v1\"")
 	// sysctl needs to be StringArray, not StringSlice, to prevent "foo=foo1,foo2" from being split to {"foo=foo1", "foo2"}
 	cmd.Flags().StringArray("sysctl", nil, "Sysctl options")
+	cmd.Flags().String("oci-spec-file", "", "Path of an OCI runtime spec JSON to use as the spec of the container. Only the env, volume, and network flags are applied on top of it")
 	// gpus needs to be StringArray, not StringSlice, to prevent "capabilities=utility,device=DEV" from being split to {"capabilities=utility", "device=DEV"}
 	cmd.Flags().StringArray("gpus", nil, "GPU devices to add to the container ('all' to pass all GPUs)")
 	cmd.RegisterFlagCompletionFunc("gpus", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -226,6 +247,8 @@ func setCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSlice("add-host", nil, "Add a custom host-to-IP mapping (host:ip)")
 	// env-file is defined as StringSlice, not StringArray, to allow specifying "--env-file=FILE1,FILE2" (compatible with Podman)
 	cmd.Flags().StringSlice("env-file", nil, "Set environment variables from file")
+	cmd.Flags().Bool("env-replace-all", false, "Clear the environment variables of the image, and use only --env and --env-file")
+	cmd.Flags().Bool("env-append", false, "Add --env and --env-file to the environment variables of the image, without replacing the ones defined by the image")
 
 	// #region metadata flags
 	cmd.Flags().String("name", "", "Assign a name to the container")
@@ -253,6 +276,7 @@ func setCreateFlags(cmd *cobra.Command) {
 
 	// shared memory flags
 	cmd.Flags().String("shm-size", "", "Size of /dev/shm")
+	cmd.Flags().Bool("no-shm", false, "Do not mount /dev/shm")
 	cmd.Flags().String("pidfile", "", "file path to write the task's pid")
 
 	// #region verify flags
@@ -299,6 +323,16 @@ func processCreateCommandFlagsInRun(cmd *cobra.Command) (opt types.ContainerCrea
 	if err != nil {
 		return
 	}
+	opt.StdinFile, err = cmd.Flags().GetString("stdin-file")
+	if err != nil {
+		return
+	}
+	if opt.StdinFile != "" {
+		if opt.TTY {
+			return opt, errors.New("flags -t and --stdin-file cannot be specified together")
+		}
+		opt.Interactive = true
+	}
 	return opt, nil
 }
 
@@ -320,6 +354,15 @@ func runAction(cmd *cobra.Command, args []string) error {
 		return errors.New("flags -d and --rm cannot be specified together")
 	}
 
+	var stdin *os.File
+	if createOpt.StdinFile != "" {
+		stdin, err = os.Open(createOpt.StdinFile)
+		if err != nil {
+			return fmt.Errorf("failed to open the stdin file: %w", err)
+		}
+		defer stdin.Close()
+	}
+
 	netFlags, err := loadNetworkFlags(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to load networking flags: %s", err)
@@ -377,7 +420,7 @@ func runAction(cmd *cobra.Command, args []string) error {
 	logURI := lab[labels.LogURI]
 	detachC := make(chan struct{})
 	task, err := taskutil.NewTask(ctx, client, c, false, createOpt.Interactive, createOpt.TTY, createOpt.Detach,
-		con, logURI, createOpt.DetachKeys, createOpt.GOptions.Namespace, detachC)
+		con, stdin, logURI, createOpt.DetachKeys, createOpt.GOptions.Namespace, detachC)
 	if err != nil {
 		return err
 	}
@@ -26,6 +26,7 @@ import (
 	"github.com/containerd/cgroups/v3"
 	"github.com/containerd/containerd/pkg/userns"
 	"github.com/containerd/continuity/testutil/loopback"
+	"github.com/containerd/nerdctl/v2/pkg/cgrouputil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"gotest.tools/v3/assert"
@@ -92,6 +93,11 @@ func TestRunCgroupV2(t *testing.T) {
 		"-w", "/sys/fs/cgroup", testutil.AlpineImage,
 		"cat", "cpu.max", "memory.max", "memory.swap.max", "memory.low", "pids.max",
 		"cpu.weight", "cpuset.cpus", "cpuset.mems").AssertOutExactly(expected2)
+	// cpu.weight translated from --cpu-shares can be overridden with --cgroup-conf
+	base.Cmd("run", "--rm", "--cpu-shares", "2000", "--cgroup-conf", "cpu.weight=42",
+		"-w", "/sys/fs/cgroup", testutil.AlpineImage, "cat", "cpu.weight").AssertOutExactly("42\n")
+	// memory swappiness is not supported on cgroup v2, so it is discarded with a warning
+	base.Cmd("run", "--rm", "--memory-swappiness", "0", testutil.AlpineImage, "true").AssertOK()
 
 	base.Cmd("run", "--name", testutil.Identifier(t)+"-testUpdate1", "-w", "/sys/fs/cgroup", "-d",
 		testutil.AlpineImage, "sleep", "infinity").AssertOK()
@@ -124,6 +130,24 @@ func TestRunCgroupV2(t *testing.T) {
 		"cat", "cpu.max", "memory.max", "memory.swap.max", "memory.low",
 		"pids.max", "cpu.weight", "cpuset.cpus", "cpuset.mems").AssertOutExactly(expected2)
 
+	// --cpus is translated into cpu.max, and the limits of a paused container are updated live too
+	defer base.Cmd("rm", "-f", testutil.Identifier(t)+"-testUpdate3").Run()
+	base.Cmd("run", "--name", testutil.Identifier(t)+"-testUpdate3", "-w", "/sys/fs/cgroup", "-d",
+		testutil.AlpineImage, "sleep", "infinity").AssertOK()
+	base.EnsureContainerStarted(testutil.Identifier(t) + "-testUpdate3")
+	base.Cmd("pause", testutil.Identifier(t)+"-testUpdate3").AssertOK()
+	base.Cmd("update", "--cpus", "0.42", "--memory", "42m", testutil.Identifier(t)+"-testUpdate3").AssertOK()
+	base.Cmd("unpause", testutil.Identifier(t)+"-testUpdate3").AssertOK()
+	base.Cmd("exec", testutil.Identifier(t)+"-testUpdate3", "cat", "cpu.max", "memory.max").
+		AssertOutExactly("42000 100000\n44040192\n")
+
+	// the spec of a created container is updated, and applied on start
+	defer base.Cmd("rm", "-f", testutil.Identifier(t)+"-testUpdate4").Run()
+	base.Cmd("create", "--name", testutil.Identifier(t)+"-testUpdate4", "-w", "/sys/fs/cgroup",
+		testutil.AlpineImage, "sleep", "infinity").AssertOK()
+	base.Cmd("update", "--cpus", "0.42", testutil.Identifier(t)+"-testUpdate4").AssertOK()
+	base.Cmd("start", testutil.Identifier(t)+"-testUpdate4").AssertOK()
+	base.Cmd("exec", testutil.Identifier(t)+"-testUpdate4", "cat", "cpu.max").AssertOutExactly("42000 100000\n")
 }
 
 func TestRunCgroupV1(t *testing.T) {
@@ -167,6 +191,49 @@ func TestRunCgroupV1(t *testing.T) {
 	base.Cmd("run", "--rm", "--cpu-quota", "42000", "--cpu-period", "100000", "--cpuset-mems", "0", "--memory", "42m", "--memory-reservation", "6m", "--memory-swap", "100m", "--memory-swappiness", "0", "--pids-limit", "42", "--cpu-shares", "2000", "--cpuset-cpus", "0-1", testutil.AlpineImage, "cat", quota, period, cpusetMems, memoryLimit, memoryReservation, memorySwap, memorySwappiness, pidsLimit, cpuShare, cpusetCpus).AssertOutExactly(expected)
 }
 
+func TestRunMemoryReservationTooSmall(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--memory-reservation", "1m", testutil.AlpineImage, "true").AssertFail()
+}
+
+func TestRunCPURealtime(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--cpu-rt-runtime", "2000", "--cpu-rt-period", "1000", testutil.AlpineImage, "true").AssertFail()
+	if !cgrouputil.RealtimeSupported() {
+		t.Skip("test requires the real-time group scheduling of the kernel")
+	}
+	const (
+		rtRuntime = "/sys/fs/cgroup/cpu/cpu.rt_runtime_us"
+		rtPeriod  = "/sys/fs/cgroup/cpu/cpu.rt_period_us"
+	)
+	base.Cmd("run", "--rm", "--cpu-rt-runtime", "950", "--cpu-rt-period", "1000000", testutil.AlpineImage, "cat", rtRuntime, rtPeriod).AssertOutExactly("950\n1000000\n")
+}
+
+func TestRunKernelMemoryCgroupV1(t *testing.T) {
+	t.Parallel()
+	switch cgroups.Mode() {
+	case cgroups.Legacy, cgroups.Hybrid:
+	default:
+		t.Skip("test requires cgroup v1")
+	}
+	base := testutil.NewBase(t)
+	info := base.Info()
+	switch info.CgroupDriver {
+	case "none", "":
+		t.Skip("test requires cgroup driver")
+	}
+	if !info.MemoryLimit {
+		t.Skip("test requires MemoryLimit")
+	}
+	kernelMemoryLimit := "/sys/fs/cgroup/memory/memory.kmem.limit_in_bytes"
+	if _, err := os.Stat(kernelMemoryLimit); err != nil {
+		t.Skipf("test requires %s", kernelMemoryLimit)
+	}
+	base.Cmd("run", "--rm", "--kernel-memory", "50m", testutil.AlpineImage, "cat", kernelMemoryLimit).AssertOutExactly("52428800\n")
+}
+
 func TestRunDevice(t *testing.T) {
 	if os.Geteuid() != 0 || userns.RunningInUserNS() {
 		t.Skip("test requires the root in the initial user namespace")
@@ -265,6 +332,77 @@ func TestParseDevice(t *testing.T) {
 	}
 }
 
+func TestParseHugetlbLimit(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		s                string
+		expectedPageSize string
+		expectedLimit    uint64
+		err              string
+	}
+	testCases := []testCase{
+		{
+			s:                "2MB:256MB",
+			expectedPageSize: "2MB",
+			expectedLimit:    256 * 1024 * 1024,
+		},
+		{
+			s:                "2048k:1g",
+			expectedPageSize: "2MB",
+			expectedLimit:    1024 * 1024 * 1024,
+		},
+		{
+			s:                "1GB:2GB",
+			expectedPageSize: "1GB",
+			expectedLimit:    2 * 1024 * 1024 * 1024,
+		},
+		{
+			s:   "2MB",
+			err: "must be formatted PAGESIZE:LIMIT",
+		},
+		{
+			s:   "3MB:256MB",
+			err: "must be a power of 2",
+		},
+		{
+			s:   "2MB:foo",
+			err: "failed to parse the limit",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Log(tc.s)
+		pageSize, limit, err := container.ParseHugetlbLimit(tc.s)
+		if tc.err == "" {
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedPageSize, pageSize)
+			assert.Equal(t, tc.expectedLimit, limit)
+		} else {
+			assert.ErrorContains(t, err, tc.err)
+		}
+	}
+}
+
+func TestRunHugetlbLimit(t *testing.T) {
+	t.Parallel()
+	if cgroups.Mode() != cgroups.Unified {
+		t.Skip("test requires cgroup v2")
+	}
+	if _, err := os.Stat("/sys/kernel/mm/hugepages/hugepages-2048kB"); err != nil {
+		t.Skip("test requires 2MB huge pages")
+	}
+	testutil.DockerIncompatible(t) // Docker lacks --hugetlb-limit
+	base := testutil.NewBase(t)
+	info := base.Info()
+	switch info.CgroupDriver {
+	case "none", "":
+		t.Skip("test requires cgroup driver")
+	}
+	base.Cmd("run", "--rm", "--hugetlb-limit", "2MB:4MB", testutil.AlpineImage, "cat", "/sys/fs/cgroup/hugetlb.2MB.max").AssertOutExactly("4194304\n")
+	base.Cmd("run", "--rm", "--hugetlb-limit", "2MB:4MB", "--hugetlb-limit", "2048KB:8MB", testutil.AlpineImage, "true").AssertFail()
+	base.Cmd("run", "--rm", "--hugetlb-limit", "4MB:4MB", testutil.AlpineImage, "true").AssertFail()
+}
+
 func TestRunCgroupConf(t *testing.T) {
 	t.Parallel()
 	if cgroups.Mode() != cgroups.Unified {
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +33,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/containerd/oci"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
@@ -70,6 +74,19 @@ func TestRunShmSize(t *testing.T) {
 	base.Cmd("run", "--rm", "--shm-size", shmSize, testutil.AlpineImage, "/bin/grep", "shm", "/proc/self/mounts").AssertOutContains("size=32768k")
 }
 
+func TestRunNoShm(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--no-shm", testutil.AlpineImage, "/bin/grep", "/dev/shm", "/proc/self/mounts").AssertFail()
+	base.Cmd("run", "--rm", testutil.AlpineImage, "/bin/grep", "/dev/shm", "/proc/self/mounts").AssertOK()
+
+	cmd := base.Cmd("run", "--rm", "--no-shm", "--shm-size", "32m", testutil.AlpineImage, "true")
+	cmd.AssertFail()
+	cmd.AssertErrContains("cannot be specified together")
+	base.Cmd("run", "--rm", "--no-shm", "--ipc", "host", testutil.AlpineImage, "true").AssertFail()
+}
+
 func TestRunShmSizeIPCShareable(t *testing.T) {
 	t.Parallel()
 	base := testutil.NewBase(t)
@@ -256,6 +273,14 @@ func TestRunUlimit(t *testing.T) {
 
 	base.Cmd("run", "--rm", "--ulimit", ulimit2, testutil.AlpineImage, "sh", "-c", "ulimit -Sn").AssertOutExactly("622\n")
 	base.Cmd("run", "--rm", "--ulimit", ulimit2, testutil.AlpineImage, "sh", "-c", "ulimit -Hn").AssertOutExactly("722\n")
+
+	base.Cmd("run", "--rm", "--add-ulimit", "nofile=1024", testutil.AlpineImage, "sh", "-c", "ulimit -Sn; ulimit -Hn").AssertOutExactly("1024\n1024\n")
+	base.Cmd("run", "--rm", "--ulimit", "core=0:unlimited", testutil.AlpineImage, "sh", "-c", "ulimit -Sc; ulimit -Hc").AssertOutExactly("0\nunlimited\n")
+	base.Cmd("run", "--rm", "--ulimit", "nofile=1024", "--add-ulimit", "core=unlimited", testutil.AlpineImage, "sh", "-c", "ulimit -Sn; ulimit -Hc").AssertOutExactly("1024\nunlimited\n")
+	// the last one wins for the same type
+	base.Cmd("run", "--rm", "--ulimit", "nofile=1024", "--ulimit", "nofile=2048", testutil.AlpineImage, "sh", "-c", "ulimit -Sn").AssertOutExactly("2048\n")
+	base.Cmd("run", "--rm", "--ulimit", "nofile=1024", "--add-ulimit", "nofile=2048", testutil.AlpineImage, "sh", "-c", "ulimit -Sn").AssertOutExactly("2048\n")
+	base.Cmd("run", "--rm", "--ulimit", "nofiles=1024", testutil.AlpineImage, "true").AssertFail()
 }
 
 func TestRunWithInit(t *testing.T) {
@@ -416,3 +441,31 @@ func TestRunWithDetachKeys(t *testing.T) {
 	container := base.InspectContainer(containerName)
 	assert.Equal(base.T, container.State.Running, true)
 }
+
+func TestRunOCISpecFile(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	id := testutil.Identifier(t)
+
+	var s oci.Spec
+	ctx := namespaces.WithNamespace(context.Background(), testutil.Namespace)
+	assert.NilError(t, oci.WithDefaultSpec()(ctx, nil, &containers.Container{ID: id}, &s))
+	s.Process.Args = []string{"sh", "-c", "echo $FROM_FILE $FROM_CLI; cat /mnt/foo; ls /sys/class/net"}
+	s.Process.Env = append(s.Process.Env, "FROM_FILE=file")
+	b, err := json.Marshal(s)
+	assert.NilError(t, err)
+	tmpDir := t.TempDir()
+	specPath := filepath.Join(tmpDir, "config.json")
+	assert.NilError(t, os.WriteFile(specPath, b, 0644))
+	volDir := filepath.Join(tmpDir, "vol")
+	assert.NilError(t, os.Mkdir(volDir, 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(volDir, "foo"), []byte("content\n"), 0644))
+
+	// the command after the image is ignored
+	base.Cmd("run", "--rm", "--oci-spec-file", specPath,
+		"--env", "FROM_CLI=cli", "--volume", volDir+":/mnt", "--network", "none",
+		testutil.CommonImage, "echo", "ignored").AssertOutExactly("file cli\ncontent\nlo\n")
+
+	base.Cmd("run", "--rm", "--oci-spec-file", filepath.Join(tmpDir, "non-existent.json"), testutil.CommonImage).AssertFail()
+}
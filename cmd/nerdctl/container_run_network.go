@@ -17,10 +17,13 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"net"
 
 	gocni "github.com/containerd/go-cni"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/portutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 	"github.com/spf13/cobra"
@@ -49,6 +52,26 @@ func loadNetworkFlags(cmd *cobra.Command) (types.NetworkOptions, error) {
 		networkSet = true
 	}
 
+	// --net-driver=(bridge|host|none) is translated to the corresponding --network
+	netDriver, err := cmd.Flags().GetString("net-driver")
+	if err != nil {
+		return netOpts, err
+	}
+	if netDriver != "" {
+		if networkSet {
+			return netOpts, errors.New("--net-driver and --network cannot be specified together")
+		}
+		switch netDriver {
+		case "bridge":
+			netSlice = append(netSlice, netutil.DefaultNetworkName)
+		case "host", "none":
+			netSlice = append(netSlice, netDriver)
+		default:
+			return netOpts, fmt.Errorf("invalid --net-driver %q (supported values: \"bridge\", \"host\", \"none\")", netDriver)
+		}
+		networkSet = true
+	}
+
 	if !networkSet {
 		network, err := cmd.Flags().GetStringSlice("network")
 		if err != nil {
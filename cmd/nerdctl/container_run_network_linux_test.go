@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"regexp"
 	"runtime"
 	"strings"
@@ -595,3 +596,23 @@ func TestRunContainerWithStaticIP6(t *testing.T) {
 		})
 	}
 }
+
+func TestRunNetDriver(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+
+	base.Cmd("run", "--rm", "--net-driver=none", testutil.CommonImage, "ip", "-o", "link", "show").AssertOutWithFunc(func(stdout string) error {
+		if strings.Contains(stdout, "eth0") {
+			return fmt.Errorf("expected no eth0 with --net-driver=none, got %q", stdout)
+		}
+		return nil
+	})
+	base.Cmd("run", "--rm", "--net-driver=bridge", testutil.CommonImage, "ip", "-o", "link", "show", "eth0").AssertOK()
+	if !rootlessutil.IsRootless() {
+		hostname, err := os.Hostname()
+		assert.NilError(t, err)
+		base.Cmd("run", "--rm", "--net-driver=host", testutil.CommonImage, "hostname").AssertOutExactly(hostname + "\n")
+	}
+	base.Cmd("run", "--rm", "--net-driver=host", "--network=none", testutil.CommonImage, "true").AssertFail()
+	base.Cmd("run", "--rm", "--net-driver=macvlan", testutil.CommonImage, "true").AssertFail()
+}
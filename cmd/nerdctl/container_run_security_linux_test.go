@@ -17,18 +17,22 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/containerd/nerdctl/v2/pkg/apparmorutil"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
 )
 
 func getCapEff(base *testutil.Base, args ...string) uint64 {
@@ -117,6 +121,32 @@ func TestRunCap(t *testing.T) {
 	}
 }
 
+func TestRunCapUnknown(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	testutil.DockerIncompatible(t)
+	base.Cmd("run", "--rm", "--cap-add=CAP_NONEXISTENT", testutil.AlpineImage, "true").Assert(icmd.Expected{
+		ExitCode: 1,
+		Err:      `unknown capability "CAP_NONEXISTENT"`,
+	})
+}
+
+func TestRunNoNewPrivileges(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	testutil.DockerIncompatible(t)
+	noNewPrivs := func(args ...string) string {
+		args = append(append([]string{"run", "--rm"}, args...), testutil.AlpineImage, "grep", "-w", "^NoNewPrivs:", "/proc/self/status")
+		return strings.Join(strings.Fields(base.Cmd(args...).Out()), " ")
+	}
+	assert.Equal(t, "NoNewPrivs: 0", noNewPrivs())
+	assert.Equal(t, "NoNewPrivs: 1", noNewPrivs("--no-new-privileges"))
+	assert.Equal(t, "NoNewPrivs: 1", noNewPrivs("--security-opt", "no-new-privileges"))
+	assert.Equal(t, "NoNewPrivs: 1", noNewPrivs("--no-new-privileges", "--security-opt", "no-new-privileges"))
+	assert.Equal(t, "NoNewPrivs: 1", noNewPrivs("--no-new-privileges", "--cap-drop", "ALL"))
+	assert.Equal(t, uint64(0), getCapEff(base, "--no-new-privileges", "--cap-drop", "ALL"))
+}
+
 func TestRunSecurityOptSeccomp(t *testing.T) {
 	t.Parallel()
 	base := testutil.NewBase(t)
@@ -186,6 +216,17 @@ func TestRunApparmor(t *testing.T) {
 	base.Cmd("run", "--rm", "--privileged", testutil.AlpineImage, "cat", attrCurrentPath).AssertOutExactly("unconfined\n")
 }
 
+func TestRunApparmorProfileNotLoaded(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	if !apparmorutil.CanApplyExistingProfile() {
+		t.Skip("test requires AppArmor")
+	}
+	res := base.Cmd("run", "--rm", "--security-opt", "apparmor="+testutil.Identifier(t), testutil.AlpineImage, "true").Run()
+	assert.Assert(t, res.ExitCode != 0, res.Combined())
+	assert.Assert(t, strings.Contains(res.Stderr(), "is not loaded on the host"), res.Combined())
+}
+
 // TestRunSeccompCapSysPtrace tests https://github.com/containerd/nerdctl/issues/976
 func TestRunSeccompCapSysPtrace(t *testing.T) {
 	base := testutil.NewBase(t)
@@ -229,3 +270,79 @@ func TestRunPrivileged(t *testing.T) {
 	// something like `ls: /dev/dummy-zero: No such file or directory`
 	assert.Check(t, strings.Contains(res.Combined(), "No such file or directory"))
 }
+
+func TestRunMaskUnmask(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	// /proc/kcore is masked by default, /proc/cpuinfo is not
+	base.Cmd("run", "--rm", "--mask", "/proc/cpuinfo", testutil.AlpineImage, "sh", "-c", "wc -c < /proc/cpuinfo").AssertOutExactly("0\n")
+
+	inspectMasked := func(args ...string) []string {
+		name := testutil.Identifier(t)
+		base.Cmd(append([]string{"create", "--name", name}, append(args, testutil.AlpineImage, "true")...)...).AssertOK()
+		defer base.Cmd("rm", "-f", name).Run()
+		out := base.Cmd("container", "inspect", "--mode=native", "--format={{json .Spec.linux.maskedPaths}}", name).Out()
+		var masked []string
+		assert.NilError(t, json.Unmarshal([]byte(out), &masked), out)
+		return masked
+	}
+	masked := inspectMasked("--unmask", "/proc/acpi", "--mask", "/proc/foo")
+	assert.Assert(t, !strutil.InStringSlice(masked, "/proc/acpi"), masked)
+	assert.Assert(t, strutil.InStringSlice(masked, "/proc/kcore"), masked)
+	assert.Assert(t, strutil.InStringSlice(masked, "/proc/foo"), masked)
+	assert.DeepEqual(t, inspectMasked("--unmask", "ALL", "--mask", "/proc/foo"), []string{"/proc/foo"})
+
+	base.Cmd("run", "--rm", "--mask", "proc/kcore", testutil.AlpineImage, "true").AssertFail()
+}
+
+func TestRunReadonlyPaths(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--readonly-paths", "/etc", testutil.AlpineImage, "touch", "/etc/foo").AssertFail()
+
+	inspectReadonly := func(args ...string) []string {
+		name := testutil.Identifier(t)
+		base.Cmd(append([]string{"create", "--name", name}, append(args, testutil.AlpineImage, "true")...)...).AssertOK()
+		defer base.Cmd("rm", "-f", name).Run()
+		out := base.Cmd("container", "inspect", "--mode=native", "--format={{json .Spec.linux.readonlyPaths}}", name).Out()
+		var readonly []string
+		assert.NilError(t, json.Unmarshal([]byte(out), &readonly), out)
+		return readonly
+	}
+	readonly := inspectReadonly("--readonly-paths", "/etc", "--readonly-paths", "/opt")
+	assert.Assert(t, strutil.InStringSlice(readonly, "/proc/sys"), readonly)
+	assert.Assert(t, strutil.InStringSlice(readonly, "/etc"), readonly)
+	assert.Assert(t, strutil.InStringSlice(readonly, "/opt"), readonly)
+	assert.DeepEqual(t, inspectReadonly("--no-readonly-paths", "ALL", "--readonly-paths", "/etc"), []string{"/etc"})
+
+	base.Cmd("run", "--rm", "--readonly-paths", "etc", testutil.AlpineImage, "true").AssertFail()
+	base.Cmd("run", "--rm", "--no-readonly-paths", "/proc/sys", testutil.AlpineImage, "true").AssertFail()
+	// conflicts with a read-write mount
+	base.Cmd("run", "--rm", "--readonly-paths", "/mnt", "-v", t.TempDir()+":/mnt", testutil.AlpineImage, "true").AssertFail()
+	base.Cmd("run", "--rm", "--readonly-paths", "/mnt", "-v", t.TempDir()+":/mnt:ro", testutil.AlpineImage, "true").AssertOK()
+	// the default readonly paths are not validated
+	base.Cmd("run", "--rm", "-v", "/proc/sys:/proc/sys", testutil.AlpineImage, "true").AssertOK()
+}
+
+func TestRunCapAddNetRaw(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	// the raw sockets are usable with the default seccomp profile
+	base.Cmd("run", "--rm", "--cap-drop", "ALL", "--cap-add", "net_raw", testutil.AlpineImage, "ping", "-c", "1", "127.0.0.1").AssertOK()
+
+	// a custom profile blocking the raw sockets is not modified, but a warning is printed
+	profile := `{
+	"defaultAction": "SCMP_ACT_ALLOW",
+	"syscalls": [
+		{"names": ["socket"], "action": "SCMP_ACT_ERRNO", "args": [{"index": 0, "value": 17, "op": "SCMP_CMP_EQ"}]}
+	]
+}`
+	profilePath := filepath.Join(t.TempDir(), "profile.json")
+	assert.NilError(t, os.WriteFile(profilePath, []byte(profile), 0644))
+	cmd := base.Cmd("run", "--rm", "--cap-add", "NET_RAW", "--security-opt", "seccomp="+profilePath, testutil.AlpineImage, "true")
+	cmd.AssertOK()
+	cmd.AssertErrContains("blocks socket(2)")
+}
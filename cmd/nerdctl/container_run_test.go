@@ -218,6 +218,34 @@ func TestRunEnv(t *testing.T) {
 	})
 }
 
+func TestRunEnvReplaceAllAndAppend(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	// PATH is defined by the image
+	imagePath := strings.TrimSpace(base.Cmd("run", "--rm", testutil.CommonImage, "sh", "-c", "echo $PATH").Out())
+
+	// the variables of the image are cleared, except the default PATH
+	base.Cmd("run", "--rm", "--env-replace-all", "-e", "FOO=foo", testutil.CommonImage, "env").AssertOutWithFunc(func(stdout string) error {
+		if !strings.Contains(stdout, "\nFOO=foo\n") {
+			return errors.New("got bad FOO")
+		}
+		if !strings.Contains(stdout, "PATH=") {
+			return errors.New("the default PATH is not set")
+		}
+		return nil
+	})
+	base.Cmd("run", "--rm", "--env-replace-all", "-e", "PATH=/bin", testutil.CommonImage, "sh", "-c", "echo $PATH").AssertOutExactly("/bin\n")
+
+	// the variables defined by the image are not replaced
+	base.Cmd("run", "--rm", "--env-append", "-e", "PATH=/foo", "-e", "FOO=foo", testutil.CommonImage, "sh", "-c", "echo $PATH $FOO").
+		AssertOutExactly(imagePath + " foo\n")
+	// by default, the variables defined by the image are replaced
+	base.Cmd("run", "--rm", "-e", "PATH=/bin", testutil.CommonImage, "sh", "-c", "echo $PATH").AssertOutExactly("/bin\n")
+
+	base.Cmd("run", "--rm", "--env-replace-all", "--env-append", testutil.CommonImage, "true").AssertFail()
+}
+
 func TestRunStdin(t *testing.T) {
 	t.Parallel()
 	base := testutil.NewBase(t)
@@ -232,6 +260,22 @@ func TestRunStdin(t *testing.T) {
 	base.Cmd("run", "--rm", "-i", testutil.CommonImage, "cat").CmdOption(opts...).AssertOutExactly(testStr)
 }
 
+func TestRunStdinFile(t *testing.T) {
+	t.Parallel()
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	testutil.RequireDaemonVersion(base, ">= 1.6.0-0")
+
+	const testStr = "test-run-stdin-file"
+	stdinFile := filepath.Join(t.TempDir(), "input.txt")
+	err := os.WriteFile(stdinFile, []byte(testStr), 0644)
+	assert.NilError(t, err)
+	base.Cmd("run", "--rm", "--stdin-file", stdinFile, testutil.CommonImage, "cat").AssertOutExactly(testStr)
+
+	base.Cmd("run", "--rm", "--stdin-file", filepath.Join(t.TempDir(), "nonexistent"), testutil.CommonImage, "cat").AssertFail()
+	base.Cmd("run", "--rm", "-t", "--stdin-file", stdinFile, testutil.CommonImage, "cat").AssertErrContains("cannot be specified together")
+}
+
 func TestRunWithJsonFileLogDriver(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("json-file log driver is not yet implemented on Windows")
@@ -520,3 +564,31 @@ func TestRunRmTime(t *testing.T) {
 		t.Fatalf("expected to have completed in %v, took %v", deadline, took)
 	}
 }
+
+func TestRunPullPolicy(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	// use a dedicated namespace, so that the image is not present at first
+	namespace := testutil.Identifier(t)
+	base := testutil.NewBaseWithNamespace(t, namespace)
+	defer base.Cmd("namespace", "remove", namespace).Run()
+	defer base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+
+	// --pull=never fails for the image not present
+	cmd := base.Cmd("run", "--rm", "--pull=never", testutil.CommonImage, "true")
+	cmd.AssertFail()
+	cmd.AssertErrContains(`the pull mode is "never"`)
+
+	// --pull=missing pulls the image not present, and then uses it without pulling
+	base.Cmd("run", "--rm", "--pull=missing", testutil.CommonImage, "echo", "foo").AssertOutContains("foo")
+	base.Cmd("run", "--rm", "--pull=never", testutil.CommonImage, "echo", "foo").AssertOutExactly("foo\n")
+
+	// --pull=always pulls the image present
+	base.Cmd("run", "--rm", "--pull=always", testutil.CommonImage, "echo", "foo").AssertOutContains("foo")
+
+	// an invalid policy is rejected before creating the container
+	name := testutil.Identifier(t)
+	cmd = base.Cmd("run", "--rm", "--pull=sometimes", "--name", name, testutil.CommonImage, "true")
+	cmd.AssertFail()
+	cmd.AssertErrContains("invalid pull mode")
+	base.Cmd("ps", "-a", "--format", "{{.Names}}").AssertOutNotContains(name)
+}
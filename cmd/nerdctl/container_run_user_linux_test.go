@@ -17,9 +17,18 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	osuser "os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"gotest.tools/v3/assert"
 )
 
 func TestRunUserGID(t *testing.T) {
@@ -181,3 +190,108 @@ func TestRunAddGroup_CVE_2023_25173(t *testing.T) {
 		base.Cmd(cmd...).AssertOutContains(testCase.expected + "\n")
 	}
 }
+
+func TestRunGroupFile(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	groupFile := filepath.Join(t.TempDir(), "group")
+	// "impostor" has the same GID as "daemon" of the image, so it must be dropped
+	err := os.WriteFile(groupFile, []byte("mygroup:x:12345:\nimpostor:x:1:\n"), 0644)
+	assert.NilError(t, err)
+
+	base.Cmd("run", "--rm", "--group-file", groupFile, "--group-add", "mygroup",
+		testutil.BusyboxImage, "sh", "-c", "id -G && cat /etc/group").AssertOutWithFunc(func(stdout string) error {
+		if !strings.Contains(stdout, " 12345\n") {
+			return fmt.Errorf("expected gid 12345 to be added, got %q", stdout)
+		}
+		if !strings.Contains(stdout, "mygroup:x:12345:") {
+			return fmt.Errorf("expected mygroup in /etc/group, got %q", stdout)
+		}
+		if strings.Contains(stdout, "impostor") || !strings.Contains(stdout, "daemon:x:1:") {
+			return fmt.Errorf("expected the image entry to win on a duplicate gid, got %q", stdout)
+		}
+		return nil
+	})
+}
+
+func TestRunGroupAddKeepGroups(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--group-add", "keep-groups", "--group-add", "1234", testutil.BusyboxImage, "id").AssertFail()
+
+	// crun keeps the groups by itself
+	name := testutil.Identifier(t)
+	base.Cmd("create", "--name", name, "--runtime", "crun", "--group-add", "keep-groups", testutil.BusyboxImage, "id").AssertOK()
+	defer base.Cmd("rm", "-f", name).Run()
+	base.Cmd("container", "inspect", "--mode=native", "--format", `{{index .Spec.annotations "run.oci.keep_original_groups"}}`, name).
+		AssertOutExactly("1\n")
+
+	if rootlessutil.IsRootless() {
+		// the supplementary groups of the host are not mapped into the user namespace
+		base.Cmd("run", "--rm", "--group-add", "keep-groups", testutil.BusyboxImage, "id").AssertFail()
+		return
+	}
+
+	gids, err := os.Getgroups()
+	assert.NilError(t, err)
+	base.Cmd("run", "--rm", "--group-add", "keep-groups", testutil.BusyboxImage, "id", "-G").AssertOutWithFunc(func(stdout string) error {
+		got := strings.Fields(stdout)
+		for _, gid := range gids {
+			if !strutil.InStringSlice(got, strconv.Itoa(gid)) {
+				return fmt.Errorf("expected gid %d to be kept, got %q", gid, stdout)
+			}
+		}
+		return nil
+	})
+}
+
+func TestRunUsernsKeepID(t *testing.T) {
+	if !rootlessutil.IsRootless() {
+		t.Skip("--userns=keep-id requires rootless mode")
+	}
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--userns=keep-id", testutil.AlpineImage, "id", "-u").
+		AssertOutExactly(fmt.Sprintf("%d\n", rootlessutil.ParentEUID()))
+	// --user wins over the user of keep-id
+	base.Cmd("run", "--rm", "--userns=keep-id", "--user", "0", testutil.AlpineImage, "id", "-u").AssertOutExactly("0\n")
+	// the applets of busybox are hard links of /bin/busybox, which must be remapped only once
+	base.Cmd("run", "--rm", "--userns=keep-id", testutil.BusyboxImage, "stat", "-c", "%u", "/bin/busybox", "/bin/sh").AssertOutExactly("0\n0\n")
+}
+
+func TestRunUsernsAuto(t *testing.T) {
+	if rootlessutil.IsRootless() {
+		t.Skip("--userns=auto is not supported in rootless mode")
+	}
+	b, err := os.ReadFile("/etc/subuid")
+	if err != nil || !strings.Contains(string(b), "containers:") {
+		cur, _ := osuser.Current()
+		if cur == nil || !strings.Contains(string(b), cur.Username+":") {
+			t.Skip("no /etc/subuid entry for --userns=auto")
+		}
+	}
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--userns=auto:size=65536", testutil.AlpineImage, "sh", "-c", "cat /proc/self/uid_map && stat -c %u /etc/passwd").
+		AssertOutWithFunc(func(stdout string) error {
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			if len(lines) != 2 {
+				return fmt.Errorf("unexpected output %q", stdout)
+			}
+			if fields := strings.Fields(lines[0]); len(fields) != 3 || fields[0] != "0" || fields[1] == "0" || fields[2] != "65536" {
+				return fmt.Errorf("unexpected uid_map %q", lines[0])
+			}
+			// the rootfs must be remapped
+			if lines[1] != "0" {
+				return fmt.Errorf("expected /etc/passwd to be owned by root in the container, got %q", lines[1])
+			}
+			return nil
+		})
+}
+
+func TestRunUsernsInvalid(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("run", "--rm", "--userns=foo", testutil.AlpineImage, "true").AssertFail()
+	base.Cmd("run", "--rm", "--userns=auto:size=0", testutil.AlpineImage, "true").AssertFail()
+}
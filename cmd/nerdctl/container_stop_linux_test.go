@@ -21,6 +21,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
@@ -90,6 +91,74 @@ echo "signal quit"`).AssertOK()
 	base.Cmd("logs", "-f", testContainerName).AssertOutContains("signal quit")
 }
 
+func TestStopWithNumericStopSignal(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	testContainerName := testutil.Identifier(t)
+	defer base.Cmd("rm", "-f", testContainerName).Run()
+
+	// 3 is SIGQUIT
+	base.Cmd("run", "-d", "--stop-signal", "3", "--name", testContainerName, testutil.CommonImage, "sh", "-euxc", `#!/bin/sh
+set -eu
+trap 'quit=1' QUIT
+quit=0
+while [ $quit -ne 1 ]; do
+    printf 'wait quit'
+    sleep 1
+done
+echo "signal quit"`).AssertOK()
+	base.Cmd("stop", testContainerName).AssertOK()
+	base.Cmd("logs", "-f", testContainerName).AssertOutContains("signal quit")
+
+	base.Cmd("run", "--rm", "--stop-signal", "SIGNONEXISTENT", testutil.CommonImage, "true").AssertFail()
+}
+
+func TestStopWithImageStopSignal(t *testing.T) {
+	testutil.RequiresBuild(t)
+	base := testutil.NewBase(t)
+	defer base.Cmd("builder", "prune").AssertOK()
+	imageName := testutil.Identifier(t)
+	defer base.Cmd("rmi", imageName).Run()
+	testContainerName := testutil.Identifier(t)
+	defer base.Cmd("rm", "-f", testContainerName).Run()
+
+	dockerfile := fmt.Sprintf(`FROM %s
+STOPSIGNAL SIGQUIT`, testutil.CommonImage)
+	buildCtx := createBuildContext(t, dockerfile)
+	base.Cmd("build", "-t", imageName, buildCtx).AssertOK()
+
+	base.Cmd("run", "-d", "--name", testContainerName, imageName, "sh", "-euxc", `#!/bin/sh
+set -eu
+trap 'quit=1' QUIT
+quit=0
+while [ $quit -ne 1 ]; do
+    printf 'wait quit'
+    sleep 1
+done
+echo "signal quit"`).AssertOK()
+	base.Cmd("stop", testContainerName).AssertOK()
+	base.Cmd("logs", "-f", testContainerName).AssertOutContains("signal quit")
+}
+
+func TestStopWithStopTimeout(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	testContainerName := testutil.Identifier(t)
+	defer base.Cmd("rm", "-f", testContainerName).Run()
+
+	// PID 1 ignores SIGTERM, so the container is killed after the stop timeout
+	base.Cmd("run", "-d", "--stop-timeout", "1", "--name", testContainerName, testutil.CommonImage, "sleep", "infinity").AssertOK()
+	start := time.Now()
+	base.Cmd("stop", testContainerName).AssertOK()
+	assert.Assert(t, time.Since(start) < 10*time.Second, "the stop timeout of the container must be used instead of the default 10 seconds")
+
+	// --time overrides the stop timeout of the container
+	base.Cmd("start", testContainerName).AssertOK()
+	start = time.Now()
+	base.Cmd("stop", "--time", "3", testContainerName).AssertOK()
+	assert.Assert(t, time.Since(start) >= 3*time.Second, "--time must override the stop timeout of the container")
+}
+
 func TestStopCleanupForwards(t *testing.T) {
 	const (
 		hostPort          = 9999
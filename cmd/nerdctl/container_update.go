@@ -28,6 +28,7 @@ import (
 	"github.com/containerd/containerd/errdefs"
 	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cgrouputil"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	nerdctlContainer "github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
@@ -243,7 +244,7 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 		return err
 	}
 	cStatus := formatter.ContainerStatus(ctx, container)
-	if cStatus == "pausing" {
+	if cStatus == "Pausing" {
 		return fmt.Errorf("container %q is in pausing state", id)
 	}
 	spec, err := container.Spec(ctx)
@@ -274,9 +275,7 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 			spec.Linux.Resources.CPU = &runtimespec.LinuxCPU{}
 		}
 		if cmd.Flags().Changed("cpu-shares") {
-			if spec.Linux.Resources.CPU.Shares != &opts.CPUShares {
-				spec.Linux.Resources.CPU.Shares = &opts.CPUShares
-			}
+			cgrouputil.SetCPUShares(spec.Linux.Resources, opts.CPUShares, cgrouputil.IsUnified())
 		}
 		if cmd.Flags().Changed("cpu-quota") {
 			if spec.Linux.Resources.CPU.Quota != &opts.CPUQuota {
@@ -289,9 +288,9 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 			}
 		}
 		if cmd.Flags().Changed("cpus") {
-			if spec.Linux.Resources.CPU.Cpus != opts.CpusetCpus {
-				spec.Linux.Resources.CPU.Cpus = opts.CpusetCpus
-			}
+			// --cpus is translated into the quota and the period in getUpdateOption
+			spec.Linux.Resources.CPU.Quota = &opts.CPUQuota
+			spec.Linux.Resources.CPU.Period = &opts.CPUPeriod
 		}
 		if cmd.Flags().Changed("cpuset-mems") {
 			if spec.Linux.Resources.CPU.Mems != opts.CpusetMems {
@@ -336,6 +335,7 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 		if err := updateContainerSpec(ctx, container, oldSpec); err != nil {
 			log.G(ctx).WithError(err).Errorf("Failed to update spec %+v for container %q", oldSpec, id)
 		}
+		return err
 	}
 
 	restart, err := cmd.Flags().GetString("restart")
@@ -350,7 +350,8 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 
 	// If container is not running, only update spec is enough, new resource
 	// limit will be applied when container start.
-	if cStatus != "Up" {
+	// The limits of a paused container are updated too, as resuming does not apply the spec.
+	if cStatus != "Up" && cStatus != "Paused" {
 		return nil
 	}
 	task, err := container.Task(ctx, nil)
@@ -361,7 +362,13 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 		}
 		return fmt.Errorf("failed to get task:%w", err)
 	}
-	return task.Update(ctx, containerd.WithResources(spec.Linux.Resources))
+	if err := task.Update(ctx, containerd.WithResources(spec.Linux.Resources)); err != nil {
+		if err := updateContainerSpec(ctx, container, oldSpec); err != nil {
+			log.G(ctx).WithError(err).Errorf("Failed to update spec %+v for container %q", oldSpec, id)
+		}
+		return fmt.Errorf("failed to update the resources of the running container %q: %w", id, err)
+	}
+	return nil
 }
 
 func updateContainerSpec(ctx context.Context, container containerd.Container, spec *runtimespec.Spec) error {
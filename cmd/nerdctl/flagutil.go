@@ -75,6 +75,14 @@ func processRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	logLevel, err := cmd.Flags().GetString("log-level")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	logFormat, err := cmd.Flags().GetString("log-format")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
 	address, err := cmd.Flags().GetString("address")
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
@@ -122,6 +130,8 @@ func processRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	return types.GlobalCommandOptions{
 		Debug:            debug,
 		DebugFull:        debugFull,
+		LogLevel:         logLevel,
+		LogFormat:        logFormat,
 		Address:          address,
 		Namespace:        namespace,
 		Snapshotter:      snapshotter,
@@ -17,10 +17,11 @@
 package main
 
 import (
+	"github.com/containerd/nerdctl/v2/pkg/config"
 	"github.com/spf13/cobra"
 )
 
-func newImageCommand() *cobra.Command {
+func newImageCommand(imagesConfig config.Images) *cobra.Command {
 	cmd := &cobra.Command{
 		Annotations:   map[string]string{Category: Management},
 		Use:           "image",
@@ -32,7 +33,7 @@ func newImageCommand() *cobra.Command {
 	cmd.AddCommand(
 		newBuildCommand(),
 		// commitCommand is in "container", not in "image"
-		imageLsCommand(),
+		imageLsCommand(imagesConfig),
 		newHistoryCommand(),
 		newPullCommand(),
 		newPushCommand(),
@@ -45,12 +46,13 @@ func newImageCommand() *cobra.Command {
 		newImageEncryptCommand(),
 		newImageDecryptCommand(),
 		newImagePruneCommand(),
+		newImageVerifyCommand(),
 	)
 	return cmd
 }
 
-func imageLsCommand() *cobra.Command {
-	x := newImagesCommand()
+func imageLsCommand(imagesConfig config.Images) *cobra.Command {
+	x := newImagesCommand(imagesConfig)
 	x.Use = "ls"
 	x.Aliases = []string{"list"}
 	return x
@@ -18,6 +18,7 @@ package main
 
 import (
 	"compress/gzip"
+	"errors"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
@@ -89,6 +90,7 @@ func newImageConvertCommand() *cobra.Command {
 	// #region generic flags
 	imageConvertCommand.Flags().Bool("uncompress", false, "Convert tar.gz layers to uncompressed tar layers")
 	imageConvertCommand.Flags().Bool("oci", false, "Convert Docker media types to OCI media types")
+	imageConvertCommand.Flags().Int("compression-level", 0, "Compression level for --estargz, --zstd, or --zstdchunked (overrides --<mode>-compression-level)")
 	// #endregion
 
 	// #region platform flags
@@ -219,6 +221,22 @@ func processImageConvertOptions(cmd *cobra.Command) (types.ImageConvertOptions,
 	if err != nil {
 		return types.ImageConvertOptions{}, err
 	}
+	if cmd.Flags().Changed("compression-level") {
+		compressionLevel, err := cmd.Flags().GetInt("compression-level")
+		if err != nil {
+			return types.ImageConvertOptions{}, err
+		}
+		switch {
+		case estargz:
+			estargzCompressionLevel = compressionLevel
+		case zstd:
+			zstdCompressionLevel = compressionLevel
+		case zstdchunked:
+			zstdChunkedCompressionLevel = compressionLevel
+		default:
+			return types.ImageConvertOptions{}, errors.New("option --compression-level requires --estargz, --zstd, or --zstdchunked")
+		}
+	}
 	// #endregion
 
 	// #region platform flags
@@ -17,13 +17,17 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"runtime"
 	"testing"
 
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/testregistry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
 	"gotest.tools/v3/icmd"
 )
 
@@ -75,3 +79,57 @@ func TestImageConvertNydus(t *testing.T) {
 	}
 	nydusifyCmd.AssertOK()
 }
+
+func TestImageConvertSinglePlatform(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	convertedImage := testutil.Identifier(t) + ":arm64"
+	base.Cmd("rmi", convertedImage).Run()
+	base.Cmd("pull", "--platform", "linux/arm64", testutil.AlpineImage).AssertOK()
+	base.Cmd("image", "convert", "--oci", "--platform", "linux/arm64",
+		testutil.AlpineImage, convertedImage).AssertOK()
+	defer base.Cmd("rmi", convertedImage).Run()
+
+	var inspect []native.Image
+	out := base.Cmd("image", "inspect", "--mode=native", convertedImage).Out()
+	assert.NilError(base.T, json.Unmarshal([]byte(out), &inspect))
+	assert.Equal(base.T, 1, len(inspect))
+	assert.Assert(base.T, inspect[0].IndexDesc == nil)
+	assert.Equal(base.T, "arm64", inspect[0].ImageConfig.Architecture)
+
+	base.Cmd("images", "--format", "{{.Platform}}", convertedImage).AssertOutExactly("linux/arm64\n")
+}
+
+func TestImageConvertOCI(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	convertedImage := testutil.Identifier(t) + ":oci"
+	base.Cmd("rmi", convertedImage).Run()
+	platform := "linux/" + runtime.GOARCH
+	base.Cmd("pull", "--platform", platform, testutil.AlpineImage).AssertOK()
+	base.Cmd("image", "convert", "--oci", "--platform", platform,
+		testutil.AlpineImage, convertedImage).AssertOK()
+	defer base.Cmd("rmi", convertedImage).Run()
+
+	inspectNative := func(name string) native.Image {
+		var inspect []native.Image
+		out := base.Cmd("image", "inspect", "--mode=native", "--platform", platform, name).Out()
+		assert.NilError(base.T, json.Unmarshal([]byte(out), &inspect))
+		assert.Equal(base.T, 1, len(inspect))
+		assert.Assert(base.T, inspect[0].Manifest != nil)
+		return inspect[0]
+	}
+	src, converted := inspectNative(testutil.AlpineImage), inspectNative(convertedImage)
+
+	// the manifest, the config, and the layers use the OCI media types
+	assert.Equal(base.T, converted.Image.Target.MediaType, ocispec.MediaTypeImageManifest)
+	assert.Equal(base.T, converted.Manifest.Config.MediaType, ocispec.MediaTypeImageConfig)
+	// the layer blobs are not recompressed, and the diff IDs are preserved
+	assert.Equal(base.T, len(converted.Manifest.Layers), len(src.Manifest.Layers))
+	for i, l := range converted.Manifest.Layers {
+		assert.Equal(base.T, l.MediaType, ocispec.MediaTypeImageLayerGzip)
+		assert.Equal(base.T, l.Digest, src.Manifest.Layers[i].Digest)
+		assert.Equal(base.T, l.Size, src.Manifest.Layers[i].Size)
+	}
+	assert.DeepEqual(base.T, converted.ImageConfig.RootFS.DiffIDs, src.ImageConfig.RootFS.DiffIDs)
+}
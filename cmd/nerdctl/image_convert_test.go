@@ -17,10 +17,13 @@
 package main
 
 import (
+	"encoding/json"
 	"runtime"
 	"testing"
 
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
 )
 
 func TestImageConvertEStargz(t *testing.T) {
@@ -64,3 +67,44 @@ func TestImageConvertZstdChunked(t *testing.T) {
 	base.Cmd("image", "convert", "--zstdchunked", "--oci", "--zstdchunked-compression-level", "3",
 		testutil.CommonImage, convertedImage).AssertOK()
 }
+
+func TestImageConvertCompressionLevel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no windows support yet")
+	}
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	fastImage := testutil.Identifier(t) + ":fast"
+	smallImage := testutil.Identifier(t) + ":small"
+	for _, img := range []string{fastImage, smallImage} {
+		base.Cmd("rmi", img).Run()
+		defer base.Cmd("rmi", img).Run()
+	}
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("image", "convert", "--zstd", "--oci", "--compression-level", "1",
+		testutil.CommonImage, fastImage).AssertOK()
+	base.Cmd("image", "convert", "--zstd", "--oci", "--compression-level", "19",
+		testutil.CommonImage, smallImage).AssertOK()
+
+	layersSize := func(img string) int64 {
+		out := base.Cmd("image", "inspect", "--mode=native", "--format={{json .Manifest.Layers}}", img).Out()
+		var layers []ocispec.Descriptor
+		assert.NilError(t, json.Unmarshal([]byte(out), &layers), out)
+		var size int64
+		for _, l := range layers {
+			size += l.Size
+		}
+		return size
+	}
+	fastSize, smallSize := layersSize(fastImage), layersSize(smallImage)
+	assert.Assert(t, smallSize < fastSize, "expected level 19 (%d bytes) to be smaller than level 1 (%d bytes)", smallSize, fastSize)
+
+	// out of range
+	base.Cmd("image", "convert", "--zstd", "--oci", "--compression-level", "23",
+		testutil.CommonImage, smallImage).AssertFail()
+	base.Cmd("image", "convert", "--estargz", "--oci", "--compression-level", "10",
+		testutil.CommonImage, smallImage).AssertFail()
+	// no compression mode
+	base.Cmd("image", "convert", "--oci", "--compression-level", "1",
+		testutil.CommonImage, smallImage).AssertFail()
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -64,9 +65,23 @@ func addHistoryFlags(cmd *cobra.Command) {
 type historyPrintable struct {
 	Snapshot     string
 	CreatedSince string
+	CreatedAt    string
 	CreatedBy    string
 	Size         string
 	Comment      string
+	EmptyLayer   bool
+}
+
+// historyJSON is an entry of `nerdctl history --format json`.
+// The fields are kept stable for tools consuming the output.
+type historyJSON struct {
+	ID           string
+	CreatedSince string
+	CreatedAt    string
+	CreatedBy    string
+	Size         string
+	Comment      string
+	EmptyLayer   bool
 }
 
 func historyAction(cmd *cobra.Command, args []string) error {
@@ -129,9 +144,11 @@ func historyAction(cmd *cobra.Command, args []string) error {
 				history := historyPrintable{
 					Snapshot:     snapshotName,
 					CreatedSince: formatter.TimeSinceInHuman(*h.Created),
+					CreatedAt:    h.Created.Format(time.RFC3339),
 					CreatedBy:    h.CreatedBy,
 					Size:         size,
 					Comment:      h.Comment,
+					EmptyLayer:   h.EmptyLayer,
 				}
 				historys = append(historys, history)
 			}
@@ -178,6 +195,11 @@ func printHistory(cmd *cobra.Command, historys []historyPrintable) error {
 		}
 	case "raw":
 		return errors.New("unsupported format: \"raw\"")
+	case "json":
+		if quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		return printHistoryJSON(w, historys)
 	default:
 		if quiet {
 			return errors.New("format and quiet must not be specified together")
@@ -208,12 +230,31 @@ func printHistory(cmd *cobra.Command, historys []historyPrintable) error {
 	return nil
 }
 
-func (x *historyPrinter) printHistory(p historyPrintable) error {
-	if !x.noTrunc {
-		if len(p.CreatedBy) > 45 {
-			p.CreatedBy = p.CreatedBy[0:44] + "…"
-		}
+// printHistoryJSON prints the histories as a JSON array, from the newest to the oldest layer.
+func printHistoryJSON(w io.Writer, historys []historyPrintable) error {
+	entries := make([]historyJSON, 0, len(historys))
+	for index := len(historys) - 1; index >= 0; index-- {
+		p := historys[index]
+		entries = append(entries, historyJSON{
+			ID:           p.Snapshot,
+			CreatedSince: p.CreatedSince,
+			CreatedAt:    p.CreatedAt,
+			CreatedBy:    p.CreatedBy,
+			Size:         p.Size,
+			Comment:      p.Comment,
+			EmptyLayer:   p.EmptyLayer,
+		})
 	}
+	b, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func (x *historyPrinter) printHistory(p historyPrintable) error {
+	p.CreatedBy = formatter.TruncateCommand(p.CreatedBy, formatter.CreatedByWidth, x.noTrunc)
 	if x.tmpl != nil {
 		var b bytes.Buffer
 		if err := x.tmpl.Execute(&b, p); err != nil {
@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func TestImageHistoryFormatJSON(t *testing.T) {
+	base := testutil.NewBase(t)
+
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	inspect := base.InspectImage(testutil.CommonImage)
+	out := base.Cmd("image", "history", "--format", "json", testutil.CommonImage).Out()
+
+	var entries []historyJSON
+	assert.NilError(t, json.Unmarshal([]byte(out), &entries), "output: %q", out)
+	assert.Assert(t, len(entries) > 0)
+	nonEmpty := 0
+	for _, e := range entries {
+		if e.EmptyLayer {
+			assert.Equal(t, e.ID, "<missing>")
+		} else {
+			assert.Assert(t, e.ID != "<missing>")
+			nonEmpty++
+		}
+		_, err := time.Parse(time.RFC3339, e.CreatedAt)
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, nonEmpty, len(inspect.RootFS.Layers))
+
+	// round-trip: re-encoding the entries must give back the same output
+	b, err := json.MarshalIndent(entries, "", "    ")
+	assert.NilError(t, err)
+	assert.Equal(t, string(b)+"\n", out)
+}
+
+func TestImageHistoryNoTrunc(t *testing.T) {
+	testutil.RequiresBuild(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires RUN of a Linux image")
+	}
+	base := testutil.NewBase(t)
+	defer base.Cmd("builder", "prune").Run()
+	imageName := testutil.Identifier(t)
+	defer base.Cmd("rmi", imageName).Run()
+
+	longCommand := "echo " + strings.Repeat("nerdctl-no-trunc-", 5) + " > /no-trunc"
+	dockerfile := fmt.Sprintf(`FROM %s
+RUN %s`, testutil.CommonImage, longCommand)
+	buildCtx := createBuildContext(t, dockerfile)
+	base.Cmd("build", "-t", imageName, buildCtx).AssertOK()
+
+	base.Cmd("image", "history", "--no-trunc", "--format", "{{.CreatedBy}}", imageName).AssertOutContains(longCommand)
+	base.Cmd("image", "history", "--format", "{{.CreatedBy}}", imageName).AssertOutWithFunc(func(out string) error {
+		if strings.Contains(out, longCommand) {
+			return fmt.Errorf("expected CREATED BY to be truncated, got %q", out)
+		}
+		return nil
+	})
+}
@@ -42,6 +42,13 @@ func newImageInspectCommand() *cobra.Command {
 	imageInspectCommand.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
 	})
+	imageInspectCommand.Flags().Bool("json-compact", false, "Print the JSON output on a single line instead of indenting it")
+	imageInspectCommand.Flags().Bool("follow-index", false, "Resolve the index to the manifest of the host platform (or --platform), and fail if it is absent")
+	imageInspectCommand.Flags().Bool("platforms", false, "Show the platforms (OS, architecture, and variant) available for the image")
+	imageInspectCommand.Flags().Bool("show-lease", false, "Show the leases (IDs and expirations) that protect the content of the image")
+	imageInspectCommand.Flags().Bool("download-sizes", false, "Show the compressed sizes of the layers and their sum, read from the manifest")
+	imageInspectCommand.Flags().Bool("diff", false, "Show the differences (config, layers, and size) between the two images, from the first to the second")
+	imageInspectCommand.Flags().Bool("config-only", false, "Show only the image config JSON of the platform, verbatim")
 
 	// #region platform flags
 	imageInspectCommand.Flags().String("platform", "", "Inspect a specific platform") // not a slice, and there is no --all-platforms
@@ -64,6 +71,24 @@ func processImageInspectOptions(cmd *cobra.Command, platform *string) (types.Ima
 	if err != nil {
 		return types.ImageInspectOptions{}, err
 	}
+	// the image-specific flags are looked up one by one, as `nerdctl inspect` and others may register only some of them
+	var jsonCompact, followIndex, showPlatforms, showLease, downloadSizes, diff, configOnly bool
+	for name, v := range map[string]*bool{
+		"json-compact":   &jsonCompact,
+		"follow-index":   &followIndex,
+		"platforms":      &showPlatforms,
+		"show-lease":     &showLease,
+		"download-sizes": &downloadSizes,
+		"diff":           &diff,
+		"config-only":    &configOnly,
+	} {
+		if cmd.Flags().Lookup(name) == nil {
+			continue
+		}
+		if *v, err = cmd.Flags().GetBool(name); err != nil {
+			return types.ImageInspectOptions{}, err
+		}
+	}
 	if platform == nil {
 		tempPlatform, err := cmd.Flags().GetString("platform")
 		if err != nil {
@@ -72,11 +97,18 @@ func processImageInspectOptions(cmd *cobra.Command, platform *string) (types.Ima
 		platform = &tempPlatform
 	}
 	return types.ImageInspectOptions{
-		GOptions: globalOptions,
-		Mode:     mode,
-		Format:   format,
-		Platform: *platform,
-		Stdout:   cmd.OutOrStdout(),
+		GOptions:      globalOptions,
+		Mode:          mode,
+		Format:        format,
+		Platform:      *platform,
+		JSONCompact:   jsonCompact,
+		FollowIndex:   followIndex,
+		Platforms:     showPlatforms,
+		ShowLease:     showLease,
+		DownloadSizes: downloadSizes,
+		Diff:          diff,
+		ConfigOnly:    configOnly,
+		Stdout:        cmd.OutOrStdout(),
 	}, nil
 }
 
@@ -17,9 +17,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/leases"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
 )
 
@@ -45,3 +55,252 @@ func TestImageInspectWithFormat(t *testing.T) {
 	// test typedFormat support
 	base.Cmd("image", "inspect", testutil.CommonImage, "--format", "{{.ID}}").AssertOK()
 }
+
+func TestImageInspectJSONCompact(t *testing.T) {
+	base := testutil.NewBase(t)
+
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	out := base.Cmd("image", "inspect", "--json-compact", testutil.CommonImage).Out()
+	assert.Assert(base.T, !strings.Contains(strings.TrimSuffix(out, "\n"), "\n"), "compact output must not contain newlines: %q", out)
+
+	out = base.Cmd("image", "inspect", testutil.CommonImage).Out()
+	assert.Assert(base.T, strings.Contains(strings.TrimSuffix(out, "\n"), "\n"), "default output must be indented: %q", out)
+}
+
+func TestImageInspectFollowIndex(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+
+	// CommonImage is a multi-platform index, only the host platform is pulled
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	var inspect []native.Image
+	out := base.Cmd("image", "inspect", "--mode=native", "--follow-index", testutil.CommonImage).Out()
+	assert.NilError(base.T, json.Unmarshal([]byte(out), &inspect))
+	assert.Equal(base.T, 1, len(inspect))
+	assert.Assert(base.T, inspect[0].IndexDesc != nil)
+	assert.Assert(base.T, inspect[0].Platform != nil)
+	assert.Equal(base.T, runtime.GOARCH, inspect[0].Platform.Architecture)
+	assert.Equal(base.T, runtime.GOARCH, inspect[0].ImageConfig.Architecture)
+
+	otherPlatform := "linux/s390x"
+	if runtime.GOARCH == "s390x" {
+		otherPlatform = "linux/amd64"
+	}
+	base.Cmd("image", "inspect", "--follow-index", "--platform", otherPlatform, testutil.CommonImage).AssertFail()
+}
+
+func TestImageInspectBadReference(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+
+	malformed := base.Cmd("image", "inspect", "::::")
+	malformed.AssertFail()
+	malformed.AssertErrContains("invalid reference format")
+	malformed.AssertErrNotContains("no such image")
+
+	absent := base.Cmd("image", "inspect", testutil.Identifier(t)+":absent")
+	absent.AssertFail()
+	absent.AssertErrContains("no such image")
+	absent.AssertErrNotContains("invalid reference format")
+
+	// the full ID and the digest are not references, but are still inspected
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	id := strings.TrimSpace(base.Cmd("image", "inspect", "--format={{.ID}}", testutil.CommonImage).Out())
+	base.Cmd("image", "inspect", id).AssertOK()
+	base.Cmd("image", "inspect", strings.TrimPrefix(id, "sha256:")).AssertOK()
+	// the digest of the index
+	target := strings.Fields(base.Cmd("images", "--quiet", "--no-trunc", testutil.CommonImage).Out())[0]
+	assert.Assert(t, target != id)
+	base.Cmd("image", "inspect", target).AssertOK()
+}
+
+func TestImageInspectPlatforms(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+
+	type imagePlatforms struct {
+		Name      string
+		Platforms []struct {
+			OS           string
+			Architecture string
+			Variant      string
+		}
+	}
+
+	// CommonImage is a multi-platform index
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	var inspect []imagePlatforms
+	out := base.Cmd("image", "inspect", "--platforms", testutil.CommonImage).Out()
+	assert.NilError(base.T, json.Unmarshal([]byte(out), &inspect))
+	assert.Equal(base.T, 1, len(inspect))
+	assert.Assert(base.T, len(inspect[0].Platforms) > 1)
+	var hostFound bool
+	for _, p := range inspect[0].Platforms {
+		if p.OS == runtime.GOOS && p.Architecture == runtime.GOARCH {
+			hostFound = true
+		}
+	}
+	assert.Assert(base.T, hostFound)
+
+	// single-platform image
+	singleImage := testutil.Identifier(t) + ":single"
+	base.Cmd("image", "convert", "--oci", "--platform", runtime.GOOS+"/"+runtime.GOARCH, testutil.CommonImage, singleImage).AssertOK()
+	defer base.Cmd("rmi", singleImage).Run()
+	base.Cmd("image", "inspect", "--platforms", "--format", "{{range .Platforms}}{{.OS}}/{{.Architecture}}{{end}}", singleImage).
+		AssertOutExactly(runtime.GOOS + "/" + runtime.GOARCH + "\n")
+}
+
+func TestImageInspectFormatTable(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	repo := testutil.Identifier(t)
+	for _, tag := range []string{"one", "two"} {
+		base.Cmd("tag", testutil.CommonImage, repo+":"+tag).AssertOK()
+		defer base.Cmd("rmi", repo+":"+tag).Run()
+	}
+
+	out := base.Cmd("image", "inspect", "--format", `table {{.Repository}}\t{{.Tag}}\t{{.Architecture}}\t{{.Size}}`,
+		testutil.CommonImage, repo+":one", repo+":two").Out()
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	assert.Equal(base.T, 4, len(lines), out)
+	assert.DeepEqual(base.T, []string{"REPOSITORY", "TAG", "ARCHITECTURE", "SIZE"}, strings.Fields(lines[0]))
+	for i, tag := range []string{"one", "two"} {
+		fields := strings.Fields(lines[i+2])
+		assert.Equal(base.T, 4, len(fields), lines[i+2])
+		assert.DeepEqual(base.T, []string{repo, tag, runtime.GOARCH}, fields[:3])
+	}
+}
+
+func TestImageInspectShowLease(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	// use a dedicated namespace, so that no other test leases the content
+	namespace := testutil.Identifier(t)
+	base := testutil.NewBaseWithNamespace(t, namespace)
+	defer base.Cmd("namespace", "remove", namespace).Run()
+
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	defer base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+	base.Cmd("pull", testutil.NginxAlpineImage).AssertOK()
+	defer base.Cmd("rmi", "-f", testutil.NginxAlpineImage).Run()
+
+	client, err := containerd.New(base.ContainerdAddress(), containerd.WithDefaultNamespace(namespace))
+	assert.NilError(base.T, err)
+	defer client.Close()
+	ctx := context.TODO()
+
+	ref, err := referenceutil.ParseAny(testutil.CommonImage)
+	assert.NilError(base.T, err)
+	img, err := client.ImageService().Get(ctx, ref.String())
+	assert.NilError(base.T, err)
+	lease, err := client.LeasesService().Create(ctx, leases.WithRandomID())
+	assert.NilError(base.T, err)
+	defer client.LeasesService().Delete(ctx, lease)
+	assert.NilError(base.T, client.LeasesService().AddResource(ctx, lease, leases.Resource{ID: img.Target.Digest.String(), Type: "content"}))
+
+	base.Cmd("image", "inspect", "--show-lease", "--format", "{{json .Leases}}", testutil.CommonImage).AssertOutContains(lease.ID)
+	base.Cmd("image", "inspect", "--show-lease", "--mode=native", "--format", "{{json .Leases}}", testutil.CommonImage).AssertOutContains(lease.ID)
+	base.Cmd("image", "inspect", "--show-lease", "--format", "{{json .Leases}}", testutil.NginxAlpineImage).AssertOutExactly("[]\n")
+	// the leases are not shown without --show-lease
+	base.Cmd("image", "inspect", "--format", "{{json .Leases}}", testutil.CommonImage).AssertOutExactly("null\n")
+}
+
+func TestImageInspectDownloadSizes(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+
+	var manifest ocispec.Manifest
+	out := base.Cmd("image", "inspect", "--mode=native", "--format", "{{json .Manifest}}", testutil.CommonImage).Out()
+	assert.NilError(t, json.Unmarshal([]byte(out), &manifest), out)
+	assert.Assert(t, len(manifest.Layers) > 0)
+
+	var sizes native.DownloadSizes
+	out = base.Cmd("image", "inspect", "--download-sizes", "--format", "{{json .DownloadSizes}}", testutil.CommonImage).Out()
+	assert.NilError(t, json.Unmarshal([]byte(out), &sizes), out)
+	assert.Equal(t, len(sizes.Layers), len(manifest.Layers))
+	var total int64
+	for i, l := range manifest.Layers {
+		assert.Equal(t, sizes.Layers[i].Digest, l.Digest.String())
+		assert.Equal(t, sizes.Layers[i].Size, l.Size)
+		total += l.Size
+	}
+	assert.Equal(t, sizes.Total, total)
+	base.Cmd("image", "inspect", "--download-sizes", "--mode=native", "--format", "{{.DownloadSizes.Total}}", testutil.CommonImage).
+		AssertOutExactly(fmt.Sprintf("%d\n", total))
+	// the download sizes are not shown without --download-sizes
+	base.Cmd("image", "inspect", "--format", "{{json .DownloadSizes}}", testutil.CommonImage).AssertOutExactly("null\n")
+}
+
+func TestImageInspectDiff(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	testutil.RequiresBuild(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires RUN of a Linux image")
+	}
+	base := testutil.NewBase(t)
+	defer base.Cmd("builder", "prune").Run()
+	imageName := testutil.Identifier(t)
+	defer base.Cmd("rmi", imageName).Run()
+
+	dockerfile := fmt.Sprintf(`FROM %s
+ENV NERDCTL_TEST_DIFF=1
+RUN echo diff > /diff`, testutil.CommonImage)
+	buildCtx := createBuildContext(t, dockerfile)
+	base.Cmd("build", "-t", imageName, buildCtx).AssertOK()
+
+	var diffs []struct {
+		Env *struct {
+			Added   []string
+			Removed []string
+		}
+		Cmd    interface{}
+		Layers struct {
+			Shared  []string
+			Added   []string
+			Removed []string
+		}
+	}
+	out := base.Cmd("image", "inspect", "--diff", testutil.CommonImage, imageName).Out()
+	assert.NilError(t, json.Unmarshal([]byte(out), &diffs), out)
+	assert.Equal(t, len(diffs), 1)
+	d := diffs[0]
+	assert.Assert(t, d.Env != nil, out)
+	assert.DeepEqual(t, d.Env.Added, []string{"NERDCTL_TEST_DIFF=1"})
+	assert.Equal(t, len(d.Env.Removed), 0)
+	assert.Assert(t, d.Cmd == nil, out)
+	assert.Equal(t, len(d.Layers.Added), 1)
+	assert.Equal(t, len(d.Layers.Removed), 0)
+	assert.DeepEqual(t, d.Layers.Shared, base.InspectImage(testutil.CommonImage).RootFS.Layers)
+
+	base.Cmd("image", "inspect", "--diff", testutil.CommonImage).AssertFail()
+}
+
+func TestImageInspectConfigOnly(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+
+	out := base.Cmd("image", "inspect", "--config-only", testutil.CommonImage).Out()
+	var config ocispec.Image
+	assert.NilError(base.T, json.Unmarshal([]byte(out), &config), out)
+	assert.Equal(base.T, config.OS, runtime.GOOS)
+	assert.Assert(base.T, len(config.RootFS.DiffIDs) > 0)
+
+	// the same config as the inspection
+	inspect := base.InspectImage(testutil.CommonImage)
+	assert.DeepEqual(base.T, config.Config.Env, inspect.Config.Env)
+
+	base.Cmd("image", "inspect", "--config-only", "--format", "{{json .}}", testutil.CommonImage).AssertFail()
+
+	// the config of another platform
+	otherArch := "arm64"
+	if runtime.GOARCH == "arm64" {
+		otherArch = "amd64"
+	}
+	base.Cmd("pull", "--platform", "linux/"+otherArch, testutil.CommonImage).AssertOK()
+	out = base.Cmd("image", "inspect", "--config-only", "--platform", "linux/"+otherArch, testutil.CommonImage).Out()
+	assert.NilError(base.T, json.Unmarshal([]byte(out), &config), out)
+	assert.Equal(base.T, config.Architecture, otherArch)
+}
@@ -18,15 +18,19 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/config"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/spf13/cobra"
 )
 
-func newImagesCommand() *cobra.Command {
+// newImagesCommand creates `nerdctl images`.
+// The defaults of the flags are read from the [images] section of nerdctl.toml.
+func newImagesCommand(imagesConfig config.Images) *cobra.Command {
 	shortHelp := "List images"
 	longHelp := shortHelp + `
 
@@ -45,7 +49,7 @@ Properties:
 		Short:                 shortHelp,
 		Long:                  longHelp,
 		Args:                  cobra.MaximumNArgs(1),
-		RunE:                  imagesAction,
+		RunE:                  newImagesAction(imagesConfig.Hide),
 		ValidArgsFunction:     imagesShellComplete,
 		SilenceUsage:          true,
 		SilenceErrors:         true,
@@ -53,21 +57,38 @@ Properties:
 	}
 
 	imagesCommand.Flags().BoolP("quiet", "q", false, "Only show numeric IDs")
-	imagesCommand.Flags().Bool("no-trunc", false, "Don't truncate output")
+	imagesCommand.Flags().Bool("no-trunc", imagesConfig.NoTrunc, "Don't truncate output")
 	// Alias "-f" is reserved for "--filter"
-	imagesCommand.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}', 'wide'")
-	imagesCommand.Flags().StringSliceP("filter", "f", []string{}, "Filter output based on conditions provided")
+	imagesCommand.Flags().String("format", imagesConfig.Format, "Format the output using the given Go template, e.g, '{{json .}}', 'wide', 'csv', 'tsv'")
+	imagesCommand.Flags().StringSliceP("filter", "f", imagesConfig.Filters, "Filter output based on conditions provided")
 	imagesCommand.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "table", "wide"}, cobra.ShellCompDirectiveNoFileComp
+		return []string{"json", "table", "wide", "csv", "tsv"}, cobra.ShellCompDirectiveNoFileComp
 	})
-	imagesCommand.Flags().Bool("digests", false, "Show digests (compatible with Docker, unlike ID)")
-	imagesCommand.Flags().Bool("names", false, "Show image names")
+	imagesCommand.Flags().Bool("digests", imagesConfig.Digests, "Show digests (compatible with Docker, unlike ID)")
+	imagesCommand.Flags().Bool("names", imagesConfig.Names, "Show image names")
 	imagesCommand.Flags().BoolP("all", "a", true, "(unimplemented yet, always true)")
+	imagesCommand.Flags().String("created-from-label", "", "Read the created time (RFC3339) from the image label with the given key, when present")
+	imagesCommand.Flags().Bool("probe-snapshotters", false, "Compute the size by probing all the registered snapshotters, for images unpacked under different snapshotters")
+	imagesCommand.Flags().Bool("show-logical-size", false, "Show the logical size of the layers, e.g., for images lazily pulled by remote snapshotters")
+	imagesCommand.Flags().Bool("all-platforms", false, "Show a single row for all the platforms of an image, with the sizes summed up across the platforms")
+	imagesCommand.Flags().String("totals-by", "", "Show the deduplicated sizes of the images grouped by the key (arch), instead of the images")
+	imagesCommand.RegisterFlagCompletionFunc("totals-by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"arch"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	imagesCommand.Flags().Bool("no-cache", false, "Compute the sizes of the snapshots live, without reading or updating the size cache")
+	imagesCommand.Flags().String("sort", "", "Sort the images by the key (digest), with the repository and the tag as tiebreakers")
+	imagesCommand.RegisterFlagCompletionFunc("sort", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"digest"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	imagesCommand.Flags().Int("max-concurrency", 0, "Maximum number of the images whose sizes are computed in parallel (0 for GOMAXPROCS, 1 for serial)")
+	imagesCommand.Flags().Bool("verbose", false, "Print the number of the shown and the filtered out images to stderr")
 
 	return imagesCommand
 }
 
-func processImageListOptions(cmd *cobra.Command, args []string) (types.ImageListOptions, error) {
+// processImageListOptions parses the flags of `nerdctl images`.
+// The images matching `hide` are hidden, unless `--filter` is specified.
+func processImageListOptions(cmd *cobra.Command, args []string, hide []string) (types.ImageListOptions, error) {
 	globalOptions, err := processRootCmdFlags(cmd)
 	if err != nil {
 		return types.ImageListOptions{}, err
@@ -77,6 +98,9 @@ func processImageListOptions(cmd *cobra.Command, args []string) (types.ImageList
 	if len(args) > 0 {
 		canonicalRef, err := referenceutil.ParseAny(args[0])
 		if err != nil {
+			if strings.ContainsAny(args[0], "*?[]{}") {
+				err = fmt.Errorf("%w (Hint: use `--filter=reference=%s` for a pattern)", err, args[0])
+			}
 			return types.ImageListOptions{}, err
 		}
 		filters = append(filters, fmt.Sprintf("name==%s", canonicalRef.String()))
@@ -94,12 +118,13 @@ func processImageListOptions(cmd *cobra.Command, args []string) (types.ImageList
 	if err != nil {
 		return types.ImageListOptions{}, err
 	}
-	var inputFilters []string
+	// the default of --filter may be set in nerdctl.toml
+	inputFilters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
 	if cmd.Flags().Changed("filter") {
-		inputFilters, err = cmd.Flags().GetStringSlice("filter")
-		if err != nil {
-			return types.ImageListOptions{}, err
-		}
+		hide = nil
 	}
 	digests, err := cmd.Flags().GetBool("digests")
 	if err != nil {
@@ -109,23 +134,90 @@ func processImageListOptions(cmd *cobra.Command, args []string) (types.ImageList
 	if err != nil {
 		return types.ImageListOptions{}, err
 	}
+	createdFromLabel, err := cmd.Flags().GetString("created-from-label")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	probeSnapshotters, err := cmd.Flags().GetBool("probe-snapshotters")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	showLogicalSize, err := cmd.Flags().GetBool("show-logical-size")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	allPlatforms, err := cmd.Flags().GetBool("all-platforms")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	totalsBy, err := cmd.Flags().GetString("totals-by")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	switch totalsBy {
+	case "", "arch":
+	default:
+		return types.ImageListOptions{}, fmt.Errorf("invalid totals-by %q (must be \"arch\")", totalsBy)
+	}
+	sortBy, err := cmd.Flags().GetString("sort")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	switch sortBy {
+	case "", "digest":
+	default:
+		return types.ImageListOptions{}, fmt.Errorf("invalid sort %q (must be \"digest\")", sortBy)
+	}
+	maxConcurrency, err := cmd.Flags().GetInt("max-concurrency")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	noCache, err := cmd.Flags().GetBool("no-cache")
+	if err != nil {
+		return types.ImageListOptions{}, err
+	}
+	if maxConcurrency < 0 {
+		return types.ImageListOptions{}, fmt.Errorf("invalid max-concurrency %d (must be 0 or greater)", maxConcurrency)
+	}
 	return types.ImageListOptions{
-		GOptions:         globalOptions,
-		Quiet:            quiet,
-		NoTrunc:          noTrunc,
-		Format:           format,
-		Filters:          inputFilters,
-		NameAndRefFilter: filters,
-		Digests:          digests,
-		Names:            names,
-		All:              true,
-		Stdout:           cmd.OutOrStdout(),
+		GOptions:          globalOptions,
+		Quiet:             quiet,
+		NoTrunc:           noTrunc,
+		Format:            format,
+		Filters:           inputFilters,
+		Hide:              hide,
+		NameAndRefFilter:  filters,
+		Digests:           digests,
+		Names:             names,
+		All:               true,
+		CreatedFromLabel:  createdFromLabel,
+		ProbeSnapshotters: probeSnapshotters,
+		Verbose:           verbose,
+		ShowLogicalSize:   showLogicalSize,
+		AllPlatforms:      allPlatforms,
+		MaxConcurrency:    maxConcurrency,
+		TotalsBy:          totalsBy,
+		Sort:              sortBy,
+		NoCache:           noCache,
+		Stdout:            cmd.OutOrStdout(),
+		Stderr:            cmd.ErrOrStderr(),
 	}, nil
 
 }
 
-func imagesAction(cmd *cobra.Command, args []string) error {
-	options, err := processImageListOptions(cmd, args)
+// newImagesAction returns the action of `nerdctl images`, hiding the images matching `hide` unless `--filter` is specified.
+func newImagesAction(hide []string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return imagesAction(cmd, args, hide)
+	}
+}
+
+func imagesAction(cmd *cobra.Command, args []string, hide []string) error {
+	options, err := processImageListOptions(cmd, args, hide)
 	if err != nil {
 		return err
 	}
@@ -17,13 +17,20 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/containerd/nerdctl/v2/pkg/tabutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
 )
 
 func TestImagesWithNames(t *testing.T) {
@@ -133,3 +140,331 @@ CMD ["echo", "nerdctl-build-notag-string"]
 	base.Cmd("images", "--filter", "dangling=true").AssertOutContains("<none>")
 	base.Cmd("images", "--filter", "dangling=false").AssertOutNotContains("<none>")
 }
+
+func TestImagesFormatDangling(t *testing.T) {
+	testutil.RequiresBuild(t)
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	base.Cmd("images", "prune", "--all").AssertOK()
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+
+	dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-build-notag-string"]
+	`, testutil.CommonImage)
+	buildCtx := createBuildContext(t, dockerfile)
+
+	base.Cmd("build", "-f", buildCtx+"/Dockerfile", buildCtx).AssertOK()
+
+	base.Cmd("images", "--format", "{{.Repository}}:{{.Tag}} {{.Dangling}}").AssertOutWithFunc(func(stdout string) error {
+		var tagged, untagged bool
+		for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+			switch line {
+			case testutil.CommonImage + " false":
+				tagged = true
+			case "<none>:<none> true":
+				untagged = true
+			default:
+				if strings.HasPrefix(line, "<none>:<none> ") || strings.HasPrefix(line, testutil.CommonImage+" ") {
+					return fmt.Errorf("unexpected Dangling value in %q", line)
+				}
+			}
+		}
+		if !tagged || !untagged {
+			return fmt.Errorf("expected both the tagged and the untagged images, got %q", stdout)
+		}
+		return nil
+	})
+	base.Cmd("images", "--format", "{{if .Dangling}}{{.Repository}}{{end}}").AssertOutContains("<none>")
+}
+
+func TestImagesFilterSize(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("busybox is not available for windows")
+	}
+	base := testutil.NewBase(t)
+
+	// the unpacked size of testutil.CommonImage is more than 4MB, and the one of busybox:uclibc is ~2MB
+	busyboxUclibc := "busybox:uclibc"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("pull", busyboxUclibc).AssertOK()
+	defer base.Cmd("rmi", busyboxUclibc).AssertOK()
+
+	base.Cmd("images", "--filter", "size>4MB").AssertOutContains(testutil.ImageRepo(testutil.CommonImage))
+	base.Cmd("images", "--filter", "size>4MB").AssertOutNotContains("uclibc")
+	base.Cmd("images", "--filter", "size<=4MB").AssertOutContains("uclibc")
+	base.Cmd("images", "--filter", "size<=4MB").AssertOutNotContains(testutil.ImageRepo(testutil.CommonImage))
+	base.Cmd("images", "--filter", "size>1KB", "--filter", "size<4MB", "--filter", "reference=busybox").AssertOutContains("uclibc")
+	base.Cmd("images", "--filter", "size<4MB", "--filter", "reference="+testutil.CommonImage).AssertOutNotContains(testutil.ImageRepo(testutil.CommonImage))
+	base.Cmd("images", "--filter", "size>foo").AssertFail()
+}
+
+func TestImagesConfigDefaults(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	hidden := testutil.Identifier(t) + "-hidden:latest"
+	tomlPath := filepath.Join(t.TempDir(), "nerdctl.toml")
+	err := os.WriteFile(tomlPath, []byte(fmt.Sprintf(`
+[images]
+hide    = [%q]
+digests = true
+`, hidden)), 0400)
+	assert.NilError(t, err)
+
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, hidden).AssertOK()
+	defer base.Cmd("rmi", hidden).Run()
+
+	if len(base.Env) == 0 {
+		base.Env = os.Environ()
+	}
+	base.Env = append(base.Env, "NERDCTL_TOML="+tomlPath)
+
+	out := base.Cmd("images").Out()
+	assert.Assert(t, strings.Contains(out, "DIGEST"), out)
+	assert.Assert(t, !strings.Contains(out, testutil.Identifier(t)+"-hidden"), out)
+
+	// flags on the command line override the config
+	out = base.Cmd("images", "--digests=false", "--filter", "reference="+hidden).Out()
+	assert.Assert(t, !strings.Contains(out, "DIGEST"), out)
+	assert.Assert(t, strings.Contains(out, testutil.Identifier(t)+"-hidden"), out)
+}
+
+func TestImagesFilterReferenceHint(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	// tagged as docker.io/library/<id>:latest
+	img := testutil.Identifier(t)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, img).AssertOK()
+	defer base.Cmd("rmi", img).Run()
+
+	// "library/" is missing, so the pattern does not match anything
+	cmd := base.Cmd("images", "--quiet", "--filter", "reference=docker.io/"+img+"*")
+	cmd.AssertOutExactly("")
+	cmd.AssertErrContains(fmt.Sprintf("Hint: try the normalized form %q", "docker.io/library/"+img+"*"))
+
+	base.Cmd("images", "--quiet", "--filter", "reference=docker.io/library/"+img+"*").AssertOutWithFunc(func(stdout string) error {
+		if strings.TrimSpace(stdout) == "" {
+			return errors.New("expected the normalized form to match the image")
+		}
+		return nil
+	})
+
+	// a pattern is not a valid argument, but a reference filter
+	base.Cmd("images", img+"*").Assert(icmd.Expected{
+		ExitCode: 1,
+		Err:      fmt.Sprintf("Hint: use `--filter=reference=%s*` for a pattern", img),
+	})
+}
+
+func TestImagesVerboseSummary(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	img := testutil.Identifier(t)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, img).AssertOK()
+	defer base.Cmd("rmi", img).Run()
+
+	result := base.Cmd("images", "--verbose", "--quiet", "--filter", "reference="+img).Run()
+	assert.Equal(t, result.ExitCode, 0, result.Combined())
+	assert.Assert(t, !strings.Contains(result.Stdout(), "Showing"), result.Stdout())
+
+	var shown, total, filtered int
+	stderrLines := strings.Split(strings.TrimSpace(result.Stderr()), "\n")
+	_, err := fmt.Sscanf(stderrLines[len(stderrLines)-1], "Showing %d of %d images (%d filtered out)", &shown, &total, &filtered)
+	assert.NilError(t, err, result.Stderr())
+	assert.Equal(t, shown, 1)
+	// at least CommonImage is filtered out
+	assert.Assert(t, total >= 2, result.Stderr())
+	assert.Equal(t, filtered, total-shown)
+}
+
+func TestImagesFilterReferenceExclude(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	tID := testutil.Identifier(t)
+	imgA, imgB := tID+"-a:v1", tID+"-b:v1"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	for _, img := range []string{imgA, imgB} {
+		base.Cmd("tag", testutil.CommonImage, img).AssertOK()
+		defer base.Cmd("rmi", img).Run()
+	}
+
+	images := func(filters ...string) string {
+		args := []string{"images", "--names", "--format", "{{.Name}}"}
+		for _, f := range filters {
+			args = append(args, "--filter", f)
+		}
+		return base.Cmd(args...).Out()
+	}
+	out := images("reference="+tID+"-*", "reference="+testutil.CommonImage, "reference=!"+tID+"-b*")
+	assert.Assert(t, strings.Contains(out, tID+"-a:v1"), out)
+	assert.Assert(t, !strings.Contains(out, tID+"-b:v1"), out)
+	assert.Assert(t, strings.Contains(out, testutil.CommonImage), out)
+
+	out = images("reference="+tID+"-*", "reference!="+tID+"-a*")
+	assert.Assert(t, !strings.Contains(out, tID+"-a:v1"), out)
+	assert.Assert(t, strings.Contains(out, tID+"-b:v1"), out)
+}
+
+func TestImagesShowLogicalSize(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no windows support yet")
+	}
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	// eStargz layers carry the uncompressed size annotations used by remote snapshotters for lazy pulling
+	convertedImage := testutil.Identifier(t) + ":esgz"
+	base.Cmd("rmi", convertedImage).Run()
+	defer base.Cmd("rmi", convertedImage).Run()
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("image", "convert", "--estargz", "--oci", testutil.CommonImage, convertedImage).AssertOK()
+
+	base.Cmd("images", "--show-logical-size", convertedImage).AssertOutWithFunc(func(out string) error {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) < 2 {
+			return fmt.Errorf("expected at least 2 lines, got %d", len(lines))
+		}
+		tab := tabutil.NewReader("REPOSITORY\tTAG\tIMAGE ID\tCREATED\tPLATFORM\tSIZE\tBLOB SIZE\tLOGICAL SIZE")
+		if err := tab.ParseHeader(lines[0]); err != nil {
+			return fmt.Errorf("failed to parse header: %v", err)
+		}
+		logicalSize, _ := tab.ReadRow(lines[1], "LOGICAL SIZE")
+		if logicalSize == "" || logicalSize == "0B" || logicalSize == "0 B" {
+			return fmt.Errorf("expected non-zero logical size, got %q", logicalSize)
+		}
+		return nil
+	})
+	base.Cmd("images", "--format", "{{.LogicalSize}}", convertedImage).AssertOutExactly("\n")
+}
+
+func TestImagesFormatDigestAndID(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+
+	digest := strings.TrimSpace(base.Cmd("images", "--no-trunc", "--format", "{{.Digest}}", testutil.CommonImage).Out())
+	id := strings.TrimSpace(base.Cmd("images", "--no-trunc", "--format", "{{.ID}}", testutil.CommonImage).Out())
+	assert.Assert(t, strings.HasPrefix(digest, "sha256:"), digest)
+	assert.Assert(t, strings.HasPrefix(id, "sha256:"), id)
+	assert.Assert(t, digest != id, "the manifest digest and the config digest must differ")
+
+	// .ID is the config digest, i.e., the ID of `image inspect`
+	base.Cmd("image", "inspect", "--format", "{{.Id}}", testutil.CommonImage).AssertOutExactly(id + "\n")
+	// .Digest is the target digest, i.e., the digest of the repo digest
+	base.Cmd("image", "inspect", "--format", "{{range .RepoDigests}}{{println .}}{{end}}", testutil.CommonImage).AssertOutContains("@" + digest + "\n")
+	// the short form of .ID
+	base.Cmd("images", "--format", "{{.ID}}", testutil.CommonImage).AssertOutExactly(strings.TrimPrefix(id, "sha256:")[:12] + "\n")
+
+	// the short form of .ID can be passed to the other commands
+	base.Cmd("image", "inspect", "--format", "{{.Id}}", strings.TrimPrefix(id, "sha256:")[:12]).AssertOutContains(id)
+}
+
+func TestImagesAllPlatforms(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	// use a dedicated namespace, so that the platforms pulled by the other tests do not interfere
+	namespace := testutil.Identifier(t)
+	base := testutil.NewBaseWithNamespace(t, namespace)
+	defer base.Cmd("namespace", "remove", namespace).Run()
+
+	base.Cmd("pull", "--platform", "linux/amd64", "--platform", "linux/arm64", testutil.CommonImage).AssertOK()
+	defer base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+
+	base.Cmd("images", "--all-platforms", "--format", "{{.Platform}}", testutil.CommonImage).
+		AssertOutWithFunc(func(out string) error {
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if len(lines) != 1 {
+				return fmt.Errorf("expected a single row, got %q", out)
+			}
+			platforms := strings.Split(lines[0], ",")
+			sort.Strings(platforms)
+			if !reflect.DeepEqual(platforms, []string{"linux/amd64", "linux/arm64"}) {
+				return fmt.Errorf("unexpected platforms %q", lines[0])
+			}
+			return nil
+		})
+	base.Cmd("images", "--all-platforms", testutil.CommonImage).AssertOutContains("PLATFORMS")
+	// the ID of the summary row is the target digest
+	digest := strings.Fields(base.Cmd("images", "--no-trunc", "--format", "{{.Digest}}", testutil.CommonImage).Out())[0]
+	base.Cmd("images", "--all-platforms", "--no-trunc", "--format", "{{.ID}}", testutil.CommonImage).AssertOutExactly(digest + "\n")
+}
+
+func TestImagesFormatCSV(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	t.Parallel()
+	base := testutil.NewBase(t)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+
+	base.Cmd("images", "--format", "csv", testutil.CommonImage).AssertOutWithFunc(func(out string) error {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) < 2 {
+			return fmt.Errorf("expected a header and the rows, got %q", out)
+		}
+		if lines[0] != "REPOSITORY,TAG,IMAGE ID,CREATED,PLATFORM,SIZE,BLOB SIZE" {
+			return fmt.Errorf("unexpected header %q", lines[0])
+		}
+		if !strings.HasPrefix(lines[1], testutil.ImageRepo(testutil.CommonImage)+",") {
+			return fmt.Errorf("unexpected row %q", lines[1])
+		}
+		return nil
+	})
+	base.Cmd("images", "--format", "tsv", testutil.CommonImage).AssertOutContains("REPOSITORY\tTAG\tIMAGE ID\t")
+}
+
+func TestImagesTotalsByArch(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	// use a dedicated namespace, so that the images pulled by the other tests do not interfere
+	namespace := testutil.Identifier(t)
+	base := testutil.NewBaseWithNamespace(t, namespace)
+	defer base.Cmd("namespace", "remove", namespace).Run()
+
+	base.Cmd("pull", "--platform", "linux/amd64", "--platform", "linux/arm64", testutil.CommonImage).AssertOK()
+	defer base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+	// a tag shares the blobs, so the blob sizes must not change
+	tagged := testutil.Identifier(t) + ":tagged"
+	base.Cmd("tag", testutil.CommonImage, tagged).AssertOK()
+	defer base.Cmd("rmi", "-f", tagged).Run()
+
+	blobSizes := make(map[string]string)
+	base.Cmd("images", "--totals-by", "arch").AssertOutWithFunc(func(out string) error {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 3 {
+			return fmt.Errorf("expected a header and the rows of amd64 and arm64, got %q", out)
+		}
+		tab := tabutil.NewReader("ARCH\tIMAGES\tSIZE\tBLOB SIZE")
+		if err := tab.ParseHeader(lines[0]); err != nil {
+			return err
+		}
+		for _, line := range lines[1:] {
+			arch, _ := tab.ReadRow(line, "ARCH")
+			images, _ := tab.ReadRow(line, "IMAGES")
+			blobSize, _ := tab.ReadRow(line, "BLOB SIZE")
+			if images != "2" {
+				return fmt.Errorf("expected 2 images for %q, got %q", arch, images)
+			}
+			blobSizes[arch] = blobSize
+		}
+		return nil
+	})
+	assert.Equal(t, len(blobSizes), 2, blobSizes)
+	for _, arch := range []string{"amd64", "arm64"} {
+		size, ok := blobSizes[arch]
+		assert.Assert(t, ok, blobSizes)
+		assert.Assert(t, !strings.HasPrefix(size, "0"), blobSizes)
+	}
+
+	base.Cmd("rmi", tagged).AssertOK()
+	base.Cmd("images", "--totals-by", "arch").AssertOutWithFunc(func(out string) error {
+		for arch, size := range blobSizes {
+			if !strings.Contains(out, size) {
+				return fmt.Errorf("expected the blob size %q of %q to be unchanged, got %q", size, arch, out)
+			}
+		}
+		return nil
+	})
+	base.Cmd("images", "--totals-by", "os").AssertFail()
+}
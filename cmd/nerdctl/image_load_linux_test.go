@@ -55,3 +55,18 @@ func TestLoadStdinEmpty(t *testing.T) {
 	base := testutil.NewBase(t)
 	base.Cmd("load").AssertFail()
 }
+
+func TestLoadQuiet(t *testing.T) {
+	t.Parallel()
+	base := testutil.NewBase(t)
+
+	tmp := t.TempDir()
+	img := testutil.Identifier(t) + "image"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, img).AssertOK()
+	base.Cmd("save", img, "-o", filepath.Join(tmp, "common.tar")).AssertOK()
+	base.Cmd("rmi", "-f", img).AssertOK()
+	defer base.Cmd("rmi", "-f", img).Run()
+
+	base.Cmd("load", "--quiet", "-i", filepath.Join(tmp, "common.tar")).AssertOutExactly(fmt.Sprintf("Loaded image: %s:latest\n", img))
+}
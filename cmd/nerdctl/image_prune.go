@@ -38,6 +38,8 @@ func newImagePruneCommand() *cobra.Command {
 
 	imagePruneCommand.Flags().BoolP("all", "a", false, "Remove all unused images, not just dangling ones")
 	imagePruneCommand.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	imagePruneCommand.Flags().Bool("verbose", false, "Print the reason (dangling or unused) and the size of each removed image")
+	imagePruneCommand.Flags().StringSlice("filter", []string{}, "Filter the images to be removed (e.g., 'reference=example.com/tmp-*', 'label=<key>=<value>', 'until=24h')")
 	return imagePruneCommand
 }
 
@@ -56,11 +58,23 @@ func processImagePruneOptions(cmd *cobra.Command) (types.ImagePruneOptions, erro
 		return types.ImagePruneOptions{}, err
 	}
 
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err != nil {
+		return types.ImagePruneOptions{}, err
+	}
+
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.ImagePruneOptions{}, err
+	}
+
 	return types.ImagePruneOptions{
 		Stdout:   cmd.OutOrStdout(),
 		GOptions: globalOptions,
 		All:      all,
 		Force:    force,
+		Verbose:  verbose,
+		Filters:  filters,
 	}, err
 }
 
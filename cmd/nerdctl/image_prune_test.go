@@ -17,10 +17,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/leases"
+	"github.com/containerd/containerd/pkg/progress"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/platforms"
+	"gotest.tools/v3/assert"
 )
 
 func TestImagePrune(t *testing.T) {
@@ -71,3 +81,119 @@ func TestImagePruneAll(t *testing.T) {
 	base.Cmd("image", "prune", "--force", "--all").AssertOutContains(imageName)
 	base.Cmd("images").AssertNoOut(imageName)
 }
+
+func TestImagePruneVerbose(t *testing.T) {
+	testutil.RequiresBuild(t)
+	testutil.DockerIncompatible(t)
+
+	base := testutil.NewBase(t)
+	defer base.Cmd("builder", "prune").AssertOK()
+	imageName := testutil.Identifier(t)
+	usedImageName := imageName + "-used"
+
+	dockerfile := fmt.Sprintf(`FROM %s
+	CMD ["echo", "nerdctl-test-image-prune-verbose"]`, testutil.CommonImage)
+
+	buildCtx := createBuildContext(t, dockerfile)
+
+	// a dangling image, an unused image, and an image used by a container
+	base.Cmd("build", buildCtx).AssertOK()
+	base.Cmd("build", "-t", imageName, buildCtx).AssertOK()
+	defer base.Cmd("rmi", imageName).Run()
+	base.Cmd("tag", imageName, usedImageName).AssertOK()
+	defer base.Cmd("rmi", usedImageName).Run()
+	tID := testutil.Identifier(t)
+	base.Cmd("run", "--name", tID, usedImageName).AssertOK()
+	defer base.Cmd("rm", "-f", tID).Run()
+
+	base.Cmd("image", "prune", "--force", "--all", "--verbose").AssertOutWithFunc(func(stdout string) error {
+		var dangling, unused bool
+		for _, line := range strings.Split(stdout, "\n") {
+			if !strings.HasPrefix(line, "Untagged: ") {
+				continue
+			}
+			if strings.Contains(line, usedImageName) {
+				return fmt.Errorf("the image used by a container must not be pruned: %q", line)
+			}
+			if strings.Contains(line, "(reason: dangling, size: ") {
+				dangling = true
+			}
+			if strings.Contains(line, imageName+":latest (reason: unused, size: ") {
+				unused = true
+			}
+		}
+		if !dangling || !unused {
+			return fmt.Errorf("expected both the dangling and the unused images to be pruned with the reasons, got %q", stdout)
+		}
+		return nil
+	})
+	base.Cmd("images").AssertOutContains(usedImageName)
+}
+
+func TestImagePruneLeasedContent(t *testing.T) {
+	testutil.DockerIncompatible(t)
+
+	// use a dedicated namespace, so that the content store only contains the blobs of the pulled image
+	namespace := testutil.Identifier(t)
+	base := testutil.NewBaseWithNamespace(t, namespace)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	defer base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+
+	client, err := containerd.New(base.ContainerdAddress(), containerd.WithDefaultNamespace(namespace))
+	assert.NilError(base.T, err)
+	defer client.Close()
+	ctx := context.TODO()
+	cs := client.ContentStore()
+
+	ref, err := referenceutil.ParseAny(testutil.CommonImage)
+	assert.NilError(base.T, err)
+	img, err := client.ImageService().Get(ctx, ref.String())
+	assert.NilError(base.T, err)
+	manifest, err := images.Manifest(ctx, cs, img.Target, platforms.Default())
+	assert.NilError(base.T, err)
+
+	// protect the layers with a lease, as an in-progress pull of another image sharing them would do
+	lease, err := client.LeasesService().Create(ctx, leases.WithRandomID())
+	assert.NilError(base.T, err)
+	defer client.LeasesService().Delete(ctx, lease, leases.SynchronousDelete)
+	var leasedSize int64
+	for _, layer := range manifest.Layers {
+		assert.NilError(base.T, client.LeasesService().AddResource(ctx, lease, leases.Resource{ID: layer.Digest.String(), Type: "content"}))
+		leasedSize += layer.Size
+	}
+
+	var totalSize int64
+	assert.NilError(base.T, cs.Walk(ctx, func(info content.Info) error {
+		totalSize += info.Size
+		return nil
+	}))
+
+	base.Cmd("image", "prune", "--force", "--all").AssertOutContains(fmt.Sprintf("Total reclaimed space: %s", progress.Bytes(totalSize-leasedSize)))
+	for _, layer := range manifest.Layers {
+		_, err := cs.Info(ctx, layer.Digest)
+		assert.NilError(base.T, err, "leased layer %s must not be garbage collected", layer.Digest)
+	}
+}
+
+func TestImagePruneFilterReference(t *testing.T) {
+	testutil.DockerIncompatible(t) // Docker lacks --filter=reference for prune
+	base := testutil.NewBase(t)
+	prefix := testutil.Identifier(t)
+	tmpImage := prefix + "-tmp-foo"
+	keptImage := prefix + "-kept"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, tmpImage).AssertOK()
+	defer base.Cmd("rmi", "-f", tmpImage).Run()
+	base.Cmd("tag", testutil.CommonImage, keptImage).AssertOK()
+	defer base.Cmd("rmi", "-f", keptImage).Run()
+
+	base.Cmd("image", "prune", "--force", "--all", "--filter", "reference="+prefix+"-tmp-*", "--filter", "until=1h").AssertNoOut(tmpImage)
+	base.Cmd("images", tmpImage).AssertOutContains(tmpImage)
+
+	base.Cmd("image", "prune", "--force", "--all", "--filter", "reference="+prefix+"-tmp-*").AssertOutContains(tmpImage)
+	base.Cmd("images", tmpImage).AssertNoOut(tmpImage)
+	base.Cmd("images", keptImage).AssertOutContains(keptImage)
+	base.Cmd("images", testutil.CommonImage).AssertOutContains(strings.Split(testutil.CommonImage, ":")[0])
+
+	base.Cmd("image", "prune", "--force", "--filter", "since="+keptImage).AssertFail()
+}
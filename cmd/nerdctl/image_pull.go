@@ -17,9 +17,14 @@
 package main
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/pull"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 	"github.com/spf13/cobra"
 )
 
@@ -39,8 +44,11 @@ func newPullCommand() *cobra.Command {
 
 	// #region platform flags
 	// platform is defined as StringSlice, not StringArray, to allow specifying "--platform=amd64,arm64"
-	pullCommand.Flags().StringSlice("platform", nil, "Pull content for a specific platform")
-	pullCommand.RegisterFlagCompletionFunc("platform", shellCompletePlatforms)
+	pullCommand.Flags().StringSlice("platform", nil, "Pull content for a specific platform (\"auto\" for the platform detected from the host)")
+	pullCommand.RegisterFlagCompletionFunc("platform", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		candidates, directive := shellCompletePlatforms(cmd, args, toComplete)
+		return append([]string{platformutil.Auto}, candidates...), directive
+	})
 	pullCommand.Flags().Bool("all-platforms", false, "Pull content for all platforms")
 	// #endregion
 
@@ -61,6 +69,17 @@ func newPullCommand() *cobra.Command {
 	// #endregion
 
 	pullCommand.Flags().BoolP("quiet", "q", false, "Suppress verbose output")
+	pullCommand.Flags().StringArray("label", nil, "Set a label on the pulled image record (key=value)")
+	pullCommand.Flags().Bool("store-metadata", false, "Store the ETag, Last-Modified, and Cache-Control headers of the registry as labels on the pulled image record")
+	pullCommand.Flags().String("progress-output", pull.ProgressFormatAuto, "Format of the progress output (auto|json). \"json\" prints a JSON progress event per line to stdout")
+	pullCommand.RegisterFlagCompletionFunc("progress-output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{pull.ProgressFormatAuto, pull.ProgressFormatJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	pullCommand.Flags().Int("retry", 0, "Number of the retries on a failed pull")
+	pullCommand.Flags().Duration("retry-backoff", time.Second, "Initial delay between the retries, doubled for each retry")
+	pullCommand.Flags().Duration("retry-backoff-max", 30*time.Second, "Maximum delay between the retries")
+	pullCommand.Flags().Duration("retry-max-duration", 0, "Maximum total duration of the retries, no retry is started beyond it (0 for no limit)")
 
 	pullCommand.Flags().String("ipfs-address", "", "multiaddr of IPFS API (default uses $IPFS_PATH env variable if defined or local directory ~/.ipfs)")
 
@@ -90,6 +109,15 @@ func processPullCommandFlags(cmd *cobra.Command) (types.ImagePullOptions, error)
 	if err != nil {
 		return types.ImagePullOptions{}, err
 	}
+	progressOutput, err := cmd.Flags().GetString("progress-output")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+	switch progressOutput {
+	case pull.ProgressFormatAuto, pull.ProgressFormatJSON:
+	default:
+		return types.ImagePullOptions{}, fmt.Errorf("invalid progress-output %q (supported values: %q, %q)", progressOutput, pull.ProgressFormatAuto, pull.ProgressFormatJSON)
+	}
 	ipfsAddressStr, err := cmd.Flags().GetString("ipfs-address")
 	if err != nil {
 		return types.ImagePullOptions{}, err
@@ -100,23 +128,65 @@ func processPullCommandFlags(cmd *cobra.Command) (types.ImagePullOptions, error)
 		return types.ImagePullOptions{}, err
 	}
 
+	labels, err := cmd.Flags().GetStringArray("label")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+
+	storeMetadata, err := cmd.Flags().GetBool("store-metadata")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+	retry, err := cmd.Flags().GetInt("retry")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+	retryBackoff, err := cmd.Flags().GetDuration("retry-backoff")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+	retryBackoffMax, err := cmd.Flags().GetDuration("retry-backoff-max")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+	retryMaxDuration, err := cmd.Flags().GetDuration("retry-max-duration")
+	if err != nil {
+		return types.ImagePullOptions{}, err
+	}
+	if retry < 0 {
+		return types.ImagePullOptions{}, fmt.Errorf("invalid retry %d (must be 0 or greater)", retry)
+	}
+	if retryBackoff <= 0 || retryBackoffMax < retryBackoff {
+		return types.ImagePullOptions{}, fmt.Errorf("invalid retry-backoff %s and retry-backoff-max %s (must be 0 < retry-backoff <= retry-backoff-max)", retryBackoff, retryBackoffMax)
+	}
+	if retryMaxDuration < 0 {
+		return types.ImagePullOptions{}, fmt.Errorf("invalid retry-max-duration %s (must be 0 or greater)", retryMaxDuration)
+	}
+
 	verifyOptions, err := processImageVerifyOptions(cmd)
 	if err != nil {
 		return types.ImagePullOptions{}, err
 	}
 	return types.ImagePullOptions{
-		GOptions:      globalOptions,
-		VerifyOptions: verifyOptions,
-		AllPlatforms:  allPlatforms,
-		Platform:      platform,
-		Unpack:        unpackStr,
-		Quiet:         quiet,
-		IPFSAddress:   ipfsAddressStr,
+		GOptions:       globalOptions,
+		VerifyOptions:  verifyOptions,
+		AllPlatforms:   allPlatforms,
+		Platform:       platform,
+		Unpack:         unpackStr,
+		Quiet:          quiet,
+		ProgressOutput: progressOutput,
+		IPFSAddress:    ipfsAddressStr,
 		RFlags: types.RemoteSnapshotterFlags{
 			SociIndexDigest: sociIndexDigest,
 		},
-		Stdout: cmd.OutOrStdout(),
-		Stderr: cmd.OutOrStderr(),
+		Labels:           labels,
+		StoreMetadata:    storeMetadata,
+		Retry:            retry,
+		RetryBackoff:     retryBackoff,
+		RetryBackoffMax:  retryBackoffMax,
+		RetryMaxDuration: retryMaxDuration,
+		Stdout:           cmd.OutOrStdout(),
+		Stderr:           cmd.OutOrStderr(),
 	}, nil
 }
 
@@ -17,6 +17,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -197,3 +198,115 @@ func TestPullSoci(t *testing.T) {
 		})
 	}
 }
+
+func TestImagePullProgressOutputJSON(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+
+	out := base.Cmd("pull", "--progress-output", "json", testutil.CommonImage).Out()
+	var layerDone bool
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var ev struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		}
+		assert.NilError(t, json.Unmarshal([]byte(line), &ev), "line %q", line)
+		if strings.HasPrefix(ev.ID, "sha256:") && ev.Status == "done" {
+			layerDone = true
+		}
+	}
+	assert.Assert(t, layerDone, "no blob reported as done: %q", out)
+
+	base.Cmd("pull", "--progress-output", "json", "--quiet", testutil.CommonImage).AssertOutExactly("")
+	base.Cmd("pull", "--progress-output", "xml", testutil.CommonImage).AssertFail()
+}
+
+func TestImagePullLabel(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+	defer base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+
+	base.Cmd("pull", "--label", "env=prod", "--label", "nerdctl.test/provenance=ci", testutil.CommonImage).AssertOK()
+	out := base.Cmd("image", "inspect", "--mode=native", "--format={{json .Image.Labels}}", testutil.CommonImage).Out()
+	var labels map[string]string
+	assert.NilError(t, json.Unmarshal([]byte(out), &labels), out)
+	assert.Equal(t, labels["env"], "prod")
+	assert.Equal(t, labels["nerdctl.test/provenance"], "ci")
+
+	base.Cmd("images", "--quiet", "--filter", "label=env=prod").AssertOutContains(
+		strings.TrimSpace(base.Cmd("images", "--quiet", testutil.CommonImage).Out()))
+}
+
+func TestImagePullStoreMetadata(t *testing.T) {
+	testutil.DockerIncompatible(t) // Docker lacks --store-metadata
+	base := testutil.NewBase(t)
+	reg := testregistry.NewPlainHTTP(base, 5000)
+	defer reg.Cleanup()
+	// localhost is accessed over plain HTTP without --insecure-registry
+	testImageRef := fmt.Sprintf("127.0.0.1:%d/%s:latest", reg.ListenPort, testutil.Identifier(t))
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, testImageRef).AssertOK()
+	base.Cmd("push", testImageRef).AssertOK()
+	base.Cmd("rmi", "-f", testImageRef).AssertOK()
+	defer base.Cmd("rmi", "-f", testImageRef).Run()
+
+	base.Cmd("pull", "--store-metadata", "--label", "nerdctl/registry.cache-control=overridden", testImageRef).AssertOK()
+	out := base.Cmd("image", "inspect", "--mode=native", "--format={{json .Image.Labels}}", testImageRef).Out()
+	var labels map[string]string
+	assert.NilError(t, json.Unmarshal([]byte(out), &labels), out)
+	// the distribution registry sends the digest of the manifest as the ETag
+	digest := strings.TrimSpace(base.Cmd("image", "inspect", "--mode=native", "--format={{.Image.Target.Digest}}", testImageRef).Out())
+	assert.Equal(t, labels["nerdctl/registry.etag"], fmt.Sprintf("%q", digest))
+	assert.Equal(t, labels["nerdctl/registry.cache-control"], "overridden")
+
+	// the stored ETag makes the next pull conditional
+	base.Cmd("pull", "--store-metadata", testImageRef).AssertCombinedOutContains("is up to date")
+	// a pull without the stored ETag is not skipped
+	base.Cmd("rmi", "-f", testImageRef).AssertOK()
+	res := base.Cmd("pull", "--store-metadata", testImageRef).Run()
+	assert.Equal(t, res.ExitCode, 0, res.Combined())
+	assert.Assert(t, !strings.Contains(res.Combined(), "is up to date"), res.Combined())
+}
+
+func TestImagePullRetry(t *testing.T) {
+	testutil.DockerIncompatible(t) // Docker lacks --retry
+	base := testutil.NewBase(t)
+	// nothing listens on the port, so that every attempt fails
+	ref := "127.0.0.1:1/" + testutil.Identifier(t) + ":latest"
+	res := base.Cmd("--insecure-registry", "pull", "--retry", "2", "--retry-backoff", "10ms", "--retry-backoff-max", "20ms", ref).Run()
+	assert.Assert(t, res.ExitCode != 0)
+	assert.Assert(t, strings.Contains(res.Stderr(), "attempt 2/3"), res.Stderr())
+	assert.Assert(t, !strings.Contains(res.Stderr(), "attempt 3/3"), res.Stderr())
+
+	// the second retry would start about 3s after the first attempt
+	res = base.Cmd("--insecure-registry", "pull", "--retry", "5", "--retry-backoff", "1s", "--retry-max-duration", "2s", ref).Run()
+	assert.Assert(t, res.ExitCode != 0)
+	assert.Assert(t, strings.Contains(res.Stderr(), "attempt 1/6"), res.Stderr())
+	assert.Assert(t, !strings.Contains(res.Stderr(), "attempt 2/6"), res.Stderr())
+	assert.Assert(t, strings.Contains(res.Stderr(), "giving up after 2 attempts"), res.Stderr())
+
+	base.Cmd("pull", "--retry", "-1", ref).AssertFail()
+	base.Cmd("pull", "--retry", "1", "--retry-max-duration", "-1s", ref).AssertFail()
+	base.Cmd("pull", "--retry", "1", "--retry-backoff", "2s", "--retry-backoff-max", "1s", ref).AssertFail()
+}
+
+func TestImagePullUnpackFalse(t *testing.T) {
+	testutil.DockerIncompatible(t) // Docker lacks --unpack
+	base := testutil.NewBase(t)
+	base.Cmd("rmi", "-f", testutil.CommonImage).Run()
+
+	base.Cmd("pull", "--unpack=false", testutil.CommonImage).AssertOK()
+	// the blobs are in the content store, but no snapshot has been unpacked
+	base.Cmd("images", "--format", "{{.Size}}", testutil.CommonImage).AssertOutExactly("0.0 B\n")
+
+	// the image is unpacked on the first use
+	base.Cmd("run", "--rm", testutil.CommonImage, "true").AssertOK()
+	base.Cmd("images", "--format", "{{.Size}}", testutil.CommonImage).AssertOutWithFunc(func(stdout string) error {
+		if stdout == "0.0 B\n" {
+			return fmt.Errorf("expected the image to be unpacked by run, got size %q", stdout)
+		}
+		return nil
+	})
+}
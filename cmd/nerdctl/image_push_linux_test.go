@@ -175,6 +175,68 @@ func TestPushNonDistributableArtifacts(t *testing.T) {
 	assert.Equal(t, resp.StatusCode, http.StatusOK, "non-distributable blob should be available")
 }
 
+func TestPushSinglePlatform(t *testing.T) {
+	testutil.RequiresBuild(t)
+	testutil.DockerIncompatible(t)
+
+	base := testutil.NewBase(t)
+	reg := testregistry.NewPlainHTTP(base, 5000)
+	defer reg.Cleanup()
+
+	base.Cmd("pull", "--platform=amd64,arm64", testutil.CommonImage).AssertOK()
+	testImageRef := fmt.Sprintf("%s:%d/%s:%s",
+		reg.IP.String(), reg.ListenPort, testutil.Identifier(t), strings.Split(testutil.CommonImage, ":")[1])
+	base.Cmd("tag", testutil.CommonImage, testImageRef).AssertOK()
+	defer base.Cmd("rmi", testImageRef).Run()
+
+	base.Cmd("--insecure-registry", "push", "--platform=linux/arm64", testImageRef).AssertOK()
+
+	mediaType := headManifestMediaType(t, fmt.Sprintf("http://%s:%d/v2/%s/manifests/%s",
+		reg.IP.String(), reg.ListenPort, testutil.Identifier(t), strings.Split(testutil.CommonImage, ":")[1]))
+	assert.Assert(t, strings.Contains(mediaType, "manifest.v1") || strings.Contains(mediaType, "manifest.v2"), mediaType)
+}
+
+func TestPushMultiPlatformTags(t *testing.T) {
+	testutil.DockerIncompatible(t)
+
+	base := testutil.NewBase(t)
+	reg := testregistry.NewPlainHTTP(base, 5000)
+	defer reg.Cleanup()
+
+	base.Cmd("pull", "--platform=amd64,arm64", testutil.CommonImage).AssertOK()
+	tag := strings.Split(testutil.CommonImage, ":")[1]
+	testImageRef := fmt.Sprintf("%s:%d/%s:%s", reg.IP.String(), reg.ListenPort, testutil.Identifier(t), tag)
+	base.Cmd("tag", testutil.CommonImage, testImageRef).AssertOK()
+	defer base.Cmd("rmi", testImageRef).Run()
+
+	base.Cmd("--insecure-registry", "push", "--platform=linux/amd64,linux/arm64", testImageRef).AssertOK()
+
+	manifestURL := fmt.Sprintf("http://%s:%d/v2/%s/manifests/", reg.IP.String(), reg.ListenPort, testutil.Identifier(t))
+	mediaType := headManifestMediaType(t, manifestURL+tag)
+	assert.Assert(t, strings.Contains(mediaType, "index") || strings.Contains(mediaType, "manifest.list"), mediaType)
+	for _, suffix := range []string{"amd64", "arm64"} {
+		mediaType := headManifestMediaType(t, manifestURL+tag+"-"+suffix)
+		assert.Assert(t, strings.Contains(mediaType, "manifest.v1") || strings.Contains(mediaType, "manifest.v2"), mediaType)
+	}
+}
+
+// headManifestMediaType returns the media type of the manifest at the URL of the registry API.
+func headManifestMediaType(t *testing.T, manifestURL string) string {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	assert.NilError(t, err)
+	req.Header.Set("Accept", strings.Join([]string{
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+	}, ","))
+	resp, err := http.DefaultClient.Do(req)
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK, manifestURL)
+	return resp.Header.Get("Content-Type")
+}
+
 func TestPushSoci(t *testing.T) {
 	testutil.DockerIncompatible(t)
 	base := testutil.NewBase(t)
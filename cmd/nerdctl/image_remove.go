@@ -36,6 +36,7 @@ func newRmiCommand() *cobra.Command {
 	rmiCommand.Flags().BoolP("force", "f", false, "Force removal of the image")
 	// Alias `-a` is reserved for `--all`. Should be compatible with `podman rmi --all`.
 	rmiCommand.Flags().Bool("async", false, "Asynchronous mode")
+	rmiCommand.Flags().Bool("no-prune", false, "Do not delete untagged parents")
 	return rmiCommand
 }
 
@@ -54,11 +55,17 @@ func processImageRemoveOptions(cmd *cobra.Command) (types.ImageRemoveOptions, er
 		return types.ImageRemoveOptions{}, err
 	}
 
+	noPrune, err := cmd.Flags().GetBool("no-prune")
+	if err != nil {
+		return types.ImageRemoveOptions{}, err
+	}
+
 	return types.ImageRemoveOptions{
 		Stdout:   cmd.OutOrStdout(),
 		GOptions: globalOptions,
 		Force:    force,
 		Async:    async,
+		NoPrune:  noPrune,
 	}, nil
 }
 
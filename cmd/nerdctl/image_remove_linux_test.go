@@ -17,9 +17,12 @@
 package main
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"gotest.tools/v3/assert"
 )
 
 func TestRemoveImage(t *testing.T) {
@@ -105,3 +108,47 @@ func TestRemoveImageWithCreatedContainer(t *testing.T) {
 	base.Cmd("rmi", "-f", testutil.NginxAlpineImage).AssertOK()
 	base.Cmd("images").AssertNoOut(testutil.ImageRepo(testutil.NginxAlpineImage))
 }
+
+func TestRemoveImageNoPrune(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	testutil.RequiresBuild(t)
+	base := testutil.NewBase(t)
+	defer base.Cmd("builder", "prune").Run()
+	parentName := testutil.Identifier(t) + "-parent"
+	childName := testutil.Identifier(t) + "-child"
+
+	base.Cmd("build", "-t", parentName, createBuildContext(t, fmt.Sprintf(`FROM %s
+RUN echo parent > /parent
+`, testutil.CommonImage))).AssertOK()
+	defer base.Cmd("rmi", "-f", parentName).Run()
+	parentDigest := strings.TrimSpace(base.Cmd("images", "--format", "{{.Digest}}", parentName).Out())
+
+	// createDanglingParent builds the child on top of the parent, and then leaves the parent untagged.
+	createDanglingParent := func() {
+		base.Cmd("tag", parentName, parentName+"@"+parentDigest).AssertOK()
+		base.Cmd("build", "-t", childName, createBuildContext(t, fmt.Sprintf(`FROM %s
+RUN echo child > /child
+`, parentName))).AssertOK()
+		base.Cmd("rmi", parentName).AssertOK()
+	}
+	dangling := func() string {
+		return base.Cmd("images", "--filter", "dangling=true", "--format", "{{.Digest}}").Out()
+	}
+	defer base.Cmd("rmi", "-f", parentName+"@"+parentDigest).Run()
+	defer base.Cmd("rmi", "-f", childName).Run()
+
+	createDanglingParent()
+	assert.Assert(t, strings.Contains(dangling(), parentDigest))
+	base.Cmd("rmi", "--no-prune", childName).AssertOK()
+	assert.Assert(t, strings.Contains(dangling(), parentDigest), "the parent should remain with --no-prune")
+
+	// without --no-prune, the dangling parent is removed along with the last record of the child
+	base.Cmd("tag", parentName+"@"+parentDigest, parentName).AssertOK()
+	createDanglingParent()
+	base.Cmd("tag", childName, childName+"-alias").AssertOK()
+	defer base.Cmd("rmi", "-f", childName+"-alias").Run()
+	base.Cmd("rmi", childName).AssertOutNotContains("Deleted: " + parentDigest)
+	assert.Assert(t, strings.Contains(dangling(), parentDigest), "the parent should remain while another tag refers to the child")
+	base.Cmd("rmi", childName+"-alias").AssertOutContains("Deleted: " + parentDigest)
+	assert.Assert(t, !strings.Contains(dangling(), parentDigest), "the parent should be removed without --no-prune")
+}
@@ -39,6 +39,7 @@ func newSaveCommand() *cobra.Command {
 		SilenceErrors:     true,
 	}
 	saveCommand.Flags().StringP("output", "o", "", "Write to a file, instead of STDOUT")
+	saveCommand.Flags().Bool("compress", false, "Compress the archive with gzip")
 
 	// #region platform flags
 	// platform is defined as StringSlice, not StringArray, to allow specifying "--platform=amd64,arm64"
@@ -65,10 +66,16 @@ func processImageSaveOptions(cmd *cobra.Command) (types.ImageSaveOptions, error)
 		return types.ImageSaveOptions{}, err
 	}
 
+	compress, err := cmd.Flags().GetBool("compress")
+	if err != nil {
+		return types.ImageSaveOptions{}, err
+	}
+
 	return types.ImageSaveOptions{
 		GOptions:     globalOptions,
 		AllPlatforms: allPlatforms,
 		Platform:     platform,
+		Compress:     compress,
 	}, err
 }
 
@@ -79,20 +86,28 @@ func saveAction(cmd *cobra.Command, args []string) error {
 	}
 
 	output := cmd.OutOrStdout()
+	// The progress must not be mixed into the archive streamed to stdout
+	progressOutput := cmd.ErrOrStderr()
+	var f *os.File
 	outputPath, err := cmd.Flags().GetString("output")
 	if err != nil {
 		return err
 	} else if outputPath != "" {
-		f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY, 0644)
+		f, err = os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 		if err != nil {
 			return err
 		}
-		output = f
 		defer f.Close()
+		output = f
+		progressOutput = cmd.OutOrStdout()
 	} else if out, ok := output.(*os.File); ok && isatty.IsTerminal(out.Fd()) {
 		return fmt.Errorf("cowardly refusing to save to a terminal. Use the -o flag or redirect")
 	}
 	options.Stdout = output
+	// The progress is only displayed on a terminal, so that it does not pollute logs
+	if out, ok := progressOutput.(*os.File); ok && isatty.IsTerminal(out.Fd()) {
+		options.ProgressOutput = progressOutput
+	}
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
 	if err != nil {
@@ -100,8 +115,15 @@ func saveAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	if err = image.Save(ctx, client, args, options); err != nil && outputPath != "" {
-		os.Remove(outputPath)
+	err = image.Save(ctx, client, args, options)
+	if f != nil {
+		// An error on closing the file means that the archive may be truncated
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(outputPath)
+		}
 	}
 	return err
 }
@@ -17,6 +17,7 @@
 package main
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -100,3 +101,57 @@ func extractTarFile(dirPath, tarFilePath string) error {
 	}
 	return nil
 }
+
+func TestSaveToStdoutPipeRoundTrip(t *testing.T) {
+	base := testutil.NewBase(t)
+	img := testutil.Identifier(t) + "image"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, img).AssertOK()
+	defer base.Cmd("rmi", "-f", img).Run()
+
+	saveCmd := strings.Join(base.Cmd("save", img).Command, " ")
+	loadCmd := strings.Join(base.Cmd("load").Command, " ")
+	archiveTarPath := filepath.Join(t.TempDir(), "piped.tar")
+	combined, err := exec.Command("sh", "-euc", fmt.Sprintf("%s | tee %s | %s", saveCmd, archiveTarPath, loadCmd)).CombinedOutput()
+	assert.NilError(t, err, "combined output: %s", string(combined))
+	assert.Assert(t, strings.Contains(string(combined), fmt.Sprintf("Loaded image: %s:latest", img)), string(combined))
+	base.Cmd("run", "--rm", img, "echo", "foo").AssertOutExactly("foo\n")
+
+	// the archive streamed to stdout is not polluted by the progress
+	newPath := filepath.Join(t.TempDir(), "new.tar")
+	base.Cmd("save", "-o", newPath, img).AssertOutExactly("")
+	piped, err := os.ReadFile(archiveTarPath)
+	assert.NilError(t, err)
+	saved, err := os.ReadFile(newPath)
+	assert.NilError(t, err)
+	assert.Equal(t, len(saved), len(piped))
+
+	// saving to an existing larger file must not leave its trailing bytes
+	existingPath := filepath.Join(t.TempDir(), "existing.tar")
+	assert.NilError(t, os.WriteFile(existingPath, append(saved, []byte("garbage")...), 0644))
+	base.Cmd("save", "-o", existingPath, img).AssertOK()
+	resaved, err := os.ReadFile(existingPath)
+	assert.NilError(t, err)
+	assert.Equal(t, len(saved), len(resaved))
+}
+
+func TestSaveCompressRoundTrip(t *testing.T) {
+	base := testutil.NewBase(t)
+	img := testutil.Identifier(t) + "image"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("tag", testutil.CommonImage, img).AssertOK()
+	defer base.Cmd("rmi", "-f", img).Run()
+
+	archivePath := filepath.Join(t.TempDir(), "a.tar.gz")
+	base.Cmd("save", "--compress", "-o", archivePath, img).AssertOK()
+	f, err := os.Open(archivePath)
+	assert.NilError(t, err)
+	defer f.Close()
+	// the archive is a valid gzip stream
+	_, err = gzip.NewReader(f)
+	assert.NilError(t, err)
+
+	base.Cmd("rmi", "-f", img).AssertOK()
+	base.Cmd("load", "-i", archivePath).AssertOutContains(fmt.Sprintf("Loaded image: %s:latest", img))
+	base.Cmd("run", "--rm", img, "echo", "foo").AssertOutExactly("foo\n")
+}
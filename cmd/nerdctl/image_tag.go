@@ -26,14 +26,15 @@ import (
 
 func newTagCommand() *cobra.Command {
 	var tagCommand = &cobra.Command{
-		Use:               "tag [flags] SOURCE_IMAGE[:TAG] TARGET_IMAGE[:TAG]",
+		Use:               "tag [flags] SOURCE_IMAGE[:TAG] TARGET_IMAGE[:TAG] [TARGET_IMAGE[:TAG]...]",
 		Short:             "Create a tag TARGET_IMAGE that refers to SOURCE_IMAGE",
-		Args:              IsExactArgs(2),
+		Args:              cobra.MinimumNArgs(2),
 		RunE:              tagAction,
 		ValidArgsFunction: tagShellComplete,
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
+	tagCommand.Flags().BoolP("force", "f", false, "Move the tag even if it already refers to a different image")
 	return tagCommand
 }
 
@@ -43,10 +44,17 @@ func tagAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return err
+	}
 	options := types.ImageTagOptions{
+		Stdout:   cmd.OutOrStdout(),
 		GOptions: globalOptions,
 		Source:   args[0],
 		Target:   args[1],
+		Targets:  args[2:],
+		Force:    force,
 	}
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
@@ -0,0 +1,111 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+)
+
+func TestTagForce(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	tagName := testutil.Identifier(t) + ":test"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("pull", testutil.NginxAlpineImage).AssertOK()
+	defer base.Cmd("rmi", tagName).Run()
+
+	// create a new tag
+	base.Cmd("tag", testutil.CommonImage, tagName).AssertOutContains("Created tag")
+	base.Cmd("tag", testutil.CommonImage, tagName).AssertOutContains("is unchanged")
+
+	// refuse to move the tag without --force
+	moveCmd := base.Cmd("tag", testutil.NginxAlpineImage, tagName)
+	moveCmd.AssertFail()
+	moveCmd.AssertErrContains("use --force to move it")
+	commonID := base.Cmd("images", "--quiet", "--no-trunc", testutil.CommonImage).OutLines()[0]
+	base.Cmd("images", "--quiet", "--no-trunc", tagName).AssertOutExactly(commonID + "\n")
+
+	// move the tag with --force
+	base.Cmd("tag", "--force", testutil.NginxAlpineImage, tagName).AssertOutContains("Moved tag")
+	nginxID := base.Cmd("images", "--quiet", "--no-trunc", testutil.NginxAlpineImage).OutLines()[0]
+	base.Cmd("images", "--quiet", "--no-trunc", tagName).AssertOutExactly(nginxID + "\n")
+}
+
+func TestTagMultipleTargets(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	tagName := testutil.Identifier(t)
+	targets := []string{tagName + ":1", tagName + ":2", tagName + ":3"}
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	base.Cmd("pull", testutil.NginxAlpineImage).AssertOK()
+	for _, target := range targets {
+		defer base.Cmd("rmi", target).Run()
+	}
+
+	base.Cmd(append([]string{"tag", testutil.CommonImage}, targets...)...).AssertOK()
+	commonID := base.Cmd("images", "--quiet", "--no-trunc", testutil.CommonImage).OutLines()[0]
+	for _, target := range targets {
+		base.Cmd("images", "--quiet", "--no-trunc", target).AssertOutExactly(commonID + "\n")
+	}
+
+	// a failure on a target does not abort the remaining targets
+	cmd := base.Cmd("tag", testutil.NginxAlpineImage, targets[1], targets[2]+"-new")
+	defer base.Cmd("rmi", targets[2]+"-new").Run()
+	cmd.AssertFail()
+	cmd.AssertErrContains("use --force to move it")
+	nginxID := base.Cmd("images", "--quiet", "--no-trunc", testutil.NginxAlpineImage).OutLines()[0]
+	base.Cmd("images", "--quiet", "--no-trunc", targets[1]).AssertOutExactly(commonID + "\n")
+	base.Cmd("images", "--quiet", "--no-trunc", targets[2]+"-new").AssertOutExactly(nginxID + "\n")
+}
+
+func TestTagConcurrent(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	tagName := testutil.Identifier(t) + ":test"
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+	defer base.Cmd("rmi", tagName).Run()
+
+	// none of the concurrent commands tagging the same target fails on the conflict with the others
+	const n = 10
+	results := make([]*icmd.Result, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = base.Cmd("tag", "--force", testutil.CommonImage, tagName).Run()
+		}(i)
+	}
+	wg.Wait()
+	var created int
+	for _, res := range results {
+		assert.NilError(t, res.Error, res.Combined())
+		if strings.Contains(res.Stdout(), "Created tag") {
+			created++
+		}
+	}
+	assert.Equal(t, created, 1)
+	commonID := base.Cmd("images", "--quiet", "--no-trunc", testutil.CommonImage).OutLines()[0]
+	base.Cmd("images", "--quiet", "--no-trunc", tagName).AssertOutExactly(commonID + "\n")
+}
@@ -0,0 +1,79 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/spf13/cobra"
+)
+
+func newImageVerifyCommand() *cobra.Command {
+	imageVerifyCommand := &cobra.Command{
+		Use:               "verify [flags] IMAGE [IMAGE...]",
+		Short:             "Verify the signatures of local images against a trust policy",
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              imageVerifyAction,
+		ValidArgsFunction: imageVerifyShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	imageVerifyCommand.Flags().String("policy", "", "Path of the trust policy file (JSON), e.g., /etc/nerdctl/trust-policy.json")
+	return imageVerifyCommand
+}
+
+func processImageVerifyPolicyOptions(cmd *cobra.Command) (types.ImageVerifyPolicyOptions, error) {
+	globalOptions, err := processRootCmdFlags(cmd)
+	if err != nil {
+		return types.ImageVerifyPolicyOptions{}, err
+	}
+	policy, err := cmd.Flags().GetString("policy")
+	if err != nil {
+		return types.ImageVerifyPolicyOptions{}, err
+	}
+	if policy == "" {
+		return types.ImageVerifyPolicyOptions{}, errors.New("--policy is required")
+	}
+	return types.ImageVerifyPolicyOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Policy:   policy,
+	}, nil
+}
+
+func imageVerifyAction(cmd *cobra.Command, args []string) error {
+	options, err := processImageVerifyPolicyOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return image.Verify(ctx, client, args, options)
+}
+
+func imageVerifyShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// show image names
+	return shellCompleteImageNames(cmd)
+}
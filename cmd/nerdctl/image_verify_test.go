@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func TestImageVerifyPolicy(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	base.Cmd("pull", testutil.CommonImage).AssertOK()
+
+	policy := filepath.Join(t.TempDir(), "trust-policy.json")
+	// only the provider "none", as the other providers need the signatures in a registry
+	err := os.WriteFile(policy, []byte(fmt.Sprintf(`{"rules": [{"scope": %q, "provider": "none"}]}`,
+		testutil.ImageRepo(testutil.CommonImage))), 0600)
+	assert.NilError(t, err)
+	base.Cmd("image", "verify", "--policy", policy, testutil.CommonImage).AssertOutContains("VERIFIED")
+
+	err = os.WriteFile(policy, []byte(`{"rules": [{"scope": "example.com", "provider": "none"}]}`), 0600)
+	assert.NilError(t, err)
+	res := base.Cmd("image", "verify", "--policy", policy, testutil.CommonImage).Run()
+	assert.Assert(t, res.ExitCode != 0, res.Combined())
+	assert.Assert(t, strings.Contains(res.Stdout(), "FAILED"), res.Combined())
+	assert.Assert(t, strings.Contains(res.Stdout(), "no rule of the trust policy"), res.Combined())
+
+	base.Cmd("image", "verify", testutil.CommonImage).AssertFail()
+}
@@ -136,7 +136,7 @@ func xmain() error {
 	return app.Execute()
 }
 
-func initRootCmdFlags(rootCmd *cobra.Command, tomlPath string) (*pflag.FlagSet, error) {
+func loadConfig(tomlPath string) (*config.Config, error) {
 	cfg := config.New()
 	if r, err := os.Open(tomlPath); err == nil {
 		log.L.Debugf("Loading config from %q", tomlPath)
@@ -152,10 +152,22 @@ func initRootCmdFlags(rootCmd *cobra.Command, tomlPath string) (*pflag.FlagSet,
 			return nil, err
 		}
 	}
+	return cfg, nil
+}
+
+func initRootCmdFlags(rootCmd *cobra.Command, cfg *config.Config) *pflag.FlagSet {
 	aliasToBeInherited := pflag.NewFlagSet(rootCmd.Name(), pflag.ExitOnError)
 
 	rootCmd.PersistentFlags().Bool("debug", cfg.Debug, "debug mode")
 	rootCmd.PersistentFlags().Bool("debug-full", cfg.DebugFull, "debug mode (with full output)")
+	rootCmd.PersistentFlags().String("log-level", cfg.LogLevel, `Set the logging level ("trace"|"debug"|"info"|"warn"|"error"|"fatal"|"panic")`)
+	rootCmd.RegisterFlagCompletionFunc("log-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.PersistentFlags().String("log-format", cfg.LogFormat, `Set the logging format ("text"|"json")`)
+	rootCmd.RegisterFlagCompletionFunc("log-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	// -a is aliases (conflicts with nerdctl images -a)
 	AddPersistentStringFlag(rootCmd, "address", []string{"a", "H"}, nil, []string{"host"}, aliasToBeInherited, cfg.Address, "CONTAINERD_ADDRESS", `containerd address, optionally with "unix://" prefix`)
 	// -n is aliases (conflicts with nerdctl logs -n)
@@ -175,7 +187,7 @@ func initRootCmdFlags(rootCmd *cobra.Command, tomlPath string) (*pflag.FlagSet,
 	// Experimental enable experimental feature, see in https://github.com/containerd/nerdctl/blob/main/docs/experimental.md
 	AddPersistentBoolFlag(rootCmd, "experimental", nil, nil, cfg.Experimental, "NERDCTL_EXPERIMENTAL", "Control experimental: https://github.com/containerd/nerdctl/blob/main/docs/experimental.md")
 	AddPersistentStringFlag(rootCmd, "host-gateway-ip", nil, nil, nil, aliasToBeInherited, cfg.HostGatewayIP, "NERDCTL_HOST_GATEWAY_IP", "IP address that the special 'host-gateway' string in --add-host resolves to. Defaults to the IP address of the host. It has no effect without setting --add-host")
-	return aliasToBeInherited, nil
+	return aliasToBeInherited
 }
 
 func newApp() (*cobra.Command, error) {
@@ -201,16 +213,28 @@ Config file ($NERDCTL_TOML): %s
 	}
 
 	rootCmd.SetUsageFunc(usage)
-	aliasToBeInherited, err := initRootCmdFlags(rootCmd, tomlPath)
+	cfg, err := loadConfig(tomlPath)
 	if err != nil {
 		return nil, err
 	}
+	aliasToBeInherited := initRootCmdFlags(rootCmd, cfg)
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		globalOptions, err := processRootCmdFlags(cmd)
 		if err != nil {
 			return err
 		}
+		switch globalOptions.LogFormat {
+		case string(log.TextFormat), string(log.JSONFormat):
+			if err := log.SetFormat(log.OutputFormat(globalOptions.LogFormat)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid log-format %q (supported values: \"text\", \"json\")", globalOptions.LogFormat)
+		}
+		if err := log.SetLevel(globalOptions.LogLevel); err != nil {
+			return fmt.Errorf("invalid log-level %q: %w", globalOptions.LogLevel, err)
+		}
 		debug := globalOptions.DebugFull
 		if !debug {
 			debug = globalOptions.Debug
@@ -267,7 +291,7 @@ Config file ($NERDCTL_TOML): %s
 		newBuildCommand(),
 
 		// #region Image management
-		newImagesCommand(),
+		newImagesCommand(cfg.Images),
 		newPullCommand(),
 		newPushCommand(),
 		newLoadCommand(),
@@ -292,11 +316,12 @@ Config file ($NERDCTL_TOML): %s
 
 		// #region Management
 		newContainerCommand(),
-		newImageCommand(),
+		newImageCommand(cfg.Images),
 		newNetworkCommand(),
 		newVolumeCommand(),
 		newSystemCommand(),
 		newNamespaceCommand(),
+		newManifestCommand(),
 		newBuilderCommand(),
 		// #endregion
 
@@ -88,3 +88,14 @@ version = 2
 	base.Env = append(base.Env, "NERDCTL_TOML="+tomlPath)
 	base.Cmd("info").AssertFail()
 }
+
+func TestLogFormatAndLevel(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+
+	// --insecure-registry prints a warning "skipping verifying HTTPS certs"
+	base.Cmd("--log-format=json", "pull", "--insecure-registry", testutil.CommonImage).AssertErrContains(`"level":"warning"`)
+	base.Cmd("--log-level=error", "pull", "--insecure-registry", testutil.CommonImage).AssertErrNotContains("skipping verifying HTTPS certs")
+	base.Cmd("--log-format=xml", "info").AssertFail()
+	base.Cmd("--log-level=foo", "info").AssertFail()
+}
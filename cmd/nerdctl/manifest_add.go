@@ -0,0 +1,55 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/manifest"
+	"github.com/spf13/cobra"
+)
+
+func newManifestAddCommand() *cobra.Command {
+	manifestAddCommand := &cobra.Command{
+		Use:               "add [flags] LIST IMAGE [IMAGE...]",
+		Short:             "Add the manifests of the images to a manifest list",
+		Long:              "The manifest of a platform replaces the one of the same platform already in the list.",
+		Args:              cobra.MinimumNArgs(2),
+		RunE:              manifestAddAction,
+		ValidArgsFunction: manifestShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return manifestAddCommand
+}
+
+func manifestAddAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := processRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return manifest.Add(ctx, client, args[0], args[1:], types.ManifestAddOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	})
+}
@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/testregistry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestManifest(t *testing.T) {
+	testutil.RequiresBuild(t)
+	testutil.DockerIncompatible(t)
+
+	base := testutil.NewBase(t)
+	reg := testregistry.NewPlainHTTP(base, 5000)
+	defer reg.Cleanup()
+
+	base.Cmd("pull", "--platform=amd64,arm64", testutil.CommonImage).AssertOK()
+	list := fmt.Sprintf("%s:%d/%s:latest", reg.IP.String(), reg.ListenPort, testutil.Identifier(t))
+	defer base.Cmd("manifest", "rm", list).Run()
+
+	inspect := func() ocispec.Index {
+		var index ocispec.Index
+		out := base.Cmd("manifest", "inspect", list).Out()
+		assert.NilError(t, json.Unmarshal([]byte(out), &index), out)
+		return index
+	}
+
+	// the list is not left behind when the manifests cannot be added
+	base.Cmd("manifest", "create", list, "nonexistent-"+testutil.Identifier(t)).AssertFail()
+	base.Cmd("manifest", "inspect", list).AssertFail()
+
+	base.Cmd("manifest", "create", list).AssertOK()
+	base.Cmd("manifest", "create", list).AssertFail()
+	index := inspect()
+	assert.Equal(t, index.MediaType, ocispec.MediaTypeImageIndex)
+	assert.Equal(t, len(index.Manifests), 0)
+	base.Cmd("manifest", "push", list).AssertFail()
+
+	// adding the same platforms again replaces them
+	for i := 0; i < 2; i++ {
+		base.Cmd("manifest", "add", list, testutil.CommonImage).AssertOK()
+		index = inspect()
+		archs := make(map[string]bool)
+		for _, m := range index.Manifests {
+			assert.Assert(t, m.Platform != nil)
+			archs[m.Platform.Architecture] = true
+		}
+		assert.DeepEqual(t, map[string]bool{"amd64": true, "arm64": true}, archs)
+		assert.Equal(t, len(index.Manifests), 2)
+	}
+
+	base.Cmd("--insecure-registry", "manifest", "push", "--purge", list).AssertOK()
+	base.Cmd("manifest", "inspect", list).AssertFail()
+	base.Cmd("--insecure-registry", "pull", "--platform=arm64", list).AssertOK()
+	defer base.Cmd("rmi", list).Run()
+
+	// only manifest lists can be removed with `manifest rm`
+	base.Cmd("manifest", "rm", list).AssertFail()
+	base.Cmd("manifest", "rm", testutil.CommonImage).AssertFail()
+	assert.Assert(t, strings.Contains(base.Cmd("images", "--names").Out(), list))
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/manifest"
+	"github.com/spf13/cobra"
+)
+
+func newManifestRmCommand() *cobra.Command {
+	manifestRmCommand := &cobra.Command{
+		Use:               "rm [flags] LIST [LIST...]",
+		Aliases:           []string{"remove"},
+		Short:             "Remove one or more manifest lists from the local store",
+		Long:              "The manifests in the lists are not removed, as they belong to the images they were added from.",
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              manifestRmAction,
+		ValidArgsFunction: manifestShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	return manifestRmCommand
+}
+
+func manifestRmAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := processRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return manifest.Remove(ctx, client, args, types.ManifestRemoveOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	})
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -50,6 +51,27 @@ func TestMultiPlatformRun(t *testing.T) {
 	testMultiPlatformRun(base, testutil.AlpineImage)
 }
 
+func TestMultiPlatformRunNonIndexedImage(t *testing.T) {
+	testutil.DockerIncompatible(t)
+	base := testutil.NewBase(t)
+	tID := testutil.Identifier(t)
+	hostPlatform := "linux/" + runtime.GOARCH
+	otherPlatform := "linux/s390x"
+	if runtime.GOARCH == "s390x" {
+		otherPlatform = "linux/amd64"
+	}
+
+	// create an image that refers to the manifest of the host platform directly, not to an index
+	imageName := tID + ":single"
+	base.Cmd("pull", testutil.AlpineImage).AssertOK()
+	base.Cmd("image", "convert", "--oci", "--platform", hostPlatform, testutil.AlpineImage, imageName).AssertOK()
+	defer base.Cmd("rmi", imageName).Run()
+
+	base.Cmd("create", "--pull=never", "--platform="+otherPlatform, "--name", tID+"-other", imageName).AssertFail()
+	defer base.Cmd("rm", "-f", tID+"-host").Run()
+	base.Cmd("create", "--pull=never", "--platform="+hostPlatform, "--name", tID+"-host", imageName).AssertOK()
+}
+
 func TestMultiPlatformBuildPush(t *testing.T) {
 	testutil.DockerIncompatible(t) // non-buildx version of `docker build` lacks multi-platform. Also, `docker push` lacks --platform.
 	testutil.RequiresBuild(t)
@@ -61,6 +61,8 @@ type BuilderBuildOptions struct {
 	Platform []string
 	// IidFile write the image ID to the file
 	IidFile string
+	// MetadataFile writes the build metadata (the image digest and name, the build ID, durations, cache hits and misses) to the file
+	MetadataFile string
 	// Label is the metadata for an image
 	Label []string
 	// BuildContext is the build context
@@ -60,6 +60,8 @@ type ContainerCreateOptions struct {
 	// #region for basic flags
 	// Interactive keep STDIN open even if not attached
 	Interactive bool
+	// StdinFile is the file to read the STDIN of the container from, instead of the STDIN of nerdctl (implies Interactive)
+	StdinFile string
 	// TTY specifies whether to allocate a pseudo-TTY for the container
 	TTY bool
 	// Detach runs container in background and print container ID
@@ -74,8 +76,12 @@ type ContainerCreateOptions struct {
 	Pull string
 	// Pid namespace to use
 	Pid string
+	// Userns is the user namespace to use ("host", "auto[:size=N]", or "keep-id")
+	Userns string
 	// StopSignal signal to stop a container, default is SIGTERM
 	StopSignal string
+	// StopSignalChanged specifies whether the stop signal has been explicitly specified
+	StopSignalChanged bool
 	// StopTimeout specifies the timeout (in seconds) to stop a container
 	StopTimeout int
 	// #endregion
@@ -104,6 +110,10 @@ type ContainerCreateOptions struct {
 	CPUQuota int64
 	// CPUPeriod limits the CPU CFS (Completely Fair Scheduler) period
 	CPUPeriod uint64
+	// CPURealtimeRuntime limits the CPU real-time runtime in microseconds
+	CPURealtimeRuntime int64
+	// CPURealtimePeriod limits the CPU real-time period in microseconds
+	CPURealtimePeriod uint64
 	// CPUShares specifies the CPU shares (relative weight)
 	CPUShares uint64
 	// CPUSetCPUs specifies the CPUs in which to allow execution (0-3, 0,1)
@@ -144,6 +154,8 @@ type ContainerCreateOptions struct {
 	CgroupParent string
 	// Device specifies add a host device to the container
 	Device []string
+	// HugetlbLimit specifies the huge page limits, formatted as PAGESIZE:LIMIT (e.g., 2MB:256MB)
+	HugetlbLimit []string
 	// #endregion
 
 	// #region for intel RDT flags
@@ -158,6 +170,8 @@ type ContainerCreateOptions struct {
 	Umask string
 	// GroupAdd specifies additional groups to join
 	GroupAdd []string
+	// GroupFile specifies a group file to merge into the /etc/group of the container
+	GroupFile string
 	// #endregion
 
 	// #region for security flags
@@ -169,6 +183,14 @@ type ContainerCreateOptions struct {
 	CapDrop []string
 	// Privileged gives extended privileges to this container
 	Privileged bool
+	// Mask specifies the paths to add to the masked paths, e.g., /proc/kcore
+	Mask []string
+	// Unmask specifies the paths to remove from the default masked paths ("ALL" for all of them)
+	Unmask []string
+	// ReadonlyPaths specifies the paths to add to the readonly paths, e.g., /proc/sys
+	ReadonlyPaths []string
+	// NoReadonlyPaths clears the default readonly paths ("ALL" is the only supported value)
+	NoReadonlyPaths []string
 	// Systemd
 	Systemd string
 	// #endregion
@@ -178,6 +200,9 @@ type ContainerCreateOptions struct {
 	Runtime string
 	// Sysctl set sysctl options, e.g "net.ipv4.ip_forward=1"
 	Sysctl []string
+	// OCISpecFile is the path of an OCI runtime spec (config.json) to use instead of the spec generated from the image and the flags.
+	// Only the env, volume, and network flags are applied on top of it.
+	OCISpecFile string
 	// #endregion
 
 	// #region for volume flags
@@ -209,6 +234,10 @@ type ContainerCreateOptions struct {
 	Env []string
 	// EnvFile set environment variables from file
 	EnvFile []string
+	// EnvReplaceAll clears the environment variables of the image, so that only Env and EnvFile are set
+	EnvReplaceAll bool
+	// EnvAppend adds Env and EnvFile to the environment variables of the image, without replacing the ones defined by the image
+	EnvAppend bool
 	// #endregion
 
 	// #region for metadata flags
@@ -241,6 +270,8 @@ type ContainerCreateOptions struct {
 	IPC string
 	// ShmSize set the size of /dev/shm
 	ShmSize string
+	// NoShm removes the default /dev/shm mount
+	NoShm bool
 	// #endregion
 
 	// #region for gpu flags
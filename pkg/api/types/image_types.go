@@ -18,11 +18,13 @@ package types
 
 import (
 	"io"
+	"time"
 )
 
 // ImageListOptions specifies options for `nerdctl image list`.
 type ImageListOptions struct {
 	Stdout io.Writer
+	Stderr io.Writer
 	// GOptions is the global options
 	GOptions GlobalCommandOptions
 	// Quiet only show numeric IDs
@@ -33,6 +35,8 @@ type ImageListOptions struct {
 	Format string
 	// Filter output based on conditions provided, for the --filter argument
 	Filters []string
+	// Hide the images matching any of the references (e.g., "k8s.gcr.io/*"), from the [images] section of nerdctl.toml
+	Hide []string
 	// NameAndRefFilter filters images by name and reference
 	NameAndRefFilter []string
 	// Digests show digests (compatible with Docker, unlike ID)
@@ -41,6 +45,24 @@ type ImageListOptions struct {
 	Names bool
 	// All (unimplemented yet, always true)
 	All bool
+	// CreatedFromLabel is the key of the image label to read the created time (RFC3339) from, instead of the image record
+	CreatedFromLabel string
+	// ProbeSnapshotters computes the size by probing all the registered snapshotters, not only GOptions.Snapshotter
+	ProbeSnapshotters bool
+	// Verbose prints `Showing N of M images (K filtered out)` to Stderr after the list
+	Verbose bool
+	// ShowLogicalSize shows the logical size of the layers, distinct from the on-disk size of the snapshots
+	ShowLogicalSize bool
+	// AllPlatforms prints a single row for all the platforms of an image, with the sizes summed up
+	AllPlatforms bool
+	// MaxConcurrency is the maximum number of the images whose sizes are computed in parallel (0 for GOMAXPROCS)
+	MaxConcurrency int
+	// TotalsBy prints the deduplicated sizes grouped by the key ("arch"), instead of the images
+	TotalsBy string
+	// Sort sorts the images by the key ("digest"), instead of the order of the image store
+	Sort string
+	// NoCache computes the sizes of the snapshots live, without reading or updating the size cache
+	NoCache bool
 }
 
 // ImageConvertOptions specifies options for `nerdctl image convert`.
@@ -154,6 +176,20 @@ type ImageInspectOptions struct {
 	Format string
 	// Platform inspect content for a specific platform
 	Platform string
+	// JSONCompact prints the default JSON output on a single line
+	JSONCompact bool
+	// FollowIndex fails if the index of the image does not contain the requested (or the host) platform
+	FollowIndex bool
+	// Platforms prints the platforms available for the image, instead of the image itself
+	Platforms bool
+	// ShowLease reports the leases that protect the content of the image
+	ShowLease bool
+	// DownloadSizes reports the compressed sizes of the layers, read from the manifest
+	DownloadSizes bool
+	// Diff prints the differences between the two images, instead of the images themselves
+	Diff bool
+	// ConfigOnly prints the config blob of the image for the platform verbatim, instead of the inspection
+	ConfigOnly bool
 }
 
 // ImagePushOptions specifies options for `nerdctl (image) push`.
@@ -199,20 +235,39 @@ type ImagePullOptions struct {
 	AllPlatforms bool
 	// Suppress verbose output
 	Quiet bool
+	// ProgressOutput is the format of the progress output ("auto" or "json")
+	ProgressOutput string
 	// multiaddr of IPFS API (default uses $IPFS_PATH env variable if defined or local directory ~/.ipfs)
 	IPFSAddress string
 	// Flags to pass into remote snapshotters
 	RFlags RemoteSnapshotterFlags
+	// Labels are merged into the labels of the pulled image record ("key=value")
+	Labels []string
+	// Retry is the number of the retries on a failed pull, only for `nerdctl pull`
+	Retry int
+	// RetryBackoff is the initial delay between the retries, doubled for each retry
+	RetryBackoff time.Duration
+	// RetryBackoffMax is the maximum delay between the retries
+	RetryBackoffMax time.Duration
+	// RetryMaxDuration is the maximum total duration of the pull including the retries, 0 for no limit
+	RetryMaxDuration time.Duration
+	// StoreMetadata stores the ETag, Last-Modified, and Cache-Control headers of the registry as the labels of the image record
+	StoreMetadata bool
 }
 
 // ImageTagOptions specifies options for `nerdctl (image) tag`.
 type ImageTagOptions struct {
+	Stdout io.Writer
 	// GOptions is the global options
 	GOptions GlobalCommandOptions
 	// Source is the image to be referenced.
 	Source string
 	// Target is the image to be created.
 	Target string
+	// Targets are the images to be created in addition to Target.
+	Targets []string
+	// Force moves the targets even if they already refer to a different image
+	Force bool
 }
 
 // ImageRemoveOptions specifies options for `nerdctl rmi` and `nerdctl image rm`.
@@ -224,6 +279,8 @@ type ImageRemoveOptions struct {
 	Force bool
 	// Async asynchronous mode or not
 	Async bool
+	// NoPrune keeps the untagged parents of the removed images
+	NoPrune bool
 }
 
 // ImagePruneOptions specifies options for `nerdctl image prune` and `nerdctl image rm`.
@@ -235,16 +292,26 @@ type ImagePruneOptions struct {
 	All bool
 	// Force will not prompt for confirmation.
 	Force bool
+	// Verbose prints the reason and the size of each removed image.
+	Verbose bool
+	// Filters limits the images to be removed, e.g., "reference=example.com/tmp-*", "label=foo", and "until=24h".
+	// Multiple filters are combined with AND, except the reference includes.
+	Filters []string
 }
 
 // ImageSaveOptions specifies options for `nerdctl (image) save`.
 type ImageSaveOptions struct {
-	Stdout   io.Writer
-	GOptions GlobalCommandOptions
+	Stdout io.Writer
+	// ProgressOutput displays the progress, if non-nil.
+	// It must not be the same stream as Stdout, e.g., it has to be stderr when saving to stdout.
+	ProgressOutput io.Writer
+	GOptions       GlobalCommandOptions
 	// Export content for all platforms
 	AllPlatforms bool
 	// Export content for a specific platform
 	Platform []string
+	// Compress the archive with gzip
+	Compress bool
 }
 
 // ImageSignOptions contains options for signing an image. It contains options from
@@ -275,6 +342,15 @@ type ImageVerifyOptions struct {
 	CosignCertificateOidcIssuerRegexp string
 }
 
+// ImageVerifyPolicyOptions specifies options for `nerdctl image verify`.
+type ImageVerifyPolicyOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Policy is the path of the trust policy file
+	Policy string
+}
+
 // SociOptions contains options for SOCI.
 type SociOptions struct {
 	// Span size that soci index uses to segment layer data. Default is 4 MiB.
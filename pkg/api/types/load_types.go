@@ -29,4 +29,6 @@ type ImageLoadOptions struct {
 	Platform []string
 	// AllPlatforms import content for all platforms
 	AllPlatforms bool
+	// Quiet suppresses the progress output, only the "Loaded image:" lines are printed
+	Quiet bool
 }
@@ -0,0 +1,55 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import "io"
+
+// ManifestCreateOptions specifies options for `nerdctl manifest create`.
+type ManifestCreateOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Images are the images whose manifests are added to the new list
+	Images []string
+}
+
+// ManifestAddOptions specifies options for `nerdctl manifest add`.
+type ManifestAddOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
+
+// ManifestInspectOptions specifies options for `nerdctl manifest inspect`.
+type ManifestInspectOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
+
+// ManifestPushOptions specifies options for `nerdctl manifest push`.
+type ManifestPushOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+	// Purge removes the manifest list from the local store after pushing it
+	Purge bool
+	// Quiet suppresses verbose output
+	Quiet bool
+}
+
+// ManifestRemoveOptions specifies options for `nerdctl manifest rm`.
+type ManifestRemoveOptions struct {
+	Stdout   io.Writer
+	GOptions GlobalCommandOptions
+}
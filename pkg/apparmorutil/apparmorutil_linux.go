@@ -79,6 +79,42 @@ func CanApplySpecificExistingProfile(profileName string) bool {
 	return true
 }
 
+// IsProfileLoaded returns whether the profile is loaded in the kernel.
+//
+// IsProfileLoaded reads /sys/kernel/security/apparmor/profiles ,
+// and falls back to CanApplySpecificExistingProfile when the file is not accessible (e.g., from user namespaces).
+// When aa-exec is not installed either, the profile cannot be checked and is assumed to be loaded.
+func IsProfileLoaded(profileName string) bool {
+	b, err := os.ReadFile("/sys/kernel/security/apparmor/profiles")
+	if err != nil {
+		if _, lookErr := exec.LookPath("aa-exec"); lookErr != nil {
+			log.L.WithError(err).Debugf("cannot check whether AppArmor profile %q is loaded", profileName)
+			return true
+		}
+		log.L.WithError(err).Debug("failed to read the loaded AppArmor profiles, falling back to aa-exec")
+		return CanApplySpecificExistingProfile(profileName)
+	}
+	_, ok := parseLoadedProfiles(b)[profileName]
+	return ok
+}
+
+// parseLoadedProfiles parses the content of /sys/kernel/security/apparmor/profiles ,
+// i.e., the lines of "NAME (MODE)", into the set of the profile names.
+func parseLoadedProfiles(b []byte) map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if i := strings.LastIndex(line, " ("); i > 0 && strings.HasSuffix(line, ")") {
+			line = line[:i]
+		}
+		names[line] = struct{}{}
+	}
+	return names
+}
+
 type Profile struct {
 	Name string `json:"Name"`           // e.g., "nerdctl-default"
 	Mode string `json:"Mode,omitempty"` // e.g., "enforce"
@@ -0,0 +1,38 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apparmorutil
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseLoadedProfiles(t *testing.T) {
+	b := []byte(`nerdctl-default (enforce)
+/usr/bin/man (complain)
+profile with spaces (enforce)
+unmoded
+
+`)
+	assert.DeepEqual(t, parseLoadedProfiles(b), map[string]struct{}{
+		"nerdctl-default":     {},
+		"/usr/bin/man":        {},
+		"profile with spaces": {},
+		"unmoded":             {},
+	})
+}
@@ -0,0 +1,61 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package cgrouputil provides utilities for translating the resource limits
+// between cgroup v1 and cgroup v2 (unified hierarchy).
+package cgrouputil
+
+import (
+	"strconv"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// UnifiedCPUWeight is the cgroup v2 file that replaces "cpu.shares" of cgroup v1.
+const UnifiedCPUWeight = "cpu.weight"
+
+// CPUSharesToWeight converts the cgroup v1 cpu.shares ([2-262144]) to the cgroup v2 cpu.weight ([1-10000]).
+// Zero means unset, and is kept as zero.
+//
+// The formula is the same as runc and crun:
+// https://github.com/opencontainers/runc/blob/v1.1.12/libcontainer/cgroups/utils.go#L421-L430
+func CPUSharesToWeight(shares uint64) uint64 {
+	if shares == 0 {
+		return 0
+	}
+	return 1 + ((shares-2)*9999)/262142
+}
+
+// SetCPUShares sets the cpu shares of `r`.
+// With `unified`, the corresponding cpu.weight is set too, so that the spec is correct
+// regardless of whether the runtime translates cpu.shares by itself.
+func SetCPUShares(r *specs.LinuxResources, shares uint64, unified bool) {
+	if r.CPU == nil {
+		r.CPU = &specs.LinuxCPU{}
+	}
+	r.CPU.Shares = &shares
+	if !unified {
+		return
+	}
+	if r.Unified == nil {
+		r.Unified = make(map[string]string)
+	}
+	if weight := CPUSharesToWeight(shares); weight != 0 {
+		r.Unified[UnifiedCPUWeight] = strconv.FormatUint(weight, 10)
+	} else {
+		delete(r.Unified, UnifiedCPUWeight)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgrouputil
+
+import (
+	"os"
+
+	"github.com/containerd/cgroups/v3"
+)
+
+// rtRuntimeFile is the knob of the real-time group scheduling of the cgroup v1 cpu controller.
+const rtRuntimeFile = "/sys/fs/cgroup/cpu/cpu.rt_runtime_us"
+
+// IsUnified returns whether the host uses the cgroup v2 unified hierarchy.
+func IsUnified() bool {
+	return cgroups.Mode() == cgroups.Unified
+}
+
+// Version returns the cgroup version of the host, "1" or "2".
+func Version() string {
+	if IsUnified() {
+		return "2"
+	}
+	return "1"
+}
+
+// RealtimeSupported returns whether the host kernel supports the real-time group scheduling
+// (CONFIG_RT_GROUP_SCHED), which is required for the realtime runtime and period of the cpu controller.
+// The cgroup v2 cpu controller does not support the real-time group scheduling.
+func RealtimeSupported() bool {
+	if IsUnified() {
+		return false
+	}
+	_, err := os.Stat(rtRuntimeFile)
+	return err == nil
+}
@@ -0,0 +1,52 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgrouputil
+
+import (
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestCPUSharesToWeight(t *testing.T) {
+	testCases := map[uint64]uint64{
+		0:      0,
+		2:      1,
+		1024:   39,
+		2000:   77,
+		262144: 10000,
+	}
+	for shares, weight := range testCases {
+		assert.Equal(t, CPUSharesToWeight(shares), weight, "shares=%d", shares)
+	}
+}
+
+func TestSetCPUShares(t *testing.T) {
+	r := &specs.LinuxResources{}
+	SetCPUShares(r, 2000, false)
+	assert.Equal(t, *r.CPU.Shares, uint64(2000))
+	assert.Assert(t, r.Unified == nil)
+
+	r = &specs.LinuxResources{Unified: map[string]string{"memory.high": "42"}}
+	SetCPUShares(r, 2000, true)
+	assert.Equal(t, *r.CPU.Shares, uint64(2000))
+	assert.DeepEqual(t, r.Unified, map[string]string{"memory.high": "42", UnifiedCPUWeight: "77"})
+
+	SetCPUShares(r, 0, true)
+	assert.DeepEqual(t, r.Unified, map[string]string{"memory.high": "42"})
+}
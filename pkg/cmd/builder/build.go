@@ -17,6 +17,7 @@
 package builder
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -25,8 +26,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/errdefs"
@@ -59,11 +63,21 @@ func (p platformParser) DefaultSpec() platforms.Platform {
 
 func Build(ctx context.Context, client *containerd.Client, options types.BuilderBuildOptions) error {
 	buildctlBinary, buildctlArgs, needsLoading, metaFile, tags, cleanup, err := generateBuildctlArgs(ctx, client, options)
+	// cleanup is returned with the error too, once the temporary directories are created
+	if cleanup != nil {
+		defer cleanup()
+	}
 	if err != nil {
 		return err
 	}
-	if cleanup != nil {
-		defer cleanup()
+	var refFile string
+	if options.MetadataFile != "" {
+		// the build ref of BuildKit is used as the build ID
+		if refFile, err = createRefFile(); err != nil {
+			return err
+		}
+		defer os.Remove(refFile)
+		buildctlArgs = append(buildctlArgs, "--ref-file="+refFile)
 	}
 
 	log.L.Debugf("running %s %v", buildctlBinary, buildctlArgs)
@@ -82,7 +96,21 @@ func Build(ctx context.Context, client *containerd.Client, options types.Builder
 	if !options.Quiet {
 		buildctlCmd.Stderr = options.Stderr
 	}
+	var counter *cacheCounter
+	if options.MetadataFile != "" {
+		if counter = newCacheCounter(options.Stderr, options.Progress, options.Quiet); counter != nil {
+			buildctlCmd.Stderr = counter
+		}
+	}
+	// the denied entitlement is only detected when the progress is not printed to a terminal,
+	// otherwise the Dockerfile is checked for the entitlements after a failure
+	var watcher *entitlementWatcher
+	if canPipeProgress(options.Stderr, options.Progress, options.Quiet) {
+		watcher = &entitlementWatcher{w: buildctlCmd.Stderr}
+		buildctlCmd.Stderr = watcher
+	}
 
+	startedAt := time.Now()
 	if err := buildctlCmd.Start(); err != nil {
 		return err
 	}
@@ -98,11 +126,37 @@ func Build(ctx context.Context, client *containerd.Client, options types.Builder
 	}
 
 	if err = buildctlCmd.Wait(); err != nil {
+		if denied := watcher.deniedEntitlement(); denied != "" {
+			if slices.Contains(options.Allow, denied) {
+				return fmt.Errorf("%w (Hint: entitlement %q has to be granted by the build daemon too, see `insecure-entitlements` of buildkitd.toml)", err, denied)
+			}
+			return fmt.Errorf("%w (Hint: the build requires `--allow=%s`)", err, denied)
+		}
+		if watcher == nil {
+			for _, ent := range dockerfileEntitlements(dockerfilePath(buildctlArgs)) {
+				if !slices.Contains(options.Allow, ent) {
+					return fmt.Errorf("%w (Hint: the Dockerfile may require `--allow=%s`)", err, ent)
+				}
+			}
+		}
 		return err
 	}
+	completedAt := time.Now()
+
+	var metadata map[string]json.RawMessage
+	if metaFile != "" {
+		metadata, err = readMetaFile(metaFile)
+		if err != nil {
+			if options.IidFile != "" {
+				log.L.WithError(err).Errorf("failed to read metadata file %s", metaFile)
+				return err
+			}
+			log.L.WithError(err).Warnf("failed to read metadata file %s", metaFile)
+		}
+	}
 
 	if options.IidFile != "" {
-		id, err := getDigestFromMetaFile(metaFile)
+		id, err := readMetaDigest(metadata)
 		if err != nil {
 			return err
 		}
@@ -111,6 +165,17 @@ func Build(ctx context.Context, client *containerd.Client, options types.Builder
 		}
 	}
 
+	if options.MetadataFile != "" {
+		// the image has been built, so failing to write the metadata does not fail the build
+		buildRef, err := os.ReadFile(refFile)
+		if err != nil {
+			log.L.WithError(err).Warnf("failed to read the build ref file %s", refFile)
+		}
+		if err := writeMetadataFile(options.MetadataFile, metadata, strings.TrimSpace(string(buildRef)), tags, startedAt, completedAt, counter); err != nil {
+			log.L.WithError(err).Warnf("failed to write the build metadata to %q", options.MetadataFile)
+		}
+	}
+
 	if len(tags) > 1 {
 		log.L.Debug("Found more than 1 tag")
 		imageService := client.ImageService()
@@ -248,25 +313,48 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		output = output + ",dangling-name-prefix=<none>"
 	}
 
+	buildContext := options.BuildContext
+	dir := options.BuildContext
+	file := buildkitutil.DefaultDockerfileName
+	if buildContext == "-" {
+		// The Dockerfile is read from stdin, without a build context, as in `docker build - < Dockerfile`.
+		// As the context is empty, only the remote sources (e.g., `ADD <URL>`) can be added.
+		if options.File != "" {
+			return "", nil, false, "", nil, nil, errors.New("the Dockerfile (-f) cannot be specified when the build context is read from stdin")
+		}
+		dir, err = buildkitutil.WriteTempDockerfile(options.Stdin)
+		if err != nil {
+			return "", nil, false, "", nil, nil, err
+		}
+		buildContext, err = os.MkdirTemp("", "buildkit-empty-context")
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", nil, false, "", nil, nil, err
+		}
+		stdinDir, emptyContext := dir, buildContext
+		cleanup = func() {
+			os.RemoveAll(stdinDir)
+			os.RemoveAll(emptyContext)
+		}
+	}
+
 	buildctlArgs = buildkitutil.BuildctlBaseArgs(options.BuildKitHost)
 
 	buildctlArgs = append(buildctlArgs, []string{
 		"build",
 		"--progress=" + options.Progress,
 		"--frontend=dockerfile.v0",
-		"--local=context=" + options.BuildContext,
+		"--local=context=" + buildContext,
 		"--output=" + output,
 	}...)
 
-	dir := options.BuildContext
-	file := buildkitutil.DefaultDockerfileName
 	if options.File != "" {
 		if options.File == "-" {
 			// Super Warning: this is a special trick to update the dir variable, Don't move this line!!!!!!
 			var err error
 			dir, err = buildkitutil.WriteTempDockerfile(options.Stdin)
 			if err != nil {
-				return "", nil, false, "", nil, nil, err
+				return "", nil, false, "", nil, cleanup, err
 			}
 			cleanup = func() {
 				os.RemoveAll(dir)
@@ -281,12 +369,12 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 	}
 	dir, file, err = buildkitutil.BuildKitFile(dir, file)
 	if err != nil {
-		return "", nil, false, "", nil, nil, err
+		return "", nil, false, "", nil, cleanup, err
 	}
 
 	buildCtx, err := parseContextNames(options.ExtendedBuildContext)
 	if err != nil {
-		return "", nil, false, "", nil, nil, err
+		return "", nil, false, "", nil, cleanup, err
 	}
 
 	for k, v := range buildCtx {
@@ -300,7 +388,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 
 		path, err := filepath.Abs(v)
 		if err != nil {
-			return "", nil, false, "", nil, nil, err
+			return "", nil, false, "", nil, cleanup, err
 		}
 		buildctlArgs = append(buildctlArgs, fmt.Sprintf("--local=%s=%s", k, path))
 		buildctlArgs = append(buildctlArgs, fmt.Sprintf("--opt=context:%s=local:%s", k, k))
@@ -347,7 +435,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 				}
 			}
 		} else {
-			return "", nil, false, "", nil, nil, fmt.Errorf("invalid build arg %q", ba)
+			return "", nil, false, "", nil, cleanup, fmt.Errorf("invalid build arg %q", ba)
 		}
 	}
 
@@ -359,7 +447,12 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		}
 	}
 
+	// The labels override the LABEL instructions in the Dockerfile
 	for _, l := range strutil.DedupeStrSlice(options.Label) {
+		if !strings.Contains(l, "=") {
+			// `--label foo` sets an empty value, as in Docker
+			l += "="
+		}
 		buildctlArgs = append(buildctlArgs, "--opt=label:"+l)
 	}
 
@@ -372,6 +465,9 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 	}
 
 	for _, s := range strutil.DedupeStrSlice(options.Allow) {
+		if !slices.Contains(entitlements, s) {
+			return "", nil, false, "", nil, cleanup, fmt.Errorf("invalid --allow value %q (must be one of %v)", s, entitlements)
+		}
 		buildctlArgs = append(buildctlArgs, "--allow="+s)
 	}
 
@@ -381,7 +477,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 			optAttestType := strings.TrimPrefix(optAttestType, "type=")
 			buildctlArgs = append(buildctlArgs, fmt.Sprintf("--opt=attest:%s=%s", optAttestType, optAttestAttrs))
 		} else {
-			return "", nil, false, "", nil, nil, fmt.Errorf("attestation type not specified")
+			return "", nil, false, "", nil, cleanup, fmt.Errorf("attestation type not specified")
 		}
 	}
 
@@ -407,7 +503,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		log.L.Warn("ignoring deprecated flag: '--rm=false'")
 	}
 
-	if options.IidFile != "" {
+	if options.IidFile != "" || options.MetadataFile != "" {
 		file, err := os.CreateTemp("", "buildkit-meta-*")
 		if err != nil {
 			return "", nil, false, "", nil, cleanup, err
@@ -432,30 +528,6 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 	return buildctlBinary, buildctlArgs, needsLoading, metaFile, tags, cleanup, nil
 }
 
-func getDigestFromMetaFile(path string) (string, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-	defer os.Remove(path)
-
-	metadata := map[string]json.RawMessage{}
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		log.L.WithError(err).Errorf("failed to unmarshal metadata file %s", path)
-		return "", err
-	}
-	digestRaw, ok := metadata["containerimage.digest"]
-	if !ok {
-		return "", errors.New("failed to find containerimage.digest in metadata file")
-	}
-	var digest string
-	if err := json.Unmarshal(digestRaw, &digest); err != nil {
-		log.L.WithError(err).Errorf("failed to unmarshal digset")
-		return "", err
-	}
-	return digest, nil
-}
-
 func isMatchingRuntimePlatform(platform string, parser PlatformParser) bool {
 	p, err := parser.Parse(platform)
 	if err != nil {
@@ -523,3 +595,104 @@ func parseContextNames(values []string) (map[string]string, error) {
 	}
 	return result, nil
 }
+
+// entitlements are the privileged entitlements of BuildKit that can be allowed with `--allow`.
+var entitlements = []string{"network.host", "security.insecure"}
+
+// entitlementWatcher forwards the progress output of buildctl to w (if non-nil), and records
+// the entitlement that BuildKit refused, e.g., "network.host is not allowed" for `RUN --network=host`.
+type entitlementWatcher struct {
+	w      io.Writer
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	denied string
+}
+
+func (e *entitlementWatcher) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	e.buf.Write(p)
+	for {
+		line, err := e.buf.ReadString('\n')
+		if err != nil {
+			// keep the incomplete line for the next write
+			e.buf.WriteString(line)
+			break
+		}
+		e.watchLine(line)
+	}
+	e.mu.Unlock()
+	if e.w == nil {
+		return len(p), nil
+	}
+	return e.w.Write(p)
+}
+
+func (e *entitlementWatcher) watchLine(line string) {
+	if !strings.Contains(line, "is not allowed") {
+		return
+	}
+	for _, ent := range entitlements {
+		if strings.Contains(line, ent+" is not allowed") {
+			e.denied = ent
+			return
+		}
+	}
+}
+
+// runFlagEntitlements are the flags of the RUN instruction that require the entitlements.
+var runFlagEntitlements = map[string]string{
+	"--network=host":      "network.host",
+	"--security=insecure": "security.insecure",
+}
+
+// dockerfilePath returns the path of the Dockerfile passed to buildctl with
+// `--local=dockerfile=<dir>` and `--opt=filename=<file>`.
+func dockerfilePath(buildctlArgs []string) string {
+	var dir, file string
+	for _, arg := range buildctlArgs {
+		if v, ok := strings.CutPrefix(arg, "--local=dockerfile="); ok {
+			dir = v
+		} else if v, ok := strings.CutPrefix(arg, "--opt=filename="); ok {
+			file = v
+		}
+	}
+	if dir == "" || file == "" {
+		return ""
+	}
+	return filepath.Join(dir, file)
+}
+
+// dockerfileEntitlements returns the entitlements required by the RUN instructions of the Dockerfile,
+// for the progress printed to a terminal, which cannot be watched for the denied entitlement.
+// An unreadable Dockerfile has no entitlements.
+func dockerfileEntitlements(path string) []string {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.L.WithError(err).Debugf("failed to read the Dockerfile %q", path)
+		return nil
+	}
+	var ents []string
+	for _, field := range strings.Fields(string(data)) {
+		if ent, ok := runFlagEntitlements[field]; ok && !slices.Contains(ents, ent) {
+			ents = append(ents, ent)
+		}
+	}
+	return ents
+}
+
+// deniedEntitlement returns the entitlement that BuildKit refused, or an empty string.
+// A nil watcher returns an empty string.
+func (e *entitlementWatcher) deniedEntitlement() string {
+	if e == nil {
+		return ""
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.buf.Len() > 0 {
+		e.watchLine(e.buf.String())
+	}
+	return e.denied
+}
@@ -0,0 +1,205 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Keys of the metadata file written by `nerdctl build --metadata-file`, in addition to the keys of BuildKit
+// (e.g., "containerimage.digest").
+const (
+	metadataImageName   = "image.name"
+	metadataBuildID     = "nerdctl.build.id"
+	metadataStartedAt   = "nerdctl.build.startedAt"
+	metadataCompletedAt = "nerdctl.build.completedAt"
+	metadataDuration    = "nerdctl.build.duration"
+	metadataCache       = "nerdctl.build.cache"
+)
+
+// cacheStats is the value of "nerdctl.build.cache".
+type cacheStats struct {
+	// Hits is the number of the build steps loaded from the cache
+	Hits int `json:"hits"`
+	// Misses is the number of the build steps actually executed
+	Misses int `json:"misses"`
+}
+
+// cacheCounter counts the cached and the executed steps in the plain progress output of buildctl,
+// while writing the output through. The internal steps of BuildKit (e.g., `[internal] load build definition`)
+// are not counted.
+//
+//	#5 [2/3] RUN apk add curl
+//	#5 CACHED
+//	#6 [3/3] COPY . .
+//	#6 DONE 0.1s
+type cacheCounter struct {
+	w     io.Writer
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	stats cacheStats
+	// internal is the set of the vertex numbers (e.g., "#1") of the internal steps
+	internal map[string]struct{}
+}
+
+func (c *cacheCounter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	c.buf.Write(p)
+	for {
+		line, err := c.buf.ReadString('\n')
+		if err != nil {
+			// keep the incomplete line for the next write
+			c.buf.WriteString(line)
+			break
+		}
+		c.countLine(line)
+	}
+	c.mu.Unlock()
+	return c.w.Write(p)
+}
+
+func (c *cacheCounter) countLine(line string) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "#") {
+		return
+	}
+	if fields[1] == "[internal]" {
+		if c.internal == nil {
+			c.internal = make(map[string]struct{})
+		}
+		c.internal[fields[0]] = struct{}{}
+		return
+	}
+	if _, ok := c.internal[fields[0]]; ok {
+		return
+	}
+	switch fields[1] {
+	case "CACHED":
+		c.stats.Hits++
+	case "DONE":
+		c.stats.Misses++
+	}
+}
+
+// newCacheCounter returns a cacheCounter for the progress output, or nil when the progress is not printed in plain text,
+// i.e., the progress is "tty", or "auto" on a terminal. The progress output on a terminal cannot be wrapped, as buildctl
+// would fall back to the plain text.
+func newCacheCounter(stderr io.Writer, progress string, quiet bool) *cacheCounter {
+	if !canPipeProgress(stderr, progress, quiet) {
+		return nil
+	}
+	if quiet {
+		return &cacheCounter{w: io.Discard}
+	}
+	return &cacheCounter{w: stderr}
+}
+
+// canPipeProgress returns whether the stderr of buildctl can be piped through nerdctl without changing
+// the progress output, i.e., whether buildctl prints the progress in plain text anyway.
+// Piping the stderr of a terminal would make buildctl fall back from tty to plain text (or fail with `--progress=tty`).
+func canPipeProgress(stderr io.Writer, progress string, quiet bool) bool {
+	if quiet {
+		// buildctl prints the progress in plain text for a non-terminal
+		return progress != "tty"
+	}
+	switch progress {
+	case "plain":
+		return true
+	case "auto", "":
+		f, ok := stderr.(*os.File)
+		return !ok || !term.IsTerminal(int(f.Fd()))
+	}
+	return false
+}
+
+// readMetaFile reads and removes the metadata file written by buildctl.
+func readMetaFile(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	metadata := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// createRefFile creates an empty file for `buildctl build --ref-file`.
+func createRefFile() (string, error) {
+	file, err := os.CreateTemp("", "buildkit-ref-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return file.Name(), nil
+}
+
+// writeMetadataFile writes the metadata of BuildKit with the metadata of nerdctl to `path`.
+func writeMetadataFile(path string, metadata map[string]json.RawMessage, buildID string, tags []string,
+	startedAt, completedAt time.Time, counter *cacheCounter) error {
+	if metadata == nil {
+		return errors.New("no metadata was produced by buildctl")
+	}
+	res := make(map[string]interface{}, len(metadata)+6)
+	for k, v := range metadata {
+		res[k] = v
+	}
+	if _, ok := res[metadataImageName]; !ok && len(tags) > 0 {
+		res[metadataImageName] = strings.Join(tags, ",")
+	}
+	if buildID != "" {
+		res[metadataBuildID] = buildID
+	}
+	res[metadataStartedAt] = startedAt.Format(time.RFC3339Nano)
+	res[metadataCompletedAt] = completedAt.Format(time.RFC3339Nano)
+	res[metadataDuration] = completedAt.Sub(startedAt).Seconds()
+	if counter != nil {
+		counter.mu.Lock()
+		res[metadataCache] = counter.stats
+		counter.mu.Unlock()
+	}
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// readMetaDigest returns "containerimage.digest" of the metadata.
+func readMetaDigest(metadata map[string]json.RawMessage) (string, error) {
+	digestRaw, ok := metadata["containerimage.digest"]
+	if !ok {
+		return "", errors.New("failed to find containerimage.digest in metadata file")
+	}
+	var digest string
+	if err := json.Unmarshal(digestRaw, &digest); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
@@ -17,6 +17,9 @@
 package builder
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -187,3 +190,70 @@ func TestIsBuildPlatformDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheCounter(t *testing.T) {
+	var out bytes.Buffer
+	c := &cacheCounter{w: &out}
+	progress := "#1 [internal] load build definition from Dockerfile\n#1 DONE 0.0s\n\n" +
+		"#2 [internal] load metadata for docker.io/library/alpine:latest\n#2 CACHED\n\n" +
+		"#5 [2/3] RUN apk add curl\n#5 CACHED\n\n#6 [3/3] COPY . .\n#6 0.123 DONE is not a status\n#6 DO"
+	// the last line is split across the writes
+	for _, s := range []string{progress, "NE 0.1s\n"} {
+		n, err := c.Write([]byte(s))
+		assert.NilError(t, err)
+		assert.Equal(t, n, len(s))
+	}
+	assert.Equal(t, out.String(), progress+"NE 0.1s\n")
+	assert.Equal(t, c.stats, cacheStats{Hits: 1, Misses: 1})
+}
+
+func TestEntitlementWatcher(t *testing.T) {
+	var out bytes.Buffer
+	e := &entitlementWatcher{w: &out}
+	progress := "#5 [2/2] RUN --network=host true\n#5 ERROR: network.host is not al"
+	for _, s := range []string{progress, "lowed\n"} {
+		n, err := e.Write([]byte(s))
+		assert.NilError(t, err)
+		assert.Equal(t, n, len(s))
+	}
+	assert.Equal(t, out.String(), progress+"lowed\n")
+	assert.Equal(t, e.deniedEntitlement(), "network.host")
+
+	// the output is discarded with --quiet
+	e = &entitlementWatcher{}
+	_, err := e.Write([]byte("ERROR: security.insecure is not allowed"))
+	assert.NilError(t, err)
+	assert.Equal(t, e.deniedEntitlement(), "security.insecure")
+
+	e = &entitlementWatcher{}
+	_, err = e.Write([]byte("#1 DONE 0.0s\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, e.deniedEntitlement(), "")
+
+	// no watcher for the progress printed to a terminal
+	e = nil
+	assert.Equal(t, e.deniedEntitlement(), "")
+}
+
+func TestDockerfileEntitlements(t *testing.T) {
+	dir := t.TempDir()
+	dockerfile := "FROM alpine\nRUN --network=host \\\n  --security=insecure true\nRUN --network=host true\nRUN --network=none true\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "Dockerfile.test"), []byte(dockerfile), 0644))
+
+	path := dockerfilePath([]string{"build", "--local=context=.", "--local=dockerfile=" + dir, "--opt=filename=Dockerfile.test"})
+	assert.Equal(t, path, filepath.Join(dir, "Dockerfile.test"))
+	assert.DeepEqual(t, dockerfileEntitlements(path), []string{"network.host", "security.insecure"})
+
+	assert.Equal(t, dockerfilePath([]string{"build"}), "")
+	assert.Assert(t, dockerfileEntitlements("") == nil)
+	assert.Assert(t, dockerfileEntitlements(filepath.Join(dir, "nonexistent")) == nil)
+}
+
+func TestCanPipeProgress(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Assert(t, canPipeProgress(&buf, "auto", false))
+	assert.Assert(t, canPipeProgress(&buf, "plain", false))
+	assert.Assert(t, !canPipeProgress(&buf, "tty", false))
+	assert.Assert(t, canPipeProgress(nil, "auto", true))
+	assert.Assert(t, !canPipeProgress(nil, "tty", true))
+}
@@ -56,6 +56,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 	dockercliopts "github.com/docker/cli/opts"
 	dockeropts "github.com/docker/docker/opts"
+	"github.com/moby/sys/signal"
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
@@ -68,6 +69,13 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		newArg = append(newArg, args[2:]...)
 		args = newArg
 	}
+	// the pull mode is validated before creating anything for the container
+	if !options.Rootfs {
+		if err := imgutil.ValidatePullMode(options.Pull); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	var internalLabels internalLabels
 	internalLabels.platform = options.Platform
 	internalLabels.namespace = options.GOptions.Namespace
@@ -96,6 +104,16 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		return nil, nil, err
 	}
 
+	var specFromFile *specs.Spec
+	if options.OCISpecFile != "" {
+		if specFromFile, err = loadOCISpecFile(options.OCISpecFile); err != nil {
+			return nil, nil, err
+		}
+		if len(args) > 1 {
+			log.G(ctx).Warnf("the command %v is ignored, as process.args is read from the OCI spec file %q", args[1:], options.OCISpecFile)
+		}
+	}
+
 	opts = append(opts,
 		oci.WithDefaultSpec(),
 	)
@@ -122,14 +140,35 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		if err != nil {
 			return nil, nil, err
 		}
+		internalLabels.imageDigest = ensuredImage.Image.Target().Digest.String()
 	}
 
-	rootfsOpts, rootfsCOpts, err := generateRootfsOpts(args, id, ensuredImage, options)
+	var (
+		usernsSnapshotter string
+		usernsImage       containerd.Image
+	)
+	if ensuredImage != nil {
+		usernsSnapshotter, usernsImage = ensuredImage.Snapshotter, ensuredImage.Image
+	}
+	// held until the container is created, so that the allocated ranges are visible to the others
+	unlockUserns, err := lockUsernsAuto(dataStore, options.Userns)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlockUserns()
+	usernsOpts, usernsSnapshotOpt, err := generateUsernsOpts(ctx, client, id, usernsSnapshotter, usernsImage, options.Userns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rootfsOpts, rootfsCOpts, err := generateRootfsOpts(args, id, ensuredImage, usernsSnapshotOpt, options)
 	if err != nil {
 		return nil, nil, err
 	}
 	opts = append(opts, rootfsOpts...)
 	cOpts = append(cOpts, rootfsCOpts...)
+	// after the rootfs opts that set the user of the image, and before `--user`
+	opts = append(opts, usernsOpts...)
 
 	if options.Workdir != "" {
 		opts = append(opts, oci.WithProcessCwd(options.Workdir))
@@ -139,7 +178,11 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	if err != nil {
 		return nil, nil, err
 	}
-	opts = append(opts, oci.WithEnv(envs))
+	envOpt, err := generateEnvOpt(envs, options.EnvReplaceAll, options.EnvAppend)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, envOpt)
 
 	if options.Interactive {
 		if options.Detach {
@@ -203,8 +246,9 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	// The OCI hooks we define (whose logic can be found in pkg/ocihook) primarily
 	// perform network setup and teardown when using CNI networking.
 	// On Windows, we are forced to set up and tear down the networking from within nerdctl.
+	var hookOpt oci.SpecOpts
 	if runtime.GOOS != "windows" {
-		hookOpt, err := withNerdctlOCIHook(options.NerdctlCmd, options.NerdctlArgs)
+		hookOpt, err = withNerdctlOCIHook(options.NerdctlCmd, options.NerdctlArgs)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -216,7 +260,7 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		return nil, nil, err
 	}
 	opts = append(opts, uOpts...)
-	gOpts, err := generateGroupsOpts(options.GroupAdd)
+	gOpts, err := generateGroupsOpts(options.GroupAdd, options.GroupFile, internalLabels.stateDir, options.Runtime)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -284,9 +328,23 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	}
 	cOpts = append(cOpts, ilOpt)
 
+	if specFromFile != nil {
+		// Only the env, volume, and network flags (and the hook for the network) are applied on top of the spec file
+		opts = append([]oci.SpecOpts{withOCISpec(specFromFile), envOpt}, mountOpts...)
+		opts = append(opts, netOpts...)
+		if hookOpt != nil {
+			opts = append(opts, hookOpt)
+		}
+	} else if len(options.ReadonlyPaths) > 0 {
+		opts = append(opts, withReadonlyPathsValidation(options.ReadonlyPaths))
+	}
+
 	opts = append(opts, propagateInternalContainerdLabelsToOCIAnnotations(),
 		oci.WithAnnotations(strutil.ConvertKVStringsToMap(options.Annotations)))
 
+	// must be the last SpecOpts
+	opts = append(opts, withSpecValidation())
+
 	var s specs.Spec
 	spec := containerd.WithSpec(&s, opts...)
 
@@ -315,12 +373,17 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	return c, nil, nil
 }
 
-func generateRootfsOpts(args []string, id string, ensured *imgutil.EnsuredImage, options types.ContainerCreateOptions) (opts []oci.SpecOpts, cOpts []containerd.NewContainerOpts, err error) {
+// generateRootfsOpts generates the opts for the rootfs.
+// snapshotOpt overrides the creation of the rootfs snapshot when non-nil (e.g., for `--userns`).
+func generateRootfsOpts(args []string, id string, ensured *imgutil.EnsuredImage, snapshotOpt containerd.NewContainerOpts, options types.ContainerCreateOptions) (opts []oci.SpecOpts, cOpts []containerd.NewContainerOpts, err error) {
 	if !options.Rootfs {
+		if snapshotOpt == nil {
+			snapshotOpt = containerd.WithNewSnapshot(id, ensured.Image)
+		}
 		cOpts = append(cOpts,
 			containerd.WithImage(ensured.Image),
 			containerd.WithSnapshotter(ensured.Snapshotter),
-			containerd.WithNewSnapshot(id, ensured.Image),
+			snapshotOpt,
 			containerd.WithImageStopSignal(ensured.Image, "SIGTERM"),
 		)
 
@@ -380,6 +443,14 @@ func generateRootfsOpts(args []string, id string, ensured *imgutil.EnsuredImage,
 		entrypointPath == "/usr/local/sbin/init")
 
 	stopSignal := options.StopSignal
+	// The STOPSIGNAL of the image is used only when --stop-signal is not specified
+	useImageStopSignal := true
+	if options.StopSignalChanged {
+		if _, err := signal.ParseSignal(stopSignal); err != nil {
+			return nil, nil, fmt.Errorf("invalid stop signal %q: %w", stopSignal, err)
+		}
+		useImageStopSignal = false
+	}
 
 	if options.Systemd == "always" || (options.Systemd == "true" && isEntryPointSystemd) {
 		if options.Privileged {
@@ -406,9 +477,10 @@ func generateRootfsOpts(args []string, id string, ensured *imgutil.EnsuredImage,
 			}),
 		)
 		stopSignal = "SIGRTMIN+3"
+		useImageStopSignal = false
 	}
 
-	cOpts = append(cOpts, withStop(stopSignal, options.StopTimeout, ensured))
+	cOpts = append(cOpts, withStop(stopSignal, useImageStopSignal, options.StopTimeout, ensured))
 
 	if options.InitBinary != nil {
 		options.InitProcessFlag = true
@@ -439,6 +511,55 @@ func generateRootfsOpts(args []string, id string, ensured *imgutil.EnsuredImage,
 	return opts, cOpts, nil
 }
 
+// generateEnvOpt sets envs on top of the environment variables of the image.
+// By default, envs replace the variables of the image with the same names, as in Docker.
+// With replaceAll (`--env-replace-all`), the variables of the image are cleared, except the default PATH when envs do not have PATH.
+// With appendOnly (`--env-append`), the variables of envs already defined by the image are ignored.
+func generateEnvOpt(envs []string, replaceAll, appendOnly bool) (oci.SpecOpts, error) {
+	switch {
+	case replaceAll && appendOnly:
+		return nil, errors.New("flags --env-replace-all and --env-append cannot be specified together")
+	case replaceAll:
+		return func(ctx context.Context, client oci.Client, c *containers.Container, s *oci.Spec) error {
+			if s.Process == nil {
+				s.Process = &specs.Process{}
+			}
+			s.Process.Env = nil
+			if err := oci.WithEnv(envs)(ctx, client, c, s); err != nil {
+				return err
+			}
+			for _, e := range s.Process.Env {
+				if strings.HasPrefix(e, "PATH=") {
+					return nil
+				}
+			}
+			// the command of the image would not be found without PATH
+			return oci.WithDefaultPathEnv(ctx, client, c, s)
+		}, nil
+	case appendOnly:
+		return func(ctx context.Context, client oci.Client, c *containers.Container, s *oci.Spec) error {
+			defined := make(map[string]struct{})
+			if s.Process != nil {
+				for _, e := range s.Process.Env {
+					defined[strings.SplitN(e, "=", 2)[0]] = struct{}{}
+				}
+			}
+			var appended []string
+			for _, e := range envs {
+				k := strings.SplitN(e, "=", 2)[0]
+				if _, ok := defined[k]; ok {
+					log.G(ctx).Warnf("ignoring the environment variable %q, as it is defined by the image (--env-append)", k)
+					continue
+				}
+				appended = append(appended, e)
+			}
+			return oci.WithEnv(appended)(ctx, client, c, s)
+		}, nil
+	default:
+		return oci.WithEnv(envs), nil
+	}
+}
+
 // GenerateLogURI generates a log URI for the current container store
 func GenerateLogURI(dataStore string) (*url.URL, error) {
 	selfExe, err := os.Executable()
@@ -545,16 +666,30 @@ func parseKVStringsMapFromLogOpt(logOpt []string, logDriver string) (map[string]
 	return logOptMap, nil
 }
 
-func withStop(stopSignal string, stopTimeout int, ensuredImage *imgutil.EnsuredImage) containerd.NewContainerOpts {
+// withStop sets the labels for `nerdctl stop`.
+// When the image is specified, its STOPSIGNAL is preferred over `stopSignal` if `useImageStopSignal` is true,
+// and its StopTimeout (Docker extension) is used if `stopTimeout` is 0.
+func withStop(stopSignal string, useImageStopSignal bool, stopTimeout int, ensuredImage *imgutil.EnsuredImage) containerd.NewContainerOpts {
 	return func(ctx context.Context, _ *containerd.Client, c *containers.Container) error {
 		if c.Labels == nil {
 			c.Labels = make(map[string]string)
 		}
 		var err error
 		if ensuredImage != nil {
-			stopSignal, err = containerd.GetOCIStopSignal(ctx, ensuredImage.Image, stopSignal)
-			if err != nil {
-				return err
+			if useImageStopSignal {
+				stopSignal, err = containerd.GetOCIStopSignal(ctx, ensuredImage.Image, stopSignal)
+				if err != nil {
+					return err
+				}
+			}
+			if stopTimeout == 0 {
+				imageStopTimeout, err := imgutil.ReadImageStopTimeout(ctx, ensuredImage.Image)
+				if err != nil {
+					return err
+				}
+				if imageStopTimeout != nil {
+					stopTimeout = *imageStopTimeout
+				}
 			}
 		}
 		c.Labels[containerd.StopSignalLabel] = stopSignal
@@ -575,7 +710,8 @@ type internalLabels struct {
 	name     string
 	hostname string
 	// automatically generated
-	stateDir string
+	stateDir    string
+	imageDigest string
 	// network
 	networks   []string
 	ipAddress  string
@@ -647,6 +783,10 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		return nil, err
 	}
 
+	if internalLabels.imageDigest != "" {
+		m[labels.ImageDigest] = internalLabels.imageDigest
+	}
+
 	if len(internalLabels.mountPoints) > 0 {
 		mounts := dockercompatMounts(internalLabels.mountPoints)
 		mountPointsJSON, err := json.Marshal(mounts)
@@ -110,15 +110,9 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 			return err
 		}
 	}
-	if !options.Detach {
-		if options.TTY {
-			if err := consoleutil.HandleConsoleResize(ctx, process, con); err != nil {
-				log.G(ctx).WithError(err).Error("console resize")
-			}
-		} else {
-			sigc := signalutil.ForwardAllSignals(ctx, process)
-			defer signalutil.StopCatch(sigc)
-		}
+	if !options.Detach && !options.TTY {
+		sigc := signalutil.ForwardAllSignals(ctx, process)
+		defer signalutil.StopCatch(sigc)
 	}
 
 	if err := process.Start(ctx); err != nil {
@@ -127,6 +121,14 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 	if options.Detach {
 		return nil
 	}
+	if options.TTY {
+		// the process has to be started before resizing its pty
+		resizeCtx, cancelResize := context.WithCancel(ctx)
+		defer cancelResize()
+		if err := consoleutil.HandleConsoleResize(resizeCtx, process, con); err != nil {
+			log.G(ctx).WithError(err).Error("console resize")
+		}
+	}
 	status := <-statusC
 	code, _, err := status.Result()
 	if err != nil {
@@ -38,7 +38,7 @@ import (
 
 // List prints containers according to `options`.
 func List(ctx context.Context, client *containerd.Client, options types.ContainerListOptions) ([]ListItem, error) {
-	containers, err := filterContainers(ctx, client, options.Filters, options.LastN, options.All)
+	containers, err := filterContainers(ctx, client, options.GOptions, options.Filters, options.LastN, options.All)
 	if err != nil {
 		return nil, err
 	}
@@ -51,12 +51,12 @@ func List(ctx context.Context, client *containerd.Client, options types.Containe
 //   - all means showing all containers (default shows just running).
 //   - lastN means only showing n last created containers (includes all states). Non-positive values are ignored.
 //     In other words, if lastN is positive, all will be set to true.
-func filterContainers(ctx context.Context, client *containerd.Client, filters []string, lastN int, all bool) ([]containerd.Container, error) {
+func filterContainers(ctx context.Context, client *containerd.Client, globalOptions types.GlobalCommandOptions, filters []string, lastN int, all bool) ([]containerd.Container, error) {
 	containers, err := client.Containers(ctx)
 	if err != nil {
 		return nil, err
 	}
-	filterCtx, err := foldContainerFilters(ctx, containers, filters)
+	filterCtx, err := foldContainerFilters(ctx, containers, globalOptions, filters)
 	if err != nil {
 		return nil, err
 	}
@@ -91,11 +91,13 @@ type ListItem struct {
 	CreatedAt time.Time
 	ID        string
 	Image     string
+	Digest    string // nerdctl extension; empty for containers created without the digest label
 	Platform  string // nerdctl extension
 	Names     string
 	Ports     string
 	Status    string
 	Runtime   string // nerdctl extension
+	Pod       string // nerdctl extension; empty for containers not belonging to any pod
 	Size      string
 	Labels    map[string]string
 	// TODO: "LocalVolumes", "Mounts", "Networks", "RunningFor", "State"
@@ -133,11 +135,13 @@ func prepareContainers(ctx context.Context, client *containerd.Client, container
 			CreatedAt: info.CreatedAt,
 			ID:        id,
 			Image:     info.Image,
+			Digest:    info.Labels[labels.ImageDigest],
 			Platform:  info.Labels[labels.Platform],
 			Names:     getContainerName(info.Labels),
 			Ports:     formatter.FormatPorts(info.Labels),
 			Status:    formatter.ContainerStatus(ctx, c),
 			Runtime:   info.Runtime.Name,
+			Pod:       info.Labels[labels.Pod],
 			Labels:    info.Labels,
 		}
 		if options.Size {
@@ -152,6 +156,50 @@ func prepareContainers(ctx context.Context, client *containerd.Client, container
 	return listItems, nil
 }
 
+// PodGroup is a group of the containers of a pod.
+type PodGroup struct {
+	// Pod is empty for the containers not belonging to any pod.
+	Pod        string
+	Containers []ListItem
+}
+
+// GroupByPod groups the containers by pod (the "nerdctl/pod" label), sorted by the pod name.
+// The label is not set by nerdctl itself, but by the user (`--label`) or by another tool.
+// The containers of a pod are sorted by the creation time.
+// The group of the containers not belonging to any pod comes last.
+func GroupByPod(items []ListItem) []PodGroup {
+	var (
+		groups    []PodGroup
+		ungrouped []ListItem
+		index     = make(map[string]int)
+	)
+	for _, item := range items {
+		if item.Pod == "" {
+			ungrouped = append(ungrouped, item)
+			continue
+		}
+		i, ok := index[item.Pod]
+		if !ok {
+			i = len(groups)
+			index[item.Pod] = i
+			groups = append(groups, PodGroup{Pod: item.Pod})
+		}
+		groups[i].Containers = append(groups[i].Containers, item)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Pod < groups[j].Pod
+	})
+	if len(ungrouped) > 0 {
+		groups = append(groups, PodGroup{Containers: ungrouped})
+	}
+	for _, g := range groups {
+		sort.SliceStable(g.Containers, func(i, j int) bool {
+			return g.Containers[i].CreatedAt.Before(g.Containers[j].CreatedAt)
+		})
+	}
+	return groups
+}
+
 func getContainerName(containerLabels map[string]string) string {
 	if name, ok := containerLabels[labels.Name]; ok {
 		return name
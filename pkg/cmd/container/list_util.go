@@ -26,17 +26,22 @@ import (
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/containers"
 	"github.com/containerd/log"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/netwalker"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
 )
 
-func foldContainerFilters(ctx context.Context, containers []containerd.Container, filters []string) (*containerFilterContext, error) {
-	filterCtx := &containerFilterContext{containers: containers}
+func foldContainerFilters(ctx context.Context, containers []containerd.Container, globalOptions types.GlobalCommandOptions, filters []string) (*containerFilterContext, error) {
+	filterCtx := &containerFilterContext{containers: containers, globalOptions: globalOptions}
 	err := filterCtx.foldFilters(ctx, filters)
 	return filterCtx, err
 }
 
 type containerFilterContext struct {
 	containers []containerd.Container
+	// globalOptions is used for resolving the network IDs of the network filter
+	globalOptions types.GlobalCommandOptions
 
 	idFilterFuncs      []func(string) bool
 	nameFilterFuncs    []func(string) bool
@@ -201,10 +206,11 @@ func (cl *containerFilterContext) foldVolumeFilter(_ context.Context, filter, va
 	return nil
 }
 
-func (cl *containerFilterContext) foldNetworkFilter(_ context.Context, filter, value string) error {
+func (cl *containerFilterContext) foldNetworkFilter(ctx context.Context, filter, value string) error {
+	names := cl.resolveNetworkNames(ctx, value)
 	cl.networkFilterFuncs = append(cl.networkFilterFuncs, func(networks []string) bool {
 		for _, network := range networks {
-			if network == value {
+			if _, ok := names[network]; ok {
 				return true
 			}
 		}
@@ -213,6 +219,28 @@ func (cl *containerFilterContext) foldNetworkFilter(_ context.Context, filter, v
 	return nil
 }
 
+// resolveNetworkNames returns the names of the networks referred by value, i.e., a name, a (short) ID prefix, or a long ID.
+// The value itself is always included, so that the removed networks and the pseudo networks (e.g., "host") still match by the name.
+func (cl *containerFilterContext) resolveNetworkNames(ctx context.Context, value string) map[string]struct{} {
+	names := map[string]struct{}{value: {}}
+	e, err := netutil.NewCNIEnv(cl.globalOptions.CNIPath, cl.globalOptions.CNINetConfPath)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to load the networks, matching the network filter %q by the name only", value)
+		return names
+	}
+	walker := netwalker.NetworkWalker{
+		Client: e,
+		OnFound: func(ctx context.Context, found netwalker.Found) error {
+			names[found.Network.Name] = struct{}{}
+			return nil
+		},
+	}
+	if _, err := walker.Walk(ctx, value); err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to resolve the network filter %q, matching it by the name only", value)
+	}
+	return names
+}
+
 func (cl *containerFilterContext) matchesInfoFilters(ctx context.Context, container containerd.Container) bool {
 	if len(cl.idFilterFuncs)+len(cl.nameFilterFuncs)+len(cl.beforeFilterFuncs)+
 		len(cl.sinceFilterFuncs)+len(cl.labelFilterFuncs)+len(cl.volumeFilterFuncs)+len(cl.networkFilterFuncs) == 0 {
@@ -362,15 +390,27 @@ func (cl *containerFilterContext) matchesNetworkFilter(info containers.Container
 	return false
 }
 
-func idOrNameFilter(ctx context.Context, containers []containerd.Container, value string) (*containers.Container, error) {
-	for _, container := range containers {
+// idOrNameFilter resolves the container referred by an exact name or an ID prefix.
+func idOrNameFilter(ctx context.Context, list []containerd.Container, value string) (*containers.Container, error) {
+	var matched []containers.Container
+	for _, container := range list {
 		info, err := container.Info(ctx, containerd.WithoutRefreshedMetadata)
 		if err != nil {
 			return nil, err
 		}
-		if strings.HasPrefix(info.ID, value) || strings.Contains(getContainerName(info.Labels), value) {
+		if getContainerName(info.Labels) == value {
 			return &info, nil
 		}
+		if strings.HasPrefix(info.ID, value) {
+			matched = append(matched, info)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("no such container %s", value)
+	case 1:
+		return &matched[0], nil
+	default:
+		return nil, fmt.Errorf("multiple IDs found with provided prefix: %s", value)
 	}
-	return nil, fmt.Errorf("no such container %s", value)
 }
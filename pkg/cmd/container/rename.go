@@ -22,6 +22,7 @@ import (
 	"runtime"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/oci"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
@@ -86,5 +87,10 @@ func renameContainer(ctx context.Context, container containerd.Container, newNam
 	if _, err = container.SetLabels(ctx, labels); err != nil {
 		return err
 	}
-	return nil
+	// the name label is also propagated to the OCI annotations (read by the OCI hooks)
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return err
+	}
+	return container.Update(ctx, containerd.UpdateContainerOpts(containerd.WithSpec(spec, oci.WithAnnotations(labels))))
 }
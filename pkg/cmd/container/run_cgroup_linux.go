@@ -20,30 +20,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/containerd/containerd/containers"
 	"github.com/containerd/containerd/oci"
 	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cgrouputil"
 	"github.com/containerd/nerdctl/v2/pkg/infoutil"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
 	"github.com/docker/go-units"
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
+// linuxMinMemory is the minimum memory limit allowed by Docker.
+const linuxMinMemory = 6 * 1024 * 1024
+
 type customMemoryOptions struct {
 	MemoryReservation *int64
 	MemorySwappiness  *uint64
+	KernelMemory      *int64
 	disableOOMKiller  *bool
 }
 
 func generateCgroupOpts(id string, options types.ContainerCreateOptions) ([]oci.SpecOpts, error) {
-	if options.KernelMemory != "" {
-		log.L.Warnf("The --kernel-memory flag is no longer supported. This flag is a noop.")
-	}
-
 	if options.Memory == "" && options.OomKillDisable {
 		log.L.Warn("Disabling the OOM killer on containers without setting a '-m/--memory' limit may be dangerous.")
 	}
@@ -83,7 +86,7 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions) ([]oci.
 	}
 
 	if options.CPUShares != 0 {
-		opts = append(opts, oci.WithCPUShares(options.CPUShares))
+		opts = append(opts, withCPUShares(options.CPUShares))
 	}
 
 	if options.CPUSetCPUs != "" {
@@ -98,6 +101,18 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions) ([]oci.
 	if options.CPUSetMems != "" {
 		opts = append(opts, oci.WithCPUsMems(options.CPUSetMems))
 	}
+	if options.CPURealtimeRuntime != 0 || options.CPURealtimePeriod != 0 {
+		if options.CPURealtimeRuntime < 0 {
+			return nil, errors.New("cpu-rt-runtime must not be negative")
+		}
+		if options.CPURealtimePeriod != 0 && uint64(options.CPURealtimeRuntime) > options.CPURealtimePeriod {
+			return nil, fmt.Errorf("cpu-rt-runtime (%d) cannot be higher than cpu-rt-period (%d)", options.CPURealtimeRuntime, options.CPURealtimePeriod)
+		}
+		if !cgrouputil.RealtimeSupported() {
+			return nil, errors.New("cpu-rt-runtime and cpu-rt-period require the real-time group scheduling of the kernel (CONFIG_RT_GROUP_SCHED with cgroup v1)")
+		}
+		opts = append(opts, oci.WithCPURT(options.CPURealtimeRuntime, options.CPURealtimePeriod))
+	}
 
 	var mem64 int64
 	if options.Memory != "" {
@@ -114,6 +129,22 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions) ([]oci.
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse memory bytes %q: %w", options.MemoryReservation, err)
 		}
+		// same as Docker
+		if memReserve64 > 0 && memReserve64 < linuxMinMemory {
+			return nil, errors.New("minimum memory reservation allowed is 6MB")
+		}
+	}
+	var kernelMem64 int64
+	if options.KernelMemory != "" {
+		kernelMem64, err = units.RAMInBytes(options.KernelMemory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kernel memory bytes %q: %w", options.KernelMemory, err)
+		}
+		if cgrouputil.IsUnified() {
+			// cgroup v2 accounts kernel memory as a part of the memory limit, and has no separate limit for it
+			log.L.Warn("The --kernel-memory flag has no effect on cgroup v2. This flag is a noop.")
+			kernelMem64 = 0
+		}
 	}
 	var memSwap64 int64
 	if options.MemorySwap != "" {
@@ -151,8 +182,16 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions) ([]oci.
 		customMemRes.MemoryReservation = &memReserve64
 	}
 	if options.MemorySwappiness64 >= 0 && options.MemorySwappiness64Changed {
-		memSwapinessUint64 := uint64(options.MemorySwappiness64)
-		customMemRes.MemorySwappiness = &memSwapinessUint64
+		if cgrouputil.IsUnified() {
+			// cgroup v2 has no per-cgroup swappiness knob, the swap usage is only limited with memory.swap.max (`--memory-swap`)
+			log.L.Warn("The --memory-swappiness flag is not supported on cgroup v2. Memory swappiness discarded.")
+		} else {
+			memSwapinessUint64 := uint64(options.MemorySwappiness64)
+			customMemRes.MemorySwappiness = &memSwapinessUint64
+		}
+	}
+	if kernelMem64 > 0 {
+		customMemRes.KernelMemory = &kernelMem64
 	}
 	if options.OomKillDisable {
 		customMemRes.disableOOMKiller = &options.OomKillDisable
@@ -163,7 +202,7 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions) ([]oci.
 		opts = append(opts, oci.WithPidsLimit(options.PidsLimit))
 	}
 
-	if len(options.CgroupConf) > 0 && infoutil.CgroupsVersion() == "1" {
+	if len(options.CgroupConf) > 0 && !cgrouputil.IsUnified() {
 		return nil, errors.New("cannot use --cgroup-conf without cgroup v2")
 	}
 
@@ -205,9 +244,114 @@ func generateCgroupOpts(id string, options types.ContainerCreateOptions) ([]oci.
 		}
 		opts = append(opts, oci.WithLinuxDevice(devPath, mode))
 	}
+
+	if len(options.HugetlbLimit) > 0 {
+		limits, err := parseHugetlbLimits(options.HugetlbLimit)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, withHugepageLimits(limits))
+	}
 	return opts, nil
 }
 
+// hugePagesDir lists the huge page sizes supported by the kernel, as "hugepages-<SIZE>kB" directories.
+const hugePagesDir = "/sys/kernel/mm/hugepages"
+
+// hugePageSizeUnits are the units of the page sizes in the names of the cgroup hugetlb files, e.g., "hugetlb.2MB.max".
+var hugePageSizeUnits = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+
+func formatHugePageSize(size int64) string {
+	return units.CustomSize("%g%s", float64(size), 1024.0, hugePageSizeUnits)
+}
+
+// ParseHugetlbLimit parses a `--hugetlb-limit` value formatted as PAGESIZE:LIMIT, e.g., "2MB:256MB".
+// The page size is returned in the format of the cgroup files, e.g., "2MB" for "2m" or "2048KB".
+func ParseHugetlbLimit(s string) (string, uint64, error) {
+	pageSizeStr, limitStr, ok := strings.Cut(s, ":")
+	if !ok || pageSizeStr == "" || limitStr == "" {
+		return "", 0, fmt.Errorf("invalid hugetlb limit %q (must be formatted PAGESIZE:LIMIT)", s)
+	}
+	pageSize, err := units.RAMInBytes(pageSizeStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse the page size %q: %w", pageSizeStr, err)
+	}
+	if pageSize <= 0 || pageSize&(pageSize-1) != 0 {
+		return "", 0, fmt.Errorf("invalid page size %q (must be a power of 2)", pageSizeStr)
+	}
+	limit, err := units.RAMInBytes(limitStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse the limit %q: %w", limitStr, err)
+	}
+	if limit < 0 {
+		return "", 0, fmt.Errorf("invalid limit %q (must not be negative)", limitStr)
+	}
+	return formatHugePageSize(pageSize), uint64(limit), nil
+}
+
+// hugePageSizes returns the huge page sizes supported by the host, in the format of ParseHugetlbLimit.
+func hugePageSizes() (map[string]struct{}, error) {
+	entries, err := os.ReadDir(hugePagesDir)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]struct{})
+	for _, e := range entries {
+		kb, ok := strings.CutPrefix(e.Name(), "hugepages-")
+		if !ok {
+			continue
+		}
+		kb, ok = strings.CutSuffix(kb, "kB")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(kb, 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[formatHugePageSize(n*1024)] = struct{}{}
+	}
+	return sizes, nil
+}
+
+// parseHugetlbLimits parses the `--hugetlb-limit` values, and validates the page sizes against the host.
+func parseHugetlbLimits(ss []string) ([]specs.LinuxHugepageLimit, error) {
+	available, err := hugePageSizes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the huge page sizes supported by the host: %w", err)
+	}
+	var limits []specs.LinuxHugepageLimit
+	seen := make(map[string]struct{})
+	for _, s := range ss {
+		pageSize, limit, err := ParseHugetlbLimit(s)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := available[pageSize]; !ok {
+			return nil, fmt.Errorf("huge page size %q is not supported by the host (see %s)", pageSize, hugePagesDir)
+		}
+		if _, ok := seen[pageSize]; ok {
+			return nil, fmt.Errorf("duplicate hugetlb limit for page size %q", pageSize)
+		}
+		seen[pageSize] = struct{}{}
+		limits = append(limits, specs.LinuxHugepageLimit{Pagesize: pageSize, Limit: limit})
+	}
+	return limits, nil
+}
+
+func withHugepageLimits(limits []specs.LinuxHugepageLimit) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		if s.Linux.Resources == nil {
+			s.Linux.Resources = &specs.LinuxResources{}
+		}
+		s.Linux.Resources.HugepageLimits = limits
+		return nil
+	}
+}
+
 func generateCgroupPath(id, cgroupManager, cgroupParent string) (string, error) {
 	var (
 		path         string
@@ -301,7 +445,10 @@ func withUnified(unified map[string]string) oci.SpecOpts {
 		if unified == nil {
 			return nil
 		}
-		s.Linux.Resources.Unified = make(map[string]string)
+		// merged, so that the keys set by the other options (e.g., cpu.weight for --cpu-shares) are kept unless overridden
+		if s.Linux.Resources.Unified == nil {
+			s.Linux.Resources.Unified = make(map[string]string)
+		}
 		for k, v := range unified {
 			s.Linux.Resources.Unified[k] = v
 		}
@@ -309,6 +456,20 @@ func withUnified(unified map[string]string) oci.SpecOpts {
 	}
 }
 
+// withCPUShares sets the cpu shares, along with the cpu weight on cgroup v2.
+func withCPUShares(shares uint64) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		if s.Linux.Resources == nil {
+			s.Linux.Resources = &specs.LinuxResources{}
+		}
+		cgrouputil.SetCPUShares(s.Linux.Resources, shares, cgrouputil.IsUnified())
+		return nil
+	}
+}
+
 func withBlkioWeight(blkioWeight uint16) oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
 		if blkioWeight == 0 {
@@ -337,6 +498,9 @@ func withCustomMemoryResources(memoryOptions customMemoryOptions) oci.SpecOpts {
 			if memoryOptions.MemoryReservation != nil {
 				s.Linux.Resources.Memory.Reservation = memoryOptions.MemoryReservation
 			}
+			if memoryOptions.KernelMemory != nil {
+				s.Linux.Resources.Memory.Kernel = memoryOptions.KernelMemory //nolint:staticcheck // deprecated, but still supported on cgroup v1
+			}
 		}
 		return nil
 	}
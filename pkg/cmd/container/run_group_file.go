@@ -0,0 +1,178 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/oci"
+	"github.com/containerd/continuity/fs"
+	"github.com/containerd/nerdctl/v2/pkg/mountutil"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// groupEntry is a single line of a group(5) database.
+type groupEntry struct {
+	name string
+	gid  uint32
+	line string
+}
+
+// parseGroupEntries parses the content of a group(5) database.
+// Blank lines and comments are skipped, malformed lines are rejected.
+func parseGroupEntries(b []byte) ([]groupEntry, error) {
+	var entries []groupEntry
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 || fields[0] == "" {
+			return nil, fmt.Errorf("invalid group entry %q", line)
+		}
+		gid, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gid in group entry %q: %w", line, err)
+		}
+		entries = append(entries, groupEntry{name: fields[0], gid: uint32(gid), line: line})
+	}
+	return entries, scanner.Err()
+}
+
+// mergeGroupEntries appends the extra entries to the base entries.
+// On a duplicate GID (or name) the base entry, i.e., the one of the image, wins.
+func mergeGroupEntries(base, extra []groupEntry) []groupEntry {
+	merged := append([]groupEntry{}, base...)
+	names := make(map[string]struct{})
+	gids := make(map[uint32]struct{})
+	for _, e := range base {
+		names[e.name] = struct{}{}
+		gids[e.gid] = struct{}{}
+	}
+	for _, e := range extra {
+		if _, ok := gids[e.gid]; ok {
+			continue
+		}
+		if _, ok := names[e.name]; ok {
+			continue
+		}
+		names[e.name] = struct{}{}
+		gids[e.gid] = struct{}{}
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// resolveGroups translates the group names of `--group-add` into GIDs using the merged entries.
+// Numeric groups and unknown names are kept as-is.
+func resolveGroups(groups []string, entries []groupEntry) []string {
+	byName := make(map[string]uint32)
+	for _, e := range entries {
+		byName[e.name] = e.gid
+	}
+	resolved := make([]string, len(groups))
+	for i, g := range groups {
+		if gid, ok := byName[g]; ok {
+			resolved[i] = strconv.FormatUint(uint64(gid), 10)
+		} else {
+			resolved[i] = g
+		}
+	}
+	return resolved
+}
+
+// readImageGroupFile reads /etc/group from the rootfs of the container.
+// A missing file is not an error.
+func readImageGroupFile(ctx context.Context, client oci.Client, c *containers.Container) ([]byte, error) {
+	if c.Snapshotter == "" {
+		return nil, errors.New("no snapshotter set for container")
+	}
+	if c.SnapshotKey == "" {
+		return nil, errors.New("rootfs snapshot not created for container")
+	}
+	mounts, err := client.SnapshotService(c.Snapshotter).Mounts(ctx, c.SnapshotKey)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	err = mount.WithReadonlyTempMount(ctx, mounts, func(root string) error {
+		gpath, err := fs.RootPath(root, "/etc/group")
+		if err != nil {
+			return err
+		}
+		b, err = os.ReadFile(gpath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+	return b, err
+}
+
+// withGroupFile merges the group file into the /etc/group of the image, bind-mounts the result
+// from the state dir, and appends the groups of `--group-add` resolved against the merged entries.
+func withGroupFile(groupFile, stateDir string, groups []string) (oci.SpecOpts, error) {
+	b, err := os.ReadFile(groupFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group file %q: %w", groupFile, err)
+	}
+	extra, err := parseGroupEntries(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse group file %q: %w", groupFile, err)
+	}
+	return func(ctx context.Context, client oci.Client, c *containers.Container, s *oci.Spec) error {
+		imageGroupFile, err := readImageGroupFile(ctx, client, c)
+		if err != nil {
+			return err
+		}
+		base, err := parseGroupEntries(imageGroupFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse /etc/group of the image: %w", err)
+		}
+		merged := mergeGroupEntries(base, extra)
+		var buf bytes.Buffer
+		for _, e := range merged {
+			buf.WriteString(e.line + "\n")
+		}
+		src := filepath.Join(stateDir, "group")
+		if err := os.WriteFile(src, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		s.Mounts = append(s.Mounts, specs.Mount{
+			Destination: "/etc/group",
+			Type:        "bind",
+			Source:      src,
+			Options:     []string{"bind", mountutil.DefaultPropagationMode}, // writable
+		})
+		if len(groups) == 0 {
+			return nil
+		}
+		return oci.WithAppendAdditionalGroups(resolveGroups(groups, merged)...)(ctx, client, c, s)
+	}, nil
+}
@@ -18,6 +18,7 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -75,6 +76,22 @@ func setPlatformOptions(ctx context.Context, client *containerd.Client, id, uts
 	}
 	opts = append(opts, secOpts...)
 
+	// must be after secOpts, as --privileged clears the masked paths and the readonly paths
+	maskOpts, err := generateMaskedPathsOpts(options.Mask, options.Unmask)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, maskOpts...)
+	readonlyPathsOpts, err := generateReadonlyPathsOpts(options.ReadonlyPaths, options.NoReadonlyPaths)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, readonlyPathsOpts...)
+	if !options.Privileged && isCapAdded(options.CapAdd, "CAP_NET_RAW") {
+		// must be after secOpts, as the default seccomp profile is generated by secOpts
+		opts = append(opts, withNetRawSeccomp(customSeccompProfile(securityOptsMaps)))
+	}
+
 	b4nnOpts, err := bypass4netnsutil.GenerateBypass4netnsOpts(securityOptsMaps, annotations, id)
 	if err != nil {
 		return nil, err
@@ -146,6 +163,15 @@ func generateNamespaceOpts(
 	}
 	internalLabels.ipc = ipcLabel
 	opts = append(opts, ipcOpts...)
+	if options.NoShm {
+		if options.ShmSize != "" {
+			return nil, errors.New("flags --no-shm and --shm-size cannot be specified together")
+		}
+		if ipc := strings.ToLower(options.IPC); ipc != "" && ipc != "private" {
+			return nil, fmt.Errorf("flag --no-shm cannot be specified with --ipc=%s", options.IPC)
+		}
+		opts = append(opts, withoutDevShm)
+	}
 
 	pidOpts, pidLabel, err := generatePIDOpts(ctx, client, options.Pid)
 	if err != nil {
@@ -176,6 +202,18 @@ func generateIPCOpts(ctx context.Context, client *containerd.Client, ipcFlag str
 	return opts, ipcLabel, nil
 }
 
+// withoutDevShm removes the default /dev/shm mount (`--no-shm`).
+func withoutDevShm(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+	var mounts []specs.Mount
+	for _, m := range s.Mounts {
+		if m.Destination != "/dev/shm" {
+			mounts = append(mounts, m)
+		}
+	}
+	s.Mounts = mounts
+	return nil
+}
+
 func generatePIDOpts(ctx context.Context, client *containerd.Client, pid string) ([]oci.SpecOpts, string, error) {
 	opts := make([]oci.SpecOpts, 0)
 	pid = strings.ToLower(pid)
@@ -17,10 +17,14 @@
 package container
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/containerd/containerd/containers"
 	"github.com/containerd/containerd/contrib/apparmor"
 	"github.com/containerd/containerd/contrib/seccomp"
 	"github.com/containerd/containerd/oci"
@@ -30,6 +34,8 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/defaults"
 	"github.com/containerd/nerdctl/v2/pkg/maputil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
 )
 
 var privilegedOpts = []oci.SpecOpts{
@@ -71,10 +77,19 @@ func generateSecurityOpts(privileged bool, securityOptsMap map[string]string) ([
 		if aaProfile == "" {
 			return nil, errors.New("invalid security-opt \"apparmor\"")
 		}
+		// "unconfined" leaves the profile of the spec empty
 		if aaProfile != "unconfined" {
 			if !canApplyExistingProfile {
 				log.L.Warnf("the host does not support AppArmor. Ignoring profile %q", aaProfile)
 			} else {
+				if aaProfile == defaults.AppArmorProfileName && canLoadNewAppArmor {
+					if err := apparmor.LoadDefaultProfile(defaults.AppArmorProfileName); err != nil {
+						return nil, err
+					}
+				}
+				if !apparmorutil.IsProfileLoaded(aaProfile) {
+					return nil, appArmorProfileNotLoadedError(aaProfile)
+				}
 				opts = append(opts, apparmor.WithProfile(aaProfile))
 			}
 		}
@@ -118,6 +133,182 @@ func generateSecurityOpts(privileged bool, securityOptsMap map[string]string) ([
 	return opts, nil
 }
 
+// appArmorProfileNotLoadedError returns the error for a profile of `--security-opt apparmor=<PROFILE>` not loaded on the host,
+// with the hint to load it.
+func appArmorProfileNotLoadedError(profile string) error {
+	hint := fmt.Sprintf("load the profile with `apparmor_parser -r <FILE>` (as root), use the default profile %q, "+
+		"or set `--security-opt apparmor=unconfined`", defaults.AppArmorProfileName)
+	if profile == defaults.AppArmorProfileName {
+		hint = "load the profile with `sudo nerdctl apparmor load`"
+	} else if profile == "docker-default" {
+		hint = fmt.Sprintf("the profile is loaded by Docker, use the equivalent default profile %q of nerdctl instead", defaults.AppArmorProfileName)
+	}
+	return fmt.Errorf("AppArmor profile %q is not loaded on the host (Hint: %s)", profile, hint)
+}
+
+// generateMaskedPathsOpts removes the paths of `--unmask` from the default masked paths, and then adds the paths of `--mask`.
+// `--unmask ALL` removes all the default masked paths.
+func generateMaskedPathsOpts(mask, unmask []string) ([]oci.SpecOpts, error) {
+	if len(mask) == 0 && len(unmask) == 0 {
+		return nil, nil
+	}
+	for _, p := range mask {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("invalid --mask %q: must be an absolute path", p)
+		}
+	}
+	unmaskAll := strutil.InStringSlice(unmask, "ALL")
+	unmaskSet := make(map[string]struct{})
+	for _, p := range unmask {
+		if p == "ALL" {
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("invalid --unmask %q: must be an absolute path or \"ALL\"", p)
+		}
+		unmaskSet[filepath.Clean(p)] = struct{}{}
+	}
+	return []oci.SpecOpts{func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		var masked []string
+		if !unmaskAll {
+			for _, p := range s.Linux.MaskedPaths {
+				if _, ok := unmaskSet[filepath.Clean(p)]; !ok {
+					masked = append(masked, p)
+				}
+			}
+		}
+		for _, p := range mask {
+			if p = filepath.Clean(p); !strutil.InStringSlice(masked, p) {
+				masked = append(masked, p)
+			}
+		}
+		s.Linux.MaskedPaths = masked
+		return nil
+	}}, nil
+}
+
+// generateReadonlyPathsOpts clears the default readonly paths on `--no-readonly-paths ALL`, and then adds the paths of `--readonly-paths`.
+func generateReadonlyPathsOpts(readonlyPaths, noReadonlyPaths []string) ([]oci.SpecOpts, error) {
+	if len(readonlyPaths) == 0 && len(noReadonlyPaths) == 0 {
+		return nil, nil
+	}
+	for _, p := range readonlyPaths {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("invalid --readonly-paths %q: must be an absolute path", p)
+		}
+	}
+	for _, p := range noReadonlyPaths {
+		if p != "ALL" {
+			return nil, fmt.Errorf("invalid --no-readonly-paths %q: only \"ALL\" is supported", p)
+		}
+	}
+	clearDefaults := len(noReadonlyPaths) > 0
+	return []oci.SpecOpts{func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil {
+			s.Linux = &specs.Linux{}
+		}
+		var readonly []string
+		if !clearDefaults {
+			readonly = s.Linux.ReadonlyPaths
+		}
+		for _, p := range readonlyPaths {
+			if p = filepath.Clean(p); !strutil.InStringSlice(readonly, p) {
+				readonly = append(readonly, p)
+			}
+		}
+		s.Linux.ReadonlyPaths = readonly
+		return nil
+	}}, nil
+}
+
+// isCapAdded returns whether capName (e.g., "CAP_NET_RAW") is added by capAdd (`--cap-add`), including `--cap-add ALL`.
+func isCapAdded(capAdd []string, capName string) bool {
+	for _, c := range capAdd {
+		if strings.ToUpper(c) == "ALL" || canonicalizeCapName(c) == capName {
+			return true
+		}
+	}
+	return false
+}
+
+// customSeccompProfile returns the profile of `--security-opt seccomp=<FILE>`, or "" for the default profile.
+func customSeccompProfile(securityOptsMap map[string]string) string {
+	profile := securityOptsMap["seccomp"]
+	if profile == defaults.SeccompProfileName {
+		return ""
+	}
+	return profile
+}
+
+// rawSocketFamilies are the address families of the raw sockets permitted by CAP_NET_RAW.
+var rawSocketFamilies = []uint64{unix.AF_INET, unix.AF_INET6, unix.AF_PACKET}
+
+// withNetRawSeccomp ensures that the seccomp profile allows the socket(2) calls for the raw sockets, when CAP_NET_RAW is in the bounding set,
+// so that `--cap-add NET_RAW` does not end up with the capability but without the raw sockets.
+// A custom profile (`--security-opt seccomp=<FILE>`) is not modified, but a warning is printed when it blocks them.
+func withNetRawSeccomp(customProfile string) oci.SpecOpts {
+	return func(ctx context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if s.Linux == nil || s.Linux.Seccomp == nil || s.Process == nil || s.Process.Capabilities == nil ||
+			!strutil.InStringSlice(s.Process.Capabilities.Bounding, "CAP_NET_RAW") {
+			return nil
+		}
+		for _, family := range rawSocketFamilies {
+			if seccompAllowsSocket(s.Linux.Seccomp, family) {
+				continue
+			}
+			if customProfile != "" {
+				log.G(ctx).Warnf("the seccomp profile %q blocks socket(2) for the address family %d, so the raw sockets may fail despite CAP_NET_RAW", customProfile, family)
+				continue
+			}
+			s.Linux.Seccomp.Syscalls = append(s.Linux.Seccomp.Syscalls, specs.LinuxSyscall{
+				Names:  []string{"socket"},
+				Action: specs.ActAllow,
+				Args:   []specs.LinuxSeccompArg{{Index: 0, Value: family, Op: specs.OpEqualTo}},
+			})
+		}
+		return nil
+	}
+}
+
+// seccompAllowsSocket returns whether the seccomp profile allows socket(2) for the address family.
+// The rules conditioned on the other arguments than the family are not considered as allowing it.
+func seccompAllowsSocket(sc *specs.LinuxSeccomp, family uint64) bool {
+	allowed := sc.DefaultAction == specs.ActAllow
+	for _, sys := range sc.Syscalls {
+		if !strutil.InStringSlice(sys.Names, "socket") {
+			continue
+		}
+		matched := true
+		for _, arg := range sys.Args {
+			if arg.Index != 0 {
+				matched = false
+				break
+			}
+			switch arg.Op {
+			case specs.OpEqualTo:
+				matched = matched && family == arg.Value
+			case specs.OpNotEqual:
+				matched = matched && family != arg.Value
+			case specs.OpMaskedEqual:
+				matched = matched && family&arg.Value == arg.ValueTwo
+			default:
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+		if sys.Action != specs.ActAllow && sys.Action != specs.ActLog {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
+
 func canonicalizeCapName(s string) string {
 	if s == "" {
 		return ""
@@ -126,10 +317,7 @@ func canonicalizeCapName(s string) string {
 	if !strings.HasPrefix(s, "CAP_") {
 		s = "CAP_" + s
 	}
-	if !isKnownCapName(s) {
-		log.L.Warnf("unknown capability name %q", s)
-		// Not a fatal error, because runtime might be aware of this cap
-	}
+	// Unknown capability names are rejected by withSpecValidation
 	return s
 }
 
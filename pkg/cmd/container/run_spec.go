@@ -0,0 +1,104 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/oci"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// withSpecValidation returns a SpecOpts that validates the generated spec,
+// so that an invalid spec fails with an error pointing to the invalid field,
+// rather than with a cryptic error from the OCI runtime.
+//
+// withSpecValidation has to be the last SpecOpts.
+func withSpecValidation() oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		if err := validateSpec(s); err != nil {
+			return fmt.Errorf("invalid OCI runtime spec: %w", err)
+		}
+		return nil
+	}
+}
+
+// withReadonlyPathsValidation returns a SpecOpts that validates that the paths of `--readonly-paths` are not
+// the destinations of read-write mounts, as a readonly path is remounted read-only by the runtime.
+// The default readonly paths (e.g., /proc/sys) are not validated, as a mount on them replaces them.
+//
+// withReadonlyPathsValidation has to be after the SpecOpts of the mounts.
+func withReadonlyPathsValidation(readonlyPaths []string) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		for _, p := range readonlyPaths {
+			for _, m := range s.Mounts {
+				if filepath.Clean(m.Destination) == filepath.Clean(p) && !strutil.InStringSlice(m.Options, "ro") {
+					return fmt.Errorf("--readonly-paths %q conflicts with the read-write mount on it", p)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// validateSpec validates the spec against the version of the OCI Runtime Specification
+// supported by nerdctl (and the OCI runtimes it is tested with).
+func validateSpec(s *specs.Spec) error {
+	v, err := semver.NewVersion(s.Version)
+	if err != nil {
+		return fmt.Errorf("ociVersion: invalid version %q: %w", s.Version, err)
+	}
+	if v.Major() != specs.VersionMajor || v.Minor() > specs.VersionMinor {
+		return fmt.Errorf("ociVersion: unsupported version %q (supported: %d.0.0 - %s)", s.Version, specs.VersionMajor, specs.Version)
+	}
+	if s.Process != nil && len(s.Process.Args) == 0 && s.Process.CommandLine == "" {
+		return fmt.Errorf("process.args: must not be empty")
+	}
+	return validatePlatformSpec(s)
+}
+
+// loadOCISpecFile loads the OCI runtime spec (config.json) for `nerdctl run --oci-spec-file`.
+func loadOCISpecFile(path string) (*specs.Spec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the OCI spec file: %w", err)
+	}
+	var s specs.Spec
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse the OCI spec file %q: %w", path, err)
+	}
+	if s.Root == nil {
+		// the snapshot of the image is mounted on "rootfs" in the bundle
+		s.Root = &specs.Root{Path: "rootfs"}
+	}
+	return &s, nil
+}
+
+// withOCISpec replaces the spec with `base`.
+func withOCISpec(base *specs.Spec) oci.SpecOpts {
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		*s = *base
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var validNamespaceTypes = map[specs.LinuxNamespaceType]struct{}{
+	specs.PIDNamespace:     {},
+	specs.NetworkNamespace: {},
+	specs.MountNamespace:   {},
+	specs.IPCNamespace:     {},
+	specs.UTSNamespace:     {},
+	specs.UserNamespace:    {},
+	specs.CgroupNamespace:  {},
+	specs.TimeNamespace:    {},
+}
+
+func validatePlatformSpec(s *specs.Spec) error {
+	if s.Process != nil {
+		if !filepath.IsAbs(s.Process.Cwd) {
+			return fmt.Errorf("process.cwd: %q is not an absolute path", s.Process.Cwd)
+		}
+		if c := s.Process.Capabilities; c != nil {
+			sets := []struct {
+				name string
+				caps []string
+			}{
+				{"bounding", c.Bounding},
+				{"effective", c.Effective},
+				{"inheritable", c.Inheritable},
+				{"permitted", c.Permitted},
+				{"ambient", c.Ambient},
+			}
+			for _, set := range sets {
+				for i, capName := range set.caps {
+					if !isKnownCapName(capName) {
+						return fmt.Errorf("process.capabilities.%s[%d]: unknown capability %q", set.name, i, capName)
+					}
+				}
+			}
+		}
+		for i, rlimit := range s.Process.Rlimits {
+			if !strings.HasPrefix(rlimit.Type, "RLIMIT_") {
+				return fmt.Errorf("process.rlimits[%d].type: invalid rlimit type %q", i, rlimit.Type)
+			}
+		}
+	}
+	for i, m := range s.Mounts {
+		if !filepath.IsAbs(m.Destination) {
+			return fmt.Errorf("mounts[%d].destination: %q is not an absolute path", i, m.Destination)
+		}
+	}
+	if s.Linux != nil {
+		seenNamespaces := make(map[specs.LinuxNamespaceType]struct{})
+		for i, ns := range s.Linux.Namespaces {
+			if _, ok := validNamespaceTypes[ns.Type]; !ok {
+				return fmt.Errorf("linux.namespaces[%d].type: invalid namespace type %q", i, ns.Type)
+			}
+			if _, ok := seenNamespaces[ns.Type]; ok {
+				return fmt.Errorf("linux.namespaces[%d].type: duplicated namespace type %q", i, ns.Type)
+			}
+			seenNamespaces[ns.Type] = struct{}{}
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package container
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/containerd/containerd/containers"
@@ -27,27 +28,61 @@ import (
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
+// generateUlimitsOpts generates the rlimits of `ulimits`.
+// The last one wins for the same type, e.g., `--ulimit nofile=1024 --ulimit nofile=2048` sets nofile to 2048.
 func generateUlimitsOpts(ulimits []string) ([]oci.SpecOpts, error) {
 	var opts []oci.SpecOpts
 	ulimits = strutil.DedupeStrSlice(ulimits)
 	if len(ulimits) > 0 {
 		var rlimits []specs.POSIXRlimit
+		// the index of each type in rlimits
+		indexes := make(map[string]int)
 		for _, ulimit := range ulimits {
-			l, err := units.ParseUlimit(ulimit)
+			l, err := parseUlimit(ulimit)
 			if err != nil {
 				return nil, err
 			}
-			rlimits = append(rlimits, specs.POSIXRlimit{
+			rlimit := specs.POSIXRlimit{
 				Type: "RLIMIT_" + strings.ToUpper(l.Name),
 				Hard: uint64(l.Hard),
 				Soft: uint64(l.Soft),
-			})
+			}
+			if i, ok := indexes[rlimit.Type]; ok {
+				rlimits[i] = rlimit
+				continue
+			}
+			indexes[rlimit.Type] = len(rlimits)
+			rlimits = append(rlimits, rlimit)
 		}
 		opts = append(opts, withRlimits(rlimits))
 	}
 	return opts, nil
 }
 
+// parseUlimit parses "name=soft[:hard]" like units.ParseUlimit, additionally
+// accepting "unlimited" (or -1) as a limit value.
+// When only the soft limit is specified, the hard limit is set to the same value.
+func parseUlimit(val string) (*units.Ulimit, error) {
+	name, limits, ok := strings.Cut(val, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid ulimit argument %q: expected name=soft[:hard]", val)
+	}
+	if _, err := units.ParseUlimit(name + "=0"); err != nil {
+		return nil, fmt.Errorf("invalid ulimit name %q in %q", name, val)
+	}
+	vals := strings.Split(limits, ":")
+	for i, v := range vals {
+		if v == "unlimited" {
+			vals[i] = "-1"
+		}
+	}
+	l, err := units.ParseUlimit(name + "=" + strings.Join(vals, ":"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ulimit %q: %w", val, err)
+	}
+	return l, nil
+}
+
 func withRlimits(rlimits []specs.POSIXRlimit) oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
 		s.Process.Rlimits = rlimits
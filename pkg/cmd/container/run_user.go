@@ -19,12 +19,24 @@ package container
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/containerd/containerd/containers"
 	"github.com/containerd/containerd/oci"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
+// keepGroups is the special value of `--group-add` for keeping the supplementary
+// groups of the user running nerdctl (compatible with Podman).
+const keepGroups = "keep-groups"
+
+// keepOriginalGroupsAnnotation makes crun keep the supplementary groups of the process that starts the container,
+// including the groups that are not mapped into the user namespace of rootless mode (used by Podman too).
+const keepOriginalGroupsAnnotation = "run.oci.keep_original_groups"
+
 func generateUserOpts(user string) ([]oci.SpecOpts, error) {
 	var opts []oci.SpecOpts
 	if user != "" {
@@ -46,15 +58,73 @@ func generateUmaskOpts(umask string) ([]oci.SpecOpts, error) {
 	return opts, nil
 }
 
-func generateGroupsOpts(groups []string) ([]oci.SpecOpts, error) {
+func generateGroupsOpts(groups []string, groupFile, stateDir, runtimeStr string) ([]oci.SpecOpts, error) {
 	var opts []oci.SpecOpts
 
-	if len(groups) != 0 {
+	if strutil.InStringSlice(groups, keepGroups) {
+		if len(groups) > 1 {
+			return nil, fmt.Errorf("the special group %q cannot be combined with other groups", keepGroups)
+		}
+		switch {
+		case isCrun(runtimeStr):
+			opts = append(opts, oci.WithAnnotations(map[string]string{keepOriginalGroupsAnnotation: "1"}))
+		case rootlessutil.IsRootless():
+			// the supplementary groups of the host are not mapped into the user namespace, so they cannot be passed as GIDs
+			return nil, fmt.Errorf("the special group %q requires crun in rootless mode (Hint: specify `--runtime=crun`)", keepGroups)
+		default:
+			opt, err := withKeepGroups()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, opt)
+		}
+		groups = nil
+	}
+
+	if groupFile != "" {
+		opt, err := withGroupFile(groupFile, stateDir, groups)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	} else if len(groups) != 0 {
 		opts = append(opts, oci.WithAppendAdditionalGroups(groups...))
 	}
 	return opts, nil
 }
 
+// isCrun returns whether the runtime of `--runtime` is crun.
+func isCrun(runtimeStr string) bool {
+	return filepath.Base(runtimeStr) == "crun"
+}
+
+// withKeepGroups appends the supplementary groups of the current process to the additional GIDs.
+func withKeepGroups() (oci.SpecOpts, error) {
+	gids, err := os.Getgroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the supplementary groups: %w", err)
+	}
+	keep := make([]uint32, 0, len(gids))
+	for _, gid := range gids {
+		keep = append(keep, uint32(gid))
+	}
+	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
+		for _, gid := range keep {
+			found := false
+			for _, existing := range s.Process.User.AdditionalGids {
+				if existing == gid {
+					found = true
+					break
+				}
+			}
+			if !found {
+				s.Process.User.AdditionalGids = append(s.Process.User.AdditionalGids, gid)
+			}
+		}
+		return nil
+	}, nil
+}
+
 func withResetAdditionalGIDs() oci.SpecOpts {
 	return func(_ context.Context, _ oci.Client, _ *containers.Container, s *oci.Spec) error {
 		s.Process.User.AdditionalGids = nil
@@ -0,0 +1,398 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	osuser "os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/containers"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/containerd/oci"
+	"github.com/containerd/log"
+	"github.com/containerd/nerdctl/v2/pkg/lockutil"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/moby/sys/user"
+	"github.com/opencontainers/image-spec/identity"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	usernsHost   = "host"
+	usernsAuto   = "auto"
+	usernsKeepID = "keep-id"
+
+	// defaultUsernsAutoSize is the size of the ID range allocated for `--userns=auto`.
+	defaultUsernsAutoSize = 65536
+	// usernsAutoSubIDUser is the user whose subordinate IDs are preferred for `--userns=auto` (same as Podman).
+	usernsAutoSubIDUser = "containers"
+)
+
+// parseUserns parses the value of `--userns` and returns the mode, and the size for "auto".
+func parseUserns(s string) (mode string, size uint32, err error) {
+	mode, opts, _ := strings.Cut(s, ":")
+	switch mode {
+	case "", usernsHost, usernsKeepID:
+		if opts != "" {
+			return "", 0, fmt.Errorf("--userns=%s does not take options, got %q", mode, opts)
+		}
+		return mode, 0, nil
+	case usernsAuto:
+		size = defaultUsernsAutoSize
+		if opts == "" {
+			return mode, size, nil
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			k, v, _ := strings.Cut(opt, "=")
+			switch k {
+			case "size":
+				n, err := strconv.ParseUint(v, 10, 32)
+				if err != nil || n == 0 {
+					return "", 0, fmt.Errorf("invalid --userns=auto size %q", v)
+				}
+				size = uint32(n)
+			default:
+				return "", 0, fmt.Errorf("unknown --userns=auto option %q", k)
+			}
+		}
+		return mode, size, nil
+	default:
+		return "", 0, fmt.Errorf("invalid --userns %q, must be one of \"host\", \"auto[:size=N]\", or \"keep-id\"", s)
+	}
+}
+
+// lockUsernsAuto locks the user namespace allocation of the data store for `--userns=auto`,
+// so that concurrent containers do not get overlapping ranges.
+// The lock has to be held until the container is created, as the allocated ranges are only visible
+// to the others via the spec of the container.
+// For the other modes, the returned unlock func is a no-op.
+func lockUsernsAuto(dataStore, userns string) (unlock func(), err error) {
+	if mode, _, _ := strings.Cut(userns, ":"); mode != usernsAuto {
+		return func() {}, nil
+	}
+	dir := filepath.Join(dataStore, "userns")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	dirFile, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockutil.Flock(dirFile, unix.LOCK_EX); err != nil {
+		dirFile.Close()
+		return nil, fmt.Errorf("failed to lock %q: %w", dir, err)
+	}
+	return func() {
+		if err := lockutil.Flock(dirFile, unix.LOCK_UN); err != nil {
+			log.L.WithError(err).Errorf("failed to unlock %q", dir)
+		}
+		dirFile.Close()
+	}, nil
+}
+
+// generateUsernsOpts returns the spec opts for `--userns`, and the opt to create the rootfs snapshot
+// with the ownership remapped to the user namespace.
+// The snapshot opt is nil when the container does not have its own user namespace.
+func generateUsernsOpts(ctx context.Context, client *containerd.Client, id, snapshotter string, image containerd.Image, userns string) ([]oci.SpecOpts, containerd.NewContainerOpts, error) {
+	mode, size, err := parseUserns(userns)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		opts             []oci.SpecOpts
+		uidMaps, gidMaps []specs.LinuxIDMapping
+	)
+	switch mode {
+	case "", usernsHost:
+		return nil, nil, nil
+	case usernsAuto:
+		if rootlessutil.IsRootless() {
+			return nil, nil, errors.New("--userns=auto is not supported in rootless mode, use --userns=keep-id")
+		}
+		uidMaps, gidMaps, err = allocateUsernsAuto(ctx, client, size)
+		if err != nil {
+			return nil, nil, err
+		}
+	case usernsKeepID:
+		if !rootlessutil.IsRootless() {
+			return nil, nil, errors.New("--userns=keep-id is only supported in rootless mode")
+		}
+		uid, gid := uint32(rootlessutil.ParentEUID()), uint32(rootlessutil.ParentEGID())
+		uidMaps, err = keepIDMappings(uid, "/proc/self/uid_map")
+		if err != nil {
+			return nil, nil, err
+		}
+		gidMaps, err = keepIDMappings(gid, "/proc/self/gid_map")
+		if err != nil {
+			return nil, nil, err
+		}
+		// The process runs as the user of nerdctl, unless `--user` is specified
+		opts = append(opts, oci.WithUIDGID(uid, gid))
+	}
+	log.G(ctx).Debugf("user namespace of container %q: uid mappings %+v, gid mappings %+v", id, uidMaps, gidMaps)
+	opts = append([]oci.SpecOpts{oci.WithUserNamespace(uidMaps, gidMaps)}, opts...)
+	if image == nil {
+		// --rootfs is used as-is
+		return opts, nil, nil
+	}
+	return opts, withUsernsRemappedSnapshot(id, snapshotter, image, uidMaps, gidMaps), nil
+}
+
+// keepIDMappings maps `id` to the same ID in the container, using the IDs of the user namespace of nerdctl
+// described by `mapFile` (e.g., /proc/self/uid_map).
+// In rootless mode, the user of nerdctl is 0 in that namespace, and the subordinate IDs are 1 and later.
+func keepIDMappings(id uint32, mapFile string) ([]specs.LinuxIDMapping, error) {
+	idMaps, err := user.ParseIDMapFile(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, m := range idMaps {
+		if end := m.ID + m.Count; end > total {
+			total = end
+		}
+	}
+	if int64(id) >= total {
+		return nil, fmt.Errorf("--userns=keep-id: ID %d does not fit in the %d IDs of %s, check /etc/subuid and /etc/subgid", id, total, mapFile)
+	}
+	return keepIDMappingsN(id, uint32(total)), nil
+}
+
+// keepIDMappingsN maps `id` in the container to 0 in the parent namespace of `total` IDs,
+// and the other IDs of the container to 1 and later.
+func keepIDMappingsN(id, total uint32) []specs.LinuxIDMapping {
+	var maps []specs.LinuxIDMapping
+	if id > 0 {
+		maps = append(maps, specs.LinuxIDMapping{ContainerID: 0, HostID: 1, Size: id})
+	}
+	maps = append(maps, specs.LinuxIDMapping{ContainerID: id, HostID: 0, Size: 1})
+	if rest := total - id - 1; rest > 0 {
+		maps = append(maps, specs.LinuxIDMapping{ContainerID: id + 1, HostID: id + 1, Size: rest})
+	}
+	return maps
+}
+
+// allocateUsernsAuto allocates ranges of `size` IDs from /etc/subuid and /etc/subgid that are not
+// used by the user namespace of any other container.
+func allocateUsernsAuto(ctx context.Context, client *containerd.Client, size uint32) ([]specs.LinuxIDMapping, []specs.LinuxIDMapping, error) {
+	usedUIDs, usedGIDs, err := usedUsernsRanges(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	uidStart, err := allocateSubIDRange("/etc/subuid", size, usedUIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	gidStart, err := allocateSubIDRange("/etc/subgid", size, usedGIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []specs.LinuxIDMapping{{ContainerID: 0, HostID: uidStart, Size: size}},
+		[]specs.LinuxIDMapping{{ContainerID: 0, HostID: gidStart, Size: size}}, nil
+}
+
+// usedUsernsRanges returns the host ID ranges mapped by the containers of all the namespaces.
+func usedUsernsRanges(ctx context.Context, client *containerd.Client) (uids, gids []specs.LinuxIDMapping, err error) {
+	nsList, err := client.NamespaceService().List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, ns := range nsList {
+		containerList, err := client.ContainerService().List(namespaces.WithNamespace(ctx, ns))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range containerList {
+			if c.Spec == nil {
+				continue
+			}
+			var s specs.Spec
+			if err := json.Unmarshal(c.Spec.GetValue(), &s); err != nil {
+				log.G(ctx).WithError(err).Debugf("failed to unmarshal the spec of container %q", c.ID)
+				continue
+			}
+			if s.Linux != nil {
+				uids = append(uids, s.Linux.UIDMappings...)
+				gids = append(gids, s.Linux.GIDMappings...)
+			}
+		}
+	}
+	return uids, gids, nil
+}
+
+// allocateSubIDRange returns the start of the first range of `size` IDs in the subordinate ID file
+// that does not overlap with `used`.
+// The entries of the "containers" user are used if any, otherwise the entries of the current user.
+func allocateSubIDRange(subIDFile string, size uint32, used []specs.LinuxIDMapping) (uint32, error) {
+	subIDName := usernsAutoSubIDUser
+	subIDs, err := user.ParseSubIDFileFilter(subIDFile, func(e user.SubID) bool { return e.Name == subIDName })
+	if err != nil {
+		return 0, err
+	}
+	if len(subIDs) == 0 {
+		cur, err := osuser.Current()
+		if err != nil {
+			return 0, err
+		}
+		subIDName = cur.Username
+		subIDs, err = user.ParseSubIDFileFilter(subIDFile, func(e user.SubID) bool {
+			return e.Name == cur.Username || e.Name == cur.Uid
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(subIDs) == 0 {
+		return 0, fmt.Errorf("--userns=auto: no entry for %q nor %q in %s", usernsAutoSubIDUser, subIDName, subIDFile)
+	}
+	start, ok := findFreeIDRange(subIDs, size, used)
+	if !ok {
+		return 0, fmt.Errorf("--userns=auto: no free range of %d IDs for %q in %s", size, subIDName, subIDFile)
+	}
+	return start, nil
+}
+
+// findFreeIDRange finds the first range of `size` IDs in `subIDs` that does not overlap with the host IDs of `used`.
+func findFreeIDRange(subIDs []user.SubID, size uint32, used []specs.LinuxIDMapping) (uint32, bool) {
+	sort.Slice(used, func(i, j int) bool { return used[i].HostID < used[j].HostID })
+	for _, s := range subIDs {
+		start, end := s.SubID, s.SubID+s.Count
+		for _, u := range used {
+			uStart, uEnd := int64(u.HostID), int64(u.HostID)+int64(u.Size)
+			if uEnd <= start || uStart >= start+int64(size) {
+				continue
+			}
+			// overlapping, so try just after the used range
+			start = uEnd
+		}
+		if start+int64(size) <= end && start+int64(size) <= 1<<32 {
+			return uint32(start), true
+		}
+	}
+	return 0, false
+}
+
+// idMappingToHost translates `id` in the container to the host ID.
+// The second return value is false when `id` is not mapped.
+func idMappingToHost(maps []specs.LinuxIDMapping, id uint32) (uint32, bool) {
+	for _, m := range maps {
+		if id >= m.ContainerID && id-m.ContainerID < m.Size {
+			return m.HostID + (id - m.ContainerID), true
+		}
+	}
+	return 0, false
+}
+
+// withUsernsRemappedSnapshot is like containerd.WithRemappedSnapshot, but supports arbitrary mappings
+// (e.g., --userns=keep-id) rather than a single offset.
+// The remapped snapshot is committed with a key derived from the mappings, so it can be shared across containers.
+func withUsernsRemappedSnapshot(id, snapshotter string, image containerd.Image, uidMaps, gidMaps []specs.LinuxIDMapping) containerd.NewContainerOpts {
+	return func(ctx context.Context, client *containerd.Client, c *containers.Container) error {
+		diffIDs, err := image.RootFS(ctx)
+		if err != nil {
+			return err
+		}
+		mapsJSON, err := json.Marshal([][]specs.LinuxIDMapping{uidMaps, gidMaps})
+		if err != nil {
+			return err
+		}
+		var (
+			parent   = identity.ChainID(diffIDs).String()
+			usernsID = fmt.Sprintf("%s-userns-%x", parent, sha256.Sum256(mapsJSON))
+			sn       = client.SnapshotService(snapshotter)
+		)
+		if _, err := sn.Stat(ctx, usernsID); err != nil {
+			if !errdefs.IsNotFound(err) {
+				return err
+			}
+			mounts, err := sn.Prepare(ctx, usernsID+"-remap", parent)
+			if err != nil {
+				return err
+			}
+			if err := mount.WithTempMount(ctx, mounts, func(root string) error {
+				return filepath.Walk(root, remapOwnership(uidMaps, gidMaps))
+			}); err != nil {
+				sn.Remove(ctx, usernsID+"-remap")
+				return err
+			}
+			if err := sn.Commit(ctx, usernsID, usernsID+"-remap"); err != nil {
+				return err
+			}
+		}
+		if _, err := sn.Prepare(ctx, id, usernsID); err != nil {
+			return err
+		}
+		c.Snapshotter = snapshotter
+		c.SnapshotKey = id
+		c.Image = image.Name()
+		return nil
+	}
+}
+
+// remapOwnership chowns the files to the host IDs of the mappings.
+// The files owned by unmapped IDs are left as they are, and appear as the overflow ID in the container.
+func remapOwnership(uidMaps, gidMaps []specs.LinuxIDMapping) filepath.WalkFunc {
+	// the hard links share the inode, which must be remapped only once
+	type inode struct {
+		dev, ino uint64
+	}
+	seen := make(map[inode]struct{})
+	return func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		stat := info.Sys().(*syscall.Stat_t)
+		if !info.IsDir() && stat.Nlink > 1 {
+			key := inode{dev: uint64(stat.Dev), ino: stat.Ino}
+			if _, ok := seen[key]; ok {
+				return nil
+			}
+			seen[key] = struct{}{}
+		}
+		uid, ok := idMappingToHost(uidMaps, stat.Uid)
+		if !ok {
+			uid = stat.Uid
+		}
+		gid, ok := idMappingToHost(gidMaps, stat.Gid)
+		if !ok {
+			gid = stat.Gid
+		}
+		// lchown, so as not to dereference the symlinks to the host files
+		if err := os.Lchown(path, int(uid), int(gid)); err != nil {
+			return err
+		}
+		// chown clears the setuid and setgid bits of the files
+		if info.Mode()&(os.ModeSetuid|os.ModeSetgid) != 0 && info.Mode()&os.ModeSymlink == 0 {
+			return os.Chmod(path, info.Mode())
+		}
+		return nil
+	}
+}
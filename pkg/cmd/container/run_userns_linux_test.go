@@ -0,0 +1,179 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/moby/sys/user"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseUserns(t *testing.T) {
+	testCases := []struct {
+		input  string
+		mode   string
+		size   uint32
+		errMsg string
+	}{
+		{input: "", mode: ""},
+		{input: "host", mode: usernsHost},
+		{input: "keep-id", mode: usernsKeepID},
+		{input: "auto", mode: usernsAuto, size: defaultUsernsAutoSize},
+		{input: "auto:size=1024", mode: usernsAuto, size: 1024},
+		{input: "auto:size=0", errMsg: "invalid --userns=auto size"},
+		{input: "auto:size=foo", errMsg: "invalid --userns=auto size"},
+		{input: "auto:size=4294967296", errMsg: "invalid --userns=auto size"},
+		{input: "auto:foo=1", errMsg: "unknown --userns=auto option"},
+		{input: "host:size=1024", errMsg: "does not take options"},
+		{input: "keep-id:size=1024", errMsg: "does not take options"},
+		{input: "private", errMsg: "invalid --userns"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			mode, size, err := parseUserns(tc.input)
+			if tc.errMsg != "" {
+				assert.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, mode, tc.mode)
+			assert.Equal(t, size, tc.size)
+		})
+	}
+}
+
+func TestFindFreeIDRange(t *testing.T) {
+	subIDs := []user.SubID{{Name: "containers", SubID: 100000, Count: 65536 * 2}}
+	testCases := []struct {
+		name   string
+		subIDs []user.SubID
+		size   uint32
+		used   []specs.LinuxIDMapping
+		start  uint32
+		ok     bool
+	}{
+		{
+			name:   "no used ranges",
+			subIDs: subIDs,
+			size:   65536,
+			start:  100000,
+			ok:     true,
+		},
+		{
+			name:   "used ranges outside of the subordinate IDs",
+			subIDs: subIDs,
+			size:   65536,
+			used:   []specs.LinuxIDMapping{{HostID: 0, Size: 100000}, {HostID: 231072, Size: 65536}},
+			start:  100000,
+			ok:     true,
+		},
+		{
+			name:   "after the overlapping range",
+			subIDs: subIDs,
+			size:   65536,
+			used:   []specs.LinuxIDMapping{{HostID: 100000, Size: 65536}},
+			start:  165536,
+			ok:     true,
+		},
+		{
+			name:   "after the partially overlapping ranges in any order",
+			subIDs: subIDs,
+			size:   1000,
+			used:   []specs.LinuxIDMapping{{HostID: 100500, Size: 1000}, {HostID: 99000, Size: 1500}},
+			start:  101500,
+			ok:     true,
+		},
+		{
+			name:   "exhausted",
+			subIDs: subIDs,
+			size:   65536,
+			used:   []specs.LinuxIDMapping{{HostID: 100000, Size: 65536}, {HostID: 165536, Size: 1}},
+			ok:     false,
+		},
+		{
+			name:   "larger than the subordinate IDs",
+			subIDs: subIDs,
+			size:   65536*2 + 1,
+			ok:     false,
+		},
+		{
+			name: "from the next entry",
+			subIDs: []user.SubID{
+				{Name: "containers", SubID: 100000, Count: 65536},
+				{Name: "containers", SubID: 300000, Count: 65536},
+			},
+			size:  65536,
+			used:  []specs.LinuxIDMapping{{HostID: 100000, Size: 1}},
+			start: 300000,
+			ok:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, ok := findFreeIDRange(tc.subIDs, tc.size, tc.used)
+			assert.Equal(t, ok, tc.ok)
+			if tc.ok {
+				assert.Equal(t, start, tc.start)
+			}
+		})
+	}
+}
+
+func TestKeepIDMappingsN(t *testing.T) {
+	testCases := []struct {
+		name     string
+		id       uint32
+		total    uint32
+		expected []specs.LinuxIDMapping
+	}{
+		{
+			name:  "root",
+			id:    0,
+			total: 65537,
+			expected: []specs.LinuxIDMapping{
+				{ContainerID: 0, HostID: 0, Size: 1},
+				{ContainerID: 1, HostID: 1, Size: 65536},
+			},
+		},
+		{
+			name:  "middle",
+			id:    1000,
+			total: 65537,
+			expected: []specs.LinuxIDMapping{
+				{ContainerID: 0, HostID: 1, Size: 1000},
+				{ContainerID: 1000, HostID: 0, Size: 1},
+				{ContainerID: 1001, HostID: 1001, Size: 64536},
+			},
+		},
+		{
+			name:  "last",
+			id:    65536,
+			total: 65537,
+			expected: []specs.LinuxIDMapping{
+				{ContainerID: 0, HostID: 1, Size: 65536},
+				{ContainerID: 65536, HostID: 0, Size: 1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, keepIDMappingsN(tc.id, tc.total), tc.expected)
+		})
+	}
+}
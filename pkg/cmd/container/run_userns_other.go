@@ -0,0 +1,40 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/oci"
+)
+
+func lockUsernsAuto(dataStore, userns string) (func(), error) {
+	return func() {}, nil
+}
+
+func generateUsernsOpts(ctx context.Context, client *containerd.Client, id, snapshotter string, image containerd.Image, userns string) ([]oci.SpecOpts, containerd.NewContainerOpts, error) {
+	switch userns {
+	case "", "host":
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("--userns=%s is only supported on Linux", userns)
+	}
+}
@@ -17,12 +17,14 @@
 package image
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	overlaybdconvert "github.com/containerd/accelerated-container-image/pkg/convertor"
@@ -38,6 +40,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	nydusconvert "github.com/containerd/nydus-snapshotter/pkg/converter"
+	"github.com/containerd/platforms"
 	"github.com/containerd/stargz-snapshotter/estargz"
 	estargzconvert "github.com/containerd/stargz-snapshotter/nativeconverter/estargz"
 	estargzexternaltocconvert "github.com/containerd/stargz-snapshotter/nativeconverter/estargz/externaltoc"
@@ -101,6 +104,10 @@ func Convert(ctx context.Context, client *containerd.Client, srcRawRef, targetRa
 			return errors.New("options --estargz, --zstdchunked, --overlaybd and --nydus lead to conflict, only one of them can be used")
 		}
 
+		if err := validateCompressionLevel(options); err != nil {
+			return err
+		}
+
 		var convertFunc converter.ConvertFunc
 		var convertType string
 		switch {
@@ -186,6 +193,13 @@ func Convert(ctx context.Context, client *containerd.Client, srcRawRef, targetRa
 	if err != nil {
 		return err
 	}
+	if len(options.Platforms) == 1 && !options.AllPlatforms && images.IsIndexType(newImg.Target.MediaType) {
+		// A single platform was requested, so the target image points at the manifest directly, not at the index
+		newImg, err = unwrapIndex(ctx, client, *newImg, platMC)
+		if err != nil {
+			return err
+		}
+	}
 	res := converterutil.ConvertedImageInfo{
 		Image: newImg.Name + "@" + newImg.Target.Digest.String(),
 	}
@@ -210,6 +224,61 @@ func Convert(ctx context.Context, client *containerd.Client, srcRawRef, targetRa
 	return printConvertedImage(options.Stdout, options, res)
 }
 
+// unwrapIndex updates the target of `img` from the index to the manifest that best matches `platMC`.
+func unwrapIndex(ctx context.Context, client *containerd.Client, img images.Image, platMC platforms.MatchComparer) (*images.Image, error) {
+	b, err := content.ReadBlob(ctx, client.ContentStore(), img.Target)
+	if err != nil {
+		return nil, err
+	}
+	var idx ocispec.Index
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return nil, err
+	}
+	var manifests []ocispec.Descriptor
+	for _, desc := range idx.Manifests {
+		if desc.Platform != nil && platMC.Match(*desc.Platform) {
+			manifests = append(manifests, desc)
+		}
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no manifest found for the specified platform in image %q", img.Name)
+	}
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return platMC.Less(*manifests[i].Platform, *manifests[j].Platform)
+	})
+	img.Target = manifests[0]
+	updated, err := client.ImageService().Update(ctx, img, "target")
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// validateCompressionLevel checks the compression level against the valid range of the algorithm of the chosen mode.
+func validateCompressionLevel(options types.ImageConvertOptions) error {
+	check := func(mode string, level, min, max int) error {
+		if level < min || level > max {
+			return fmt.Errorf("invalid compression level %d for --%s (must be between %d and %d)", level, mode, min, max)
+		}
+		return nil
+	}
+	switch {
+	case options.Estargz:
+		return check("estargz", options.EstargzCompressionLevel, gzip.HuffmanOnly, gzip.BestCompression)
+	case options.Zstd:
+		return check("zstd", options.ZstdCompressionLevel, zstdMinCompressionLevel, zstdMaxCompressionLevel)
+	case options.ZstdChunked:
+		return check("zstdchunked", options.ZstdChunkedCompressionLevel, zstdMinCompressionLevel, zstdMaxCompressionLevel)
+	}
+	return nil
+}
+
+// The range of the zstd compression levels, as in the zstd CLI (`--ultra` for 20-22).
+const (
+	zstdMinCompressionLevel = 1
+	zstdMaxCompressionLevel = 22
+)
+
 func getESGZConverter(options types.ImageConvertOptions) (convertFunc converter.ConvertFunc, finalize func(ctx context.Context, cs content.Store, ref string, desc *ocispec.Descriptor) (*images.Image, error), _ error) {
 	if options.EstargzExternalToc && !options.GOptions.Experimental {
 		return nil, nil, fmt.Errorf("estargz-external-toc requires experimental mode to be enabled")
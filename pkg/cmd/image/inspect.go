@@ -17,21 +17,46 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	refdocker "github.com/containerd/containerd/reference/docker"
 	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/imageinspector"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	"github.com/containerd/platforms"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// imageIDPattern matches an image ID or its prefix, with or without the "sha256:" algorithm.
+var imageIDPattern = regexp.MustCompile(`^(sha256:)?[a-f0-9]{1,64}$`)
+
+// isImageIDOrDigest returns whether req is an image ID (or its prefix) or a digest rather than a reference.
+func isImageIDOrDigest(req string) bool {
+	return imageIDPattern.MatchString(req)
+}
+
 // Inspect prints detailed information of each image in `images`.
 func Inspect(ctx context.Context, client *containerd.Client, images []string, options types.ImageInspectOptions) error {
+	if options.Diff {
+		return inspectDiff(ctx, client, images, options)
+	}
+	if options.ConfigOnly {
+		return inspectConfigOnly(ctx, client, images, options)
+	}
 	f := &imageInspector{
 		mode: options.Mode,
 	}
@@ -41,10 +66,44 @@ func Inspect(ctx context.Context, client *containerd.Client, images []string, op
 			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 
+			if options.Platforms {
+				p, err := inspectPlatforms(ctx, client, found.Image)
+				if err != nil {
+					return err
+				}
+				f.entries = append(f.entries, p)
+				return nil
+			}
+
 			n, err := imageinspector.Inspect(ctx, client, found.Image, options.GOptions.Snapshotter)
 			if err != nil {
 				return err
 			}
+			if options.ShowLease {
+				imgLeases, err := imageLeases(ctx, client, found.Image)
+				if err != nil {
+					return err
+				}
+				n.Leases = &imgLeases
+			}
+			if options.DownloadSizes {
+				if n.Manifest == nil {
+					return fmt.Errorf("image %q does not have a manifest for the platform, the download sizes are unknown", found.Req)
+				}
+				n.DownloadSizes = downloadSizes(n.Manifest)
+			}
+			if options.FollowIndex && n.IndexDesc != nil {
+				platform := options.Platform
+				if platform == "" {
+					platform = platforms.DefaultString()
+				}
+				if n.ManifestDesc == nil {
+					return fmt.Errorf("image %q does not contain a manifest for platform %q", found.Req, platform)
+				}
+				if n.Platform != nil {
+					log.G(ctx).Infof("resolved the index of image %q to platform %q", found.Req, platforms.Format(*n.Platform))
+				}
+			}
 			switch f.mode {
 			case "native":
 				f.entries = append(f.entries, n)
@@ -53,6 +112,10 @@ func Inspect(ctx context.Context, client *containerd.Client, images []string, op
 				if err != nil {
 					return err
 				}
+				if formatter.IsTableFormat(options.Format) {
+					f.entries = append(f.entries, newImageInspectTableRow(d))
+					return nil
+				}
 				f.entries = append(f.entries, d)
 			default:
 				return fmt.Errorf("unknown mode %q", f.mode)
@@ -61,16 +124,212 @@ func Inspect(ctx context.Context, client *containerd.Client, images []string, op
 		},
 	}
 
-	err := walker.WalkAll(ctx, images, true)
+	// A malformed reference is reported as such, not as "no such image"
+	var (
+		reqs      []string
+		parseErrs []error
+	)
+	for _, req := range images {
+		if isImageIDOrDigest(req) {
+			// ParseDockerRef rejects a 64-hex ID and a "sha256:" digest, which are matched by the walker
+			reqs = append(reqs, req)
+			continue
+		}
+		if _, err := refdocker.ParseDockerRef(req); err != nil {
+			parseErrs = append(parseErrs, fmt.Errorf("invalid reference format %q: %w", req, err))
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+
+	var err error
+	if len(reqs) > 0 {
+		err = walker.WalkAll(ctx, reqs, true)
+	}
+	if len(parseErrs) > 0 {
+		err = errors.Join(append(parseErrs, err)...)
+	}
 	if len(f.entries) > 0 {
-		if formatErr := formatter.FormatSlice(options.Format, options.Stdout, f.entries); formatErr != nil {
+		formatSlice := formatter.FormatSlice
+		if options.JSONCompact {
+			formatSlice = formatter.FormatSliceCompact
+		}
+		if formatErr := formatSlice(options.Format, options.Stdout, f.entries); formatErr != nil {
 			log.G(ctx).Error(formatErr)
 		}
 	}
 	return err
 }
 
+// inspectConfigOnly prints the config blob of each image verbatim, for the platform of the client (`--config-only`).
+func inspectConfigOnly(ctx context.Context, client *containerd.Client, reqs []string, options types.ImageInspectOptions) error {
+	if options.Format != "" || options.Platforms || options.ShowLease {
+		return errors.New("--config-only cannot be combined with --format, --platforms, or --show-lease")
+	}
+	var platMC platforms.MatchComparer
+	if options.Platform != "" {
+		parsedPlatform, err := platforms.Parse(options.Platform)
+		if err != nil {
+			return err
+		}
+		platMC = platforms.Only(parsedPlatform)
+	}
+	walker := &imagewalker.ImageWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found imagewalker.Found) error {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			image := containerd.NewImage(client, found.Image)
+			if platMC != nil {
+				image = containerd.NewImageWithPlatform(client, found.Image, platMC)
+			}
+			desc, err := image.Config(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get the config of image %q: %w", found.Req, err)
+			}
+			b, err := content.ReadBlob(ctx, client.ContentStore(), desc)
+			if err != nil {
+				return fmt.Errorf("failed to read the config of image %q: %w", found.Req, err)
+			}
+			if !bytes.HasSuffix(b, []byte("\n")) {
+				b = append(b, '\n')
+			}
+			_, err = options.Stdout.Write(b)
+			return err
+		},
+	}
+	return walker.WalkAll(ctx, reqs, true)
+}
+
+// gcExpireLabel is the label of a lease set by leases.WithExpiration.
+const gcExpireLabel = "containerd.io/gc.expire"
+
 type imageInspector struct {
 	mode    string
 	entries []interface{}
 }
+
+// imageInspectTableRow is printed by `nerdctl image inspect --format 'table ...'`.
+// In addition to the fields of dockercompat.Image, it has the Repository and Tag
+// fields of `nerdctl images`, so that several images can be compared side by side.
+type imageInspectTableRow struct {
+	*dockercompat.Image
+	Repository string
+	Tag        string
+}
+
+func newImageInspectTableRow(d *dockercompat.Image) *imageInspectTableRow {
+	row := &imageInspectTableRow{
+		Image:      d,
+		Repository: "<none>",
+		Tag:        "<none>",
+	}
+	if len(d.RepoTags) > 0 {
+		row.Repository, row.Tag = imgutil.ParseRepoTag(d.RepoTags[0])
+	}
+	return row
+}
+
+// imagePlatforms is printed by `nerdctl image inspect --platforms`.
+type imagePlatforms struct {
+	Name      string
+	Platforms []imagePlatform
+}
+
+type imagePlatform struct {
+	OS           string
+	Architecture string
+	Variant      string `json:",omitempty"`
+}
+
+// inspectPlatforms returns the platforms in the index of `img`,
+// or the platform in the image config for a non-indexed image.
+func inspectPlatforms(ctx context.Context, client *containerd.Client, img images.Image) (*imagePlatforms, error) {
+	res := &imagePlatforms{Name: img.Name}
+	if !images.IsIndexType(img.Target.MediaType) {
+		config, _, err := imgutil.ReadImageConfig(ctx, containerd.NewImage(client, img))
+		if err != nil {
+			return nil, err
+		}
+		res.Platforms = append(res.Platforms, imagePlatform{
+			OS:           config.OS,
+			Architecture: config.Architecture,
+			Variant:      config.Variant,
+		})
+		return res, nil
+	}
+	ociPlatforms, err := images.Platforms(ctx, client.ContentStore(), img.Target)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range ociPlatforms {
+		res.Platforms = append(res.Platforms, imagePlatform{
+			OS:           p.OS,
+			Architecture: p.Architecture,
+			Variant:      p.Variant,
+		})
+	}
+	return res, nil
+}
+
+// downloadSizes returns the compressed sizes of the layers of the manifest, and their sum.
+func downloadSizes(manifest *ocispec.Manifest) *native.DownloadSizes {
+	res := &native.DownloadSizes{Layers: []native.LayerDownloadSize{}}
+	for _, l := range manifest.Layers {
+		res.Layers = append(res.Layers, native.LayerDownloadSize{
+			Digest:    l.Digest.String(),
+			MediaType: l.MediaType,
+			Size:      l.Size,
+		})
+		res.Total += l.Size
+	}
+	return res
+}
+
+// imageLeases returns the leases that reference the content of `img`.
+// The result is empty (not nil) when no lease applies.
+func imageLeases(ctx context.Context, client *containerd.Client, img images.Image) ([]native.Lease, error) {
+	cs := client.ContentStore()
+	digests := make(map[string]struct{})
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		digests[desc.Digest.String()] = struct{}{}
+		children, err := images.Children(ctx, cs, desc)
+		if errdefs.IsNotFound(err) {
+			// the content of the other platforms may not be present
+			return nil, nil
+		}
+		return children, err
+	})
+	if err := images.Walk(ctx, handler, img.Target); err != nil {
+		return nil, err
+	}
+
+	ls := client.LeasesService()
+	all, err := ls.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := []native.Lease{}
+	for _, l := range all {
+		resources, err := ls.ListResources(ctx, l)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			if r.Type != "content" {
+				continue
+			}
+			if _, ok := digests[r.ID]; ok {
+				res = append(res, native.Lease{
+					ID:         l.ID,
+					CreatedAt:  l.CreatedAt,
+					Expiration: l.Labels[gcExpireLabel],
+					Labels:     l.Labels,
+				})
+				break
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,223 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/imageinspector"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+)
+
+// imageDiff is printed by `nerdctl image inspect --diff IMAGE_A IMAGE_B`.
+// "Added" means only in IMAGE_B, "Removed" means only in IMAGE_A.
+// The config fields are omitted when they are equal.
+type imageDiff struct {
+	ImageA       string
+	ImageB       string
+	Env          *setDiff    `json:",omitempty"`
+	Entrypoint   *valuesDiff `json:",omitempty"`
+	Cmd          *valuesDiff `json:",omitempty"`
+	Labels       *labelsDiff `json:",omitempty"`
+	ExposedPorts *setDiff    `json:",omitempty"`
+	// Layers are compared by the diff IDs
+	Layers layersDiff
+	// SizeDelta is the unpacked size of IMAGE_B minus the one of IMAGE_A
+	SizeDelta int64
+}
+
+type setDiff struct {
+	Added   []string `json:",omitempty"`
+	Removed []string `json:",omitempty"`
+}
+
+type valuesDiff struct {
+	A []string
+	B []string
+}
+
+type valueChange struct {
+	A string
+	B string
+}
+
+type labelsDiff struct {
+	Added   map[string]string      `json:",omitempty"`
+	Removed map[string]string      `json:",omitempty"`
+	Changed map[string]valueChange `json:",omitempty"`
+}
+
+type layersDiff struct {
+	Shared  []string `json:",omitempty"`
+	Added   []string `json:",omitempty"`
+	Removed []string `json:",omitempty"`
+}
+
+// inspectDiff prints the differences between the two images in `reqs`.
+func inspectDiff(ctx context.Context, client *containerd.Client, reqs []string, options types.ImageInspectOptions) error {
+	if len(reqs) != 2 {
+		return fmt.Errorf("--diff requires exactly 2 images, got %d", len(reqs))
+	}
+	var inspected [2]*native.Image
+	for i, req := range reqs {
+		img, err := findImage(ctx, client, req)
+		if err != nil {
+			return err
+		}
+		inspected[i], err = imageinspector.Inspect(ctx, client, img, options.GOptions.Snapshotter)
+		if err != nil {
+			return err
+		}
+	}
+	d := diffImages(inspected[0], inspected[1])
+	d.ImageA, d.ImageB = reqs[0], reqs[1]
+	formatSlice := formatter.FormatSlice
+	if options.JSONCompact {
+		formatSlice = formatter.FormatSliceCompact
+	}
+	return formatSlice(options.Format, options.Stdout, []interface{}{d})
+}
+
+// findImage returns the image record matching `req`, which must not be ambiguous.
+func findImage(ctx context.Context, client *containerd.Client, req string) (images.Image, error) {
+	var found *imagewalker.Found
+	walker := &imagewalker.ImageWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, f imagewalker.Found) error {
+			if f.UniqueImages > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", f.Req)
+			}
+			if found == nil {
+				found = &f
+			}
+			return nil
+		},
+	}
+	if _, err := walker.Walk(ctx, req); err != nil {
+		return images.Image{}, err
+	}
+	if found == nil {
+		return images.Image{}, fmt.Errorf("no such image: %s", req)
+	}
+	return found.Image, nil
+}
+
+// diffImages compares the configs, the layers, and the sizes of `a` and `b`.
+func diffImages(a, b *native.Image) *imageDiff {
+	ca, cb := a.ImageConfig.Config, b.ImageConfig.Config
+	d := &imageDiff{
+		Env:          diffSets(ca.Env, cb.Env),
+		ExposedPorts: diffSets(portKeys(ca.ExposedPorts), portKeys(cb.ExposedPorts)),
+		Labels:       diffLabels(ca.Labels, cb.Labels),
+		SizeDelta:    b.Size - a.Size,
+	}
+	if !slices.Equal(ca.Entrypoint, cb.Entrypoint) {
+		d.Entrypoint = &valuesDiff{A: ca.Entrypoint, B: cb.Entrypoint}
+	}
+	if !slices.Equal(ca.Cmd, cb.Cmd) {
+		d.Cmd = &valuesDiff{A: ca.Cmd, B: cb.Cmd}
+	}
+
+	inA := make(map[string]struct{})
+	for _, l := range a.ImageConfig.RootFS.DiffIDs {
+		inA[l.String()] = struct{}{}
+	}
+	inB := make(map[string]struct{})
+	for _, l := range b.ImageConfig.RootFS.DiffIDs {
+		inB[l.String()] = struct{}{}
+		if _, ok := inA[l.String()]; ok {
+			d.Layers.Shared = append(d.Layers.Shared, l.String())
+		} else {
+			d.Layers.Added = append(d.Layers.Added, l.String())
+		}
+	}
+	for _, l := range a.ImageConfig.RootFS.DiffIDs {
+		if _, ok := inB[l.String()]; !ok {
+			d.Layers.Removed = append(d.Layers.Removed, l.String())
+		}
+	}
+	return d
+}
+
+func portKeys(ports map[string]struct{}) []string {
+	keys := make([]string, 0, len(ports))
+	for k := range ports {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// diffSets returns the sorted differences of the sets `a` and `b`, or nil if they are equal.
+func diffSets(a, b []string) *setDiff {
+	d := &setDiff{}
+	for _, s := range b {
+		if !slices.Contains(a, s) {
+			d.Added = append(d.Added, s)
+		}
+	}
+	for _, s := range a {
+		if !slices.Contains(b, s) {
+			d.Removed = append(d.Removed, s)
+		}
+	}
+	if len(d.Added) == 0 && len(d.Removed) == 0 {
+		return nil
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	return d
+}
+
+// diffLabels returns the differences of the labels `a` and `b`, or nil if they are equal.
+func diffLabels(a, b map[string]string) *labelsDiff {
+	d := &labelsDiff{}
+	for k, vb := range b {
+		va, ok := a[k]
+		switch {
+		case !ok:
+			if d.Added == nil {
+				d.Added = make(map[string]string)
+			}
+			d.Added[k] = vb
+		case va != vb:
+			if d.Changed == nil {
+				d.Changed = make(map[string]valueChange)
+			}
+			d.Changed[k] = valueChange{A: va, B: vb}
+		}
+	}
+	for k, va := range a {
+		if _, ok := b[k]; !ok {
+			if d.Removed == nil {
+				d.Removed = make(map[string]string)
+			}
+			d.Removed[k] = va
+		}
+	}
+	if d.Added == nil && d.Removed == nil && d.Changed == nil {
+		return nil
+	}
+	return d
+}
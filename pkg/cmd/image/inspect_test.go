@@ -0,0 +1,33 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIsImageIDOrDigest(t *testing.T) {
+	id := "4f7a5e1f6f4b3e9d0a3b2e6c9a0d8f1e2c3b4a5968778695a4b3c2d1e0f9a8b7"
+	assert.Assert(t, isImageIDOrDigest(id))
+	assert.Assert(t, isImageIDOrDigest("sha256:"+id))
+	assert.Assert(t, isImageIDOrDigest("4f7a5e1f"))
+	assert.Assert(t, !isImageIDOrDigest("alpine:3.18"))
+	assert.Assert(t, !isImageIDOrDigest("::::"))
+	assert.Assert(t, !isImageIDOrDigest(id+"0"))
+}
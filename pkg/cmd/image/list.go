@@ -19,11 +19,17 @@ package image
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"text/template"
 	"time"
@@ -35,9 +41,12 @@ import (
 	"github.com/containerd/containerd/snapshots"
 	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/infoutil"
 	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -47,7 +56,97 @@ func ListCommandHandler(ctx context.Context, client *containerd.Client, options
 	if err != nil {
 		return err
 	}
-	return printImages(ctx, client, imageList, options)
+	imageList, err = imgutil.ExcludeByReference(imageList, options.Hide)
+	if err != nil {
+		return err
+	}
+	if options.TotalsBy != "" {
+		return printTotalsByArch(ctx, client, imageList, options)
+	}
+	if options.Sort == "digest" {
+		sortImagesByDigest(imageList)
+	}
+	shown, err := printImages(ctx, client, imageList, options)
+	if err != nil {
+		return err
+	}
+	for _, img := range imageList {
+		if imgutil.IsSchema1(img) {
+			log.G(ctx).Warnf("image %q uses the deprecated Docker schema1 manifest, which cannot be pushed to modern registries; "+
+				"it should be re-pulled or converted (list such images with --filter=schema=1)", img.Name)
+		}
+	}
+	if options.Verbose {
+		all, err := client.ImageService().List(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(options.Stderr, imageListSummary(shown, len(all)))
+	}
+	return nil
+}
+
+// imageListSummary returns the summary printed by `nerdctl images --verbose`.
+func imageListSummary(shown, total int) string {
+	return fmt.Sprintf("Showing %d of %d images (%d filtered out)", shown, total, total-shown)
+}
+
+// sortImagesByDigest sorts the images by the full target digest, with the repository and the tag as tiebreakers.
+// The full digest is compared rather than the truncated form printed in the table, as the truncated digests may collide.
+func sortImagesByDigest(imageList []images.Image) {
+	sort.SliceStable(imageList, func(i, j int) bool {
+		di, dj := imageList[i].Target.Digest.String(), imageList[j].Target.Digest.String()
+		if di != dj {
+			return di < dj
+		}
+		ri, ti := imgutil.ParseRepoTag(imageList[i].Name)
+		rj, tj := imgutil.ParseRepoTag(imageList[j].Name)
+		if ri != rj {
+			return ri < rj
+		}
+		if ti != tj {
+			return ti < tj
+		}
+		return imageList[i].Name < imageList[j].Name
+	})
+}
+
+// createdAtConflict is a target digest shared by the image records with different created times,
+// e.g., after a tag or a load of the same image at another time.
+type createdAtConflict struct {
+	digest digest.Digest
+	names  []string
+}
+
+// createdAtConflicts indexes the images by the target digest, and returns the digests whose records have different created times,
+// in the order of the first record of each digest.
+func createdAtConflicts(imageList []images.Image, createdLabel string) []createdAtConflict {
+	var (
+		order   []digest.Digest
+		byDgst  = make(map[digest.Digest][]images.Image)
+		results []createdAtConflict
+	)
+	for _, img := range imageList {
+		if _, ok := byDgst[img.Target.Digest]; !ok {
+			order = append(order, img.Target.Digest)
+		}
+		byDgst[img.Target.Digest] = append(byDgst[img.Target.Digest], img)
+	}
+	for _, dgst := range order {
+		records := byDgst[dgst]
+		first := imgutil.CreatedAt(records[0], createdLabel)
+		for _, img := range records[1:] {
+			if !imgutil.CreatedAt(img, createdLabel).Equal(first) {
+				c := createdAtConflict{digest: dgst}
+				for _, r := range records {
+					c.names = append(c.names, r.Name)
+				}
+				results = append(results, c)
+				break
+			}
+		}
+	}
+	return results
 }
 
 // List queries containerd client to get image list and only returns those matching given filters.
@@ -58,6 +157,12 @@ func ListCommandHandler(ctx context.Context, client *containerd.Client, options
 // - label=<key>[=<value>]: Matches images based on the presence of a label alone or a label and a value
 // - dangling=true: Filter images by dangling
 // - reference=<image>[:<tag>]: Filter images by reference (Matches both docker compatible wildcard pattern and regexp
+// - reference=*:<tag>: Filter images by tag across all the repositories
+// - reference=<image>:{<tag>,<tag>}: Filter images by any of the tags (brace expansion of comma-separated lists)
+// - reference!=<image>[:<tag>], reference=!<image>[:<tag>]: Exclude images by reference (subtracted from the images matching any of the includes)
+// - size(>|<|>=|<=|==)<size>: Filter images by the unpacked size (applied by printImages, not by List)
+// - schema=(1|2): Filter images by the manifest schema (1 for the deprecated Docker schema1)
+// - tag=<tag>: Filter images by the exact tag, or by the absence of a tag with tag=none
 //
 // nameAndRefFilter has the format of `name==(<image>[:<tag>])|ID`,
 // and they will be used when getting images from containerd,
@@ -79,15 +184,40 @@ func List(ctx context.Context, client *containerd.Client, filters, nameAndRefFil
 			imageList = imgutil.FilterDangling(imageList, *f.Dangling)
 		}
 
+		if f.Schema != "" {
+			imageList = imgutil.FilterBySchema(imageList, f.Schema)
+		}
+
+		if f.Until != nil {
+			imageList = imgutil.FilterUntil(imageList, *f.Until)
+		}
+
+		if len(f.Tag) > 0 {
+			imageList = imgutil.FilterByTag(imageList, f.Tag)
+		}
+
 		imageList, err = imgutil.FilterByLabel(ctx, client, imageList, f.Labels)
 		if err != nil {
 			return nil, err
 		}
 
+		beforeReferenceFilter := imageList
+		// the images matching any of the includes, minus the ones matching any of the excludes
 		imageList, err = imgutil.FilterByReference(imageList, f.Reference)
 		if err != nil {
 			return nil, err
 		}
+		imageList, err = imgutil.ExcludeByReference(imageList, f.ReferenceExclude)
+		if err != nil {
+			return nil, err
+		}
+		if len(imageList) == 0 {
+			for _, r := range f.Reference {
+				if suggested, ok := imgutil.SuggestReference(beforeReferenceFilter, r); ok {
+					log.G(ctx).Warnf("no image matches reference %q. Hint: try the normalized form %q", r, suggested)
+				}
+			}
+		}
 
 		var beforeImages []images.Image
 		if len(f.Before) > 0 {
@@ -114,49 +244,96 @@ type imagePrintable struct {
 	CreatedAt    string
 	CreatedSince string
 	Digest       string // "<none>" or image target digest (i.e., index digest or manifest digest)
-	ID           string // image target digest (not config digest, unlike Docker), or its short form
+	ID           string // image config digest (as Docker's image ID), or its short form. Not printed in the table, see printImageSinglePlatform
 	Repository   string
 	Tag          string // "<none>" or tag
 	Name         string // image name
 	Size         string // the size of the unpacked snapshots.
 	BlobSize     string // the size of the blobs in the content store (nerdctl extension)
+	// LogicalSize is the size of the layers regardless of lazy pulling (nerdctl extension).
+	// Empty unless --show-logical-size is specified.
+	LogicalSize string
 	// TODO: "SharedSize", "UniqueSize"
-	Platform string // nerdctl extension
+	Platform string // nerdctl extension. The comma-separated platforms with --all-platforms
+	Dangling bool   // true if both Repository and Tag are "<none>"
+	// Snapshotter is the snapshotter used for computing Size (nerdctl extension).
+	// Empty if the image is not unpacked in any of the snapshotters probed with --probe-snapshotters.
+	Snapshotter string
 }
 
-func printImages(ctx context.Context, client *containerd.Client, imageList []images.Image, options types.ImageListOptions) error {
+// printImages prints the images, and returns the number of the images printed in at least one row.
+func printImages(ctx context.Context, client *containerd.Client, imageList []images.Image, options types.ImageListOptions) (int, error) {
 	w := options.Stdout
 	digestsFlag := options.Digests
 	if options.Format == "wide" {
 		digestsFlag = true
 	}
-	var tmpl *template.Template
+	var (
+		tmpl        *template.Template
+		delimiter   rune
+		printHeader bool
+	)
 	switch options.Format {
 	case "", "table", "wide":
 		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
-		if !options.Quiet {
-			printHeader := ""
-			if options.Names {
-				printHeader += "NAME\t"
-			} else {
-				printHeader += "REPOSITORY\tTAG\t"
-			}
-			if digestsFlag {
-				printHeader += "DIGEST\t"
-			}
-			printHeader += "IMAGE ID\tCREATED\tPLATFORM\tSIZE\tBLOB SIZE"
-			fmt.Fprintln(w, printHeader)
+		printHeader = !options.Quiet
+	case "csv", "tsv":
+		if options.Quiet {
+			return 0, errors.New("format and quiet must not be specified together")
+		}
+		delimiter = ','
+		if options.Format == "tsv" {
+			delimiter = '\t'
 		}
+		printHeader = true
 	case "raw":
-		return errors.New("unsupported format: \"raw\"")
+		return 0, errors.New("unsupported format: \"raw\"")
 	default:
 		if options.Quiet {
-			return errors.New("format and quiet must not be specified together")
+			return 0, errors.New("format and quiet must not be specified together")
 		}
 		var err error
 		tmpl, err = formatter.ParseTemplate(options.Format)
 		if err != nil {
-			return err
+			return 0, err
+		}
+	}
+
+	var sizeFilters []imgutil.SizeFilter
+	if len(options.Filters) > 0 {
+		f, err := imgutil.ParseFilters(options.Filters)
+		if err != nil {
+			return 0, err
+		}
+		// size filters are applied after computing the unpacked size of each platform
+		sizeFilters = f.Size
+	}
+
+	var sizes *imgutil.SizeCache
+	if !options.NoCache {
+		// the sizes are computed live without the cache, e.g., when the data root is not writable
+		if dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address); err != nil {
+			log.G(ctx).WithError(err).Debug("not using the image size cache")
+		} else {
+			sizes = imgutil.LoadSizeCache(ctx, filepath.Join(dataStore, "image-sizes", options.GOptions.Namespace+".json"))
+			defer func() {
+				if len(options.Filters) == 0 && len(options.NameAndRefFilter) == 0 && len(options.Hide) == 0 {
+					// all the images have been visited
+					sizes.PruneUnvisited()
+				}
+				if err := sizes.Save(); err != nil {
+					log.G(ctx).WithError(err).Warn("failed to save the image size cache")
+				}
+			}()
+		}
+	}
+
+	var prober *imgutil.SnapshotterProber
+	if options.ProbeSnapshotters {
+		var err error
+		prober, err = newSnapshotterProber(ctx, client, options.GOptions.Snapshotter, sizes)
+		if err != nil {
+			return 0, err
 		}
 	}
 
@@ -166,38 +343,216 @@ func printImages(ctx context.Context, client *containerd.Client, imageList []ima
 		noTrunc:      options.NoTrunc,
 		digestsFlag:  digestsFlag,
 		namesFlag:    options.Names,
+		logicalSize:  options.ShowLogicalSize,
+		allPlatforms: options.AllPlatforms,
+		createdLabel: options.CreatedFromLabel,
+		sizeFilters:  sizeFilters,
 		tmpl:         tmpl,
+		delimiter:    delimiter,
 		client:       client,
 		contentStore: client.ContentStore(),
 		snapshotter:  client.SnapshotService(options.GOptions.Snapshotter),
+		snName:       options.GOptions.Snapshotter,
+		prober:       prober,
+		sizes:        sizes,
+		configs:      &configCache{m: make(map[string]cachedConfig)},
+	}
+	conflicts := createdAtConflicts(imageList, options.CreatedFromLabel)
+	if len(conflicts) > 0 {
+		printer.createdConflicts = make(map[digest.Digest]struct{}, len(conflicts))
+		for _, c := range conflicts {
+			printer.createdConflicts[c.digest] = struct{}{}
+			if options.Verbose {
+				log.G(ctx).Warnf("image records %v share the digest %s, but have different created times; showing the created time of the image config instead",
+					c.names, c.digest)
+			}
+		}
+	}
+	if printHeader {
+		if err := printer.writeRecord(printer.header()); err != nil {
+			return 0, err
+		}
 	}
 
-	for _, img := range imageList {
-		if err := printer.printImage(ctx, img); err != nil {
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = runtime.GOMAXPROCS(0)
+	}
+	// the sizes are computed in parallel, and the rows are buffered per image to keep the order
+	bufs := make([]bytes.Buffer, len(imageList))
+	rows := make([]int, len(imageList))
+	runParallel(len(imageList), maxConcurrency, func(i int) {
+		p := *printer
+		p.w = &bufs[i]
+		if err := p.printImage(ctx, imageList[i]); err != nil {
 			log.G(ctx).Warn(err)
 		}
+		rows[i] = p.rows
+	})
+	shown := 0
+	for i := range imageList {
+		if _, err := w.Write(bufs[i].Bytes()); err != nil {
+			return shown, err
+		}
+		if rows[i] > 0 {
+			shown++
+		}
 	}
 	if f, ok := w.(formatter.Flusher); ok {
-		return f.Flush()
+		return shown, f.Flush()
 	}
-	return nil
+	return shown, nil
+}
+
+// runParallel calls fn(0), ..., fn(n-1) with at most maxConcurrency calls running at once.
+// With maxConcurrency 1, the calls are serial.
+func runParallel(n, maxConcurrency int, fn func(i int)) {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
 }
 
 type imagePrinter struct {
 	w                                      io.Writer
 	quiet, noTrunc, digestsFlag, namesFlag bool
+	logicalSize                            bool // --show-logical-size
+	allPlatforms                           bool // --all-platforms
+	createdLabel                           string
+	sizeFilters                            []imgutil.SizeFilter
 	tmpl                                   *template.Template
+	delimiter                              rune // ',' for --format=csv, '\t' for --format=tsv, 0 otherwise
 	client                                 *containerd.Client
 	contentStore                           content.Store
 	snapshotter                            snapshots.Snapshotter
+	snName                                 string
+	prober                                 *imgutil.SnapshotterProber // nil unless --probe-snapshotters
+	sizes                                  *imgutil.SizeCache         // nil with --no-cache
+	configs                                *configCache
+	createdConflicts                       map[digest.Digest]struct{} // the target digests of the records with different created times
+	rows                                   int                        // the number of the printed rows
+}
+
+// configCache caches the config descriptors read from the manifests, and the platforms and the created times read from the configs,
+// keyed by the target digest and the platform, so that the images sharing a target (e.g., the tags of an image)
+// read the manifest and the config only once.
+type configCache struct {
+	mu sync.Mutex
+	m  map[string]cachedConfig
+}
+
+type cachedConfig struct {
+	desc     v1.Descriptor
+	platform v1.Platform // zero if the config could not be read
+	created  *time.Time  // nil if the config could not be read, or does not have the created time
+}
+
+func (c *configCache) config(ctx context.Context, image containerd.Image, platform string) (cachedConfig, error) {
+	key := image.Target().Digest.String() + "@" + platform
+	c.mu.Lock()
+	cached, ok := c.m[key]
+	c.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	desc, err := image.Config(ctx)
+	if err != nil {
+		return cachedConfig{desc: desc}, err
+	}
+	cached = cachedConfig{desc: desc}
+	if b, err := content.ReadBlob(ctx, image.ContentStore(), desc); err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to read config %s", desc.Digest)
+	} else {
+		var config v1.Image
+		if err := json.Unmarshal(b, &config); err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to parse config %s", desc.Digest)
+		} else {
+			cached.platform = config.Platform
+			cached.created = config.Created
+		}
+	}
+	c.mu.Lock()
+	c.m[key] = cached
+	c.mu.Unlock()
+	return cached, nil
+}
+
+// resolvePlatform complements the platform of the manifest descriptor with the variant and the OS version of the config,
+// as the descriptors in an index often omit them (e.g., "linux/arm" for "linux/arm/v7").
+func resolvePlatform(desc, config v1.Platform) v1.Platform {
+	if config.OS != desc.OS || config.Architecture != desc.Architecture {
+		return desc
+	}
+	if desc.Variant == "" {
+		desc.Variant = config.Variant
+	}
+	if desc.OSVersion == "" {
+		desc.OSVersion = config.OSVersion
+	}
+	return desc
+}
+
+// newSnapshotterProber creates a prober for all the registered snapshotters, trying `preferred` first.
+func newSnapshotterProber(ctx context.Context, client *containerd.Client, preferred string, sizes *imgutil.SizeCache) (*imgutil.SnapshotterProber, error) {
+	registered, err := infoutil.GetSnapshotterNames(ctx, client.IntrospectionService())
+	if err != nil {
+		return nil, err
+	}
+	names := []string{preferred}
+	snapshotters := map[string]snapshots.Snapshotter{preferred: client.SnapshotService(preferred)}
+	for _, name := range registered {
+		if _, ok := snapshotters[name]; ok {
+			continue
+		}
+		names = append(names, name)
+		snapshotters[name] = client.SnapshotService(name)
+	}
+	return imgutil.NewSnapshotterProber(snapshotters, names, sizes), nil
 }
 
 func (x *imagePrinter) printImage(ctx context.Context, img images.Image) error {
+	if err := img.Target.Digest.Validate(); err != nil {
+		if x.quiet {
+			// the placeholder is not an ID that can be passed to the other commands, e.g., `nerdctl rmi $(nerdctl images -q)`
+			log.G(ctx).WithError(err).Warnf("skipping image %q with an invalid target digest %q, the metadata may be corrupt", img.Name, img.Target.Digest)
+			return nil
+		}
+		log.G(ctx).WithError(err).Warnf("image %q has an invalid target digest %q, the metadata may be corrupt", img.Name, img.Target.Digest)
+		return x.printCorruptImage(img)
+	}
 	ociPlatforms, err := images.Platforms(ctx, x.contentStore, img.Target)
 	if err != nil {
 		log.G(ctx).WithError(err).Warnf("failed to get the platform list of image %q", img.Name)
 		return x.printImageSinglePlatform(ctx, img, platforms.DefaultSpec())
 	}
+	ociPlatforms = uniquePlatforms(ociPlatforms)
+	if x.allPlatforms {
+		return x.printImageAllPlatforms(ctx, img, ociPlatforms)
+	}
+	for _, ociPlatform := range ociPlatforms {
+		if err := x.printImageSinglePlatform(ctx, img, ociPlatform); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get platform %q of image %q", platforms.Format(ociPlatform), img.Name)
+		}
+	}
+	return nil
+}
+
+// uniquePlatforms returns the platforms without the duplicates, e.g., of the attestation manifests.
+func uniquePlatforms(ociPlatforms []v1.Platform) []v1.Platform {
+	var res []v1.Platform
 	psm := map[string]struct{}{}
 	for _, ociPlatform := range ociPlatforms {
 		platformKey := makePlatformKey(ociPlatform)
@@ -205,11 +560,9 @@ func (x *imagePrinter) printImage(ctx context.Context, img images.Image) error {
 			continue
 		}
 		psm[platformKey] = struct{}{}
-		if err := x.printImageSinglePlatform(ctx, img, ociPlatform); err != nil {
-			log.G(ctx).WithError(err).Warnf("failed to get platform %q of image %q", platforms.Format(ociPlatform), img.Name)
-		}
+		res = append(res, ociPlatform)
 	}
-	return nil
+	return res
 }
 
 func makePlatformKey(platform v1.Platform) string {
@@ -221,6 +574,90 @@ func makePlatformKey(platform v1.Platform) string {
 }
 
 func (x *imagePrinter) printImageSinglePlatform(ctx context.Context, img images.Image, ociPlatform v1.Platform) error {
+	row := x.platformRow(ctx, img, ociPlatform)
+	if row == nil || !imgutil.MatchSize(x.sizeFilters, row.size) {
+		return nil
+	}
+	return x.printRow(row.imagePrintable)
+}
+
+// printImageAllPlatforms prints a single row for all the available platforms of the image (`--all-platforms`),
+// with the sizes summed up across the platforms.
+func (x *imagePrinter) printImageAllPlatforms(ctx context.Context, img images.Image, ociPlatforms []v1.Platform) error {
+	var (
+		sum           *imagePlatformRow
+		platformNames []string
+		available     []v1.Platform
+	)
+	for _, ociPlatform := range ociPlatforms {
+		row := x.platformRow(ctx, img, ociPlatform)
+		if row == nil {
+			continue
+		}
+		platformNames = append(platformNames, row.Platform)
+		available = append(available, ociPlatform)
+		if sum == nil {
+			sum = row
+			continue
+		}
+		sum.size += row.size
+		sum.logicalSize += row.logicalSize
+		if sum.Snapshotter == "" {
+			sum.Snapshotter = row.Snapshotter
+		}
+		// the config digest is specific to a platform, so the row is identified by the target digest
+		sum.ID = img.Target.Digest.String()
+	}
+	if sum == nil || !imgutil.MatchSize(x.sizeFilters, sum.size) {
+		return nil
+	}
+	if len(available) > 1 {
+		blobSize, err := uniqueBlobSize(ctx, x.contentStore, img.Target, platforms.Any(available...))
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get blob size of image %q", img.Name)
+		}
+		sum.blobSize = blobSize
+	}
+	p := sum.imagePrintable
+	p.Platform = strings.Join(platformNames, ",")
+	p.Size = progress.Bytes(sum.size).String()
+	p.BlobSize = progress.Bytes(sum.blobSize).String()
+	if x.logicalSize {
+		p.LogicalSize = progress.Bytes(sum.logicalSize).String()
+	}
+	return x.printRow(p)
+}
+
+// uniqueBlobSize returns the total size of the blobs of `target` for the platforms matched by `platMC`,
+// counting the blobs shared across the platforms (e.g., the same layer) only once.
+func uniqueBlobSize(ctx context.Context, provider content.Provider, target v1.Descriptor, platMC platforms.Matcher) (int64, error) {
+	var (
+		size     int64
+		seen     = make(map[digest.Digest]struct{})
+		children = images.FilterPlatforms(images.ChildrenHandler(provider), platMC)
+	)
+	handler := images.HandlerFunc(func(ctx context.Context, desc v1.Descriptor) ([]v1.Descriptor, error) {
+		if _, ok := seen[desc.Digest]; ok {
+			return nil, nil
+		}
+		seen[desc.Digest] = struct{}{}
+		size += desc.Size
+		return children(ctx, desc)
+	})
+	if err := images.Walk(ctx, handler, target); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// imagePlatformRow is the row of an image for a platform, with the sizes in bytes.
+type imagePlatformRow struct {
+	imagePrintable
+	size, blobSize, logicalSize int64
+}
+
+// platformRow returns the row of the image for the platform, or nil if the content of the platform is not available.
+func (x *imagePrinter) platformRow(ctx context.Context, img images.Image, ociPlatform v1.Platform) *imagePlatformRow {
 	platMC := platforms.OnlyStrict(ociPlatform)
 	if avail, _, _, _, availErr := images.Check(ctx, x.contentStore, img.Target, platMC); !avail {
 		log.G(ctx).WithError(availErr).Debugf("skipping printing image %q for platform %q", img.Name, platforms.Format(ociPlatform))
@@ -228,7 +665,8 @@ func (x *imagePrinter) printImageSinglePlatform(ctx context.Context, img images.
 	}
 
 	image := containerd.NewImageWithPlatform(x.client, img, platMC)
-	desc, err := image.Config(ctx)
+	config, err := x.configs.config(ctx, image, platforms.Format(ociPlatform))
+	desc := config.desc
 	if err != nil {
 		log.G(ctx).WithError(err).Warnf("failed to get config of image %q for platform %q", img.Name, platforms.Format(ociPlatform))
 	}
@@ -246,66 +684,170 @@ func (x *imagePrinter) printImageSinglePlatform(ctx context.Context, img images.
 		log.G(ctx).WithError(err).Warnf("failed to get blob size of image %q for platform %q", img.Name, platforms.Format(ociPlatform))
 	}
 
-	size, err := imgutil.UnpackedImageSize(ctx, x.snapshotter, image)
+	var size int64
+	snName := x.snName
+	if x.prober != nil {
+		size, snName, err = x.prober.UnpackedImageSize(ctx, image)
+	} else {
+		size, err = x.sizes.UnpackedImageSize(ctx, x.snapshotter, x.snName, image)
+	}
 	if err != nil {
 		// Warnf is too verbose: https://github.com/containerd/nerdctl/issues/2058
 		log.G(ctx).WithError(err).Debugf("failed to get unpacked size of image %q for platform %q", img.Name, platforms.Format(ociPlatform))
 	}
 
+	var (
+		logicalSize    int64
+		logicalSizeStr string
+	)
+	if x.logicalSize {
+		logicalSize, err = imgutil.LogicalImageSize(ctx, image)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get logical size of image %q for platform %q", img.Name, platforms.Format(ociPlatform))
+		}
+		logicalSizeStr = progress.Bytes(logicalSize).String()
+	}
+
+	createdAt := imgutil.CreatedAt(img, x.createdLabel)
+	if _, ok := x.createdConflicts[img.Target.Digest]; ok && config.created != nil {
+		// the records of the same image must not show different created times
+		createdAt = *config.created
+	}
 	p := imagePrintable{
-		CreatedAt:    img.CreatedAt.Round(time.Second).Local().String(), // format like "2021-08-07 02:19:45 +0900 JST"
-		CreatedSince: formatter.TimeSinceInHuman(img.CreatedAt),
+		CreatedAt:    createdAt.Round(time.Second).Local().String(), // format like "2021-08-07 02:19:45 +0900 JST"
+		CreatedSince: formatter.TimeSinceInHuman(createdAt),
 		Digest:       img.Target.Digest.String(),
-		ID:           img.Target.Digest.String(),
+		ID:           desc.Digest.String(),
 		Repository:   repository,
 		Tag:          tag,
 		Name:         img.Name,
 		Size:         progress.Bytes(size).String(),
 		BlobSize:     progress.Bytes(blobSize).String(),
-		Platform:     platforms.Format(ociPlatform),
+		LogicalSize:  logicalSizeStr,
+		Platform:     platforms.Format(resolvePlatform(ociPlatform, config.platform)),
+		Snapshotter:  snName,
 	}
+	return &imagePlatformRow{imagePrintable: p, size: size, blobSize: blobSize, logicalSize: logicalSize}
+}
+
+// corruptDigest is printed for the digests of an image record with an invalid target.
+const corruptDigest = "<corrupt>"
+
+// printCorruptImage prints a row for an image record whose target digest is invalid (e.g., empty),
+// so that the record is still listed and can be removed by the name.
+func (x *imagePrinter) printCorruptImage(img images.Image) error {
+	repository, tag := imgutil.ParseRepoTag(img.Name)
+	createdAt := imgutil.CreatedAt(img, x.createdLabel)
+	return x.printRow(imagePrintable{
+		CreatedAt:    createdAt.Round(time.Second).Local().String(),
+		CreatedSince: formatter.TimeSinceInHuman(createdAt),
+		Digest:       corruptDigest,
+		ID:           corruptDigest,
+		Repository:   repository,
+		Tag:          tag,
+		Name:         img.Name,
+		Size:         progress.Bytes(0).String(),
+		BlobSize:     progress.Bytes(0).String(),
+	})
+}
+
+func (x *imagePrinter) printRow(p imagePrintable) error {
 	if p.Repository == "" {
 		p.Repository = "<none>"
 	}
 	if p.Tag == "" {
 		p.Tag = "<none>" // for Docker compatibility
 	}
+	p.Dangling = p.Repository == "<none>" && p.Tag == "<none>"
+	// The table and --quiet print the target digest as the image ID, as in the previous releases.
+	// The other commands such as `nerdctl rmi` accept either of the target digest and the config digest (p.ID).
+	targetID := p.Digest
 	if !x.noTrunc {
 		// p.Digest does not need to be truncated
-		p.ID = strings.Split(p.ID, ":")[1][:12]
+		targetID = truncateDigest(targetID)
+		if p.ID != "" {
+			p.ID = truncateDigest(p.ID)
+		}
 	}
 	if x.tmpl != nil {
 		var b bytes.Buffer
 		if err := x.tmpl.Execute(&b, p); err != nil {
 			return err
 		}
-		if _, err = fmt.Fprintln(x.w, b.String()); err != nil {
+		if _, err := fmt.Fprintln(x.w, b.String()); err != nil {
 			return err
 		}
 	} else if x.quiet {
-		if _, err := fmt.Fprintln(x.w, p.ID); err != nil {
+		if _, err := fmt.Fprintln(x.w, targetID); err != nil {
 			return err
 		}
+	} else if err := x.writeRecord(x.record(p, targetID)); err != nil {
+		return err
+	}
+	x.rows++
+	return nil
+}
+
+// header returns the column names of the table, csv, and tsv formats.
+func (x *imagePrinter) header() []string {
+	var h []string
+	if x.namesFlag {
+		h = append(h, "NAME")
 	} else {
-		format := ""
-		args := []interface{}{}
-		if x.namesFlag {
-			format += "%s\t"
-			args = append(args, p.Name)
-		} else {
-			format += "%s\t%s\t"
-			args = append(args, p.Repository, p.Tag)
-		}
-		if x.digestsFlag {
-			format += "%s\t"
-			args = append(args, p.Digest)
-		}
+		h = append(h, "REPOSITORY", "TAG")
+	}
+	if x.digestsFlag {
+		h = append(h, "DIGEST")
+	}
+	platformHeader := "PLATFORM"
+	if x.allPlatforms {
+		platformHeader = "PLATFORMS"
+	}
+	h = append(h, "IMAGE ID", "CREATED", platformHeader, "SIZE", "BLOB SIZE")
+	if x.logicalSize {
+		h = append(h, "LOGICAL SIZE")
+	}
+	return h
+}
 
-		format += "%s\t%s\t%s\t%s\t%s\n"
-		args = append(args, p.ID, p.CreatedSince, p.Platform, p.Size, p.BlobSize)
-		if _, err := fmt.Fprintf(x.w, format, args...); err != nil {
-			return err
-		}
+// record returns the columns of the row, in the order of header.
+func (x *imagePrinter) record(p imagePrintable, targetID string) []string {
+	var r []string
+	if x.namesFlag {
+		r = append(r, p.Name)
+	} else {
+		r = append(r, p.Repository, p.Tag)
 	}
-	return nil
+	if x.digestsFlag {
+		r = append(r, p.Digest)
+	}
+	r = append(r, targetID, p.CreatedSince, p.Platform, p.Size, p.BlobSize)
+	if x.logicalSize {
+		r = append(r, p.LogicalSize)
+	}
+	return r
+}
+
+// writeRecord writes the columns as a line of the table, or as an RFC 4180 record for csv and tsv.
+func (x *imagePrinter) writeRecord(record []string) error {
+	if x.delimiter == 0 {
+		_, err := fmt.Fprintln(x.w, strings.Join(record, "\t"))
+		return err
+	}
+	cw := csv.NewWriter(x.w)
+	cw.Comma = x.delimiter
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// truncateDigest returns the short form of a digest, e.g., "4f2c4fdb4c1d" for "sha256:4f2c4fdb4c1d...".
+func truncateDigest(d string) string {
+	_, encoded, ok := strings.Cut(d, ":")
+	if !ok || len(encoded) < 12 {
+		return d
+	}
+	return encoded[:12]
 }
@@ -0,0 +1,245 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// fakeSizer records the number of the concurrent size computations.
+type fakeSizer struct {
+	running int32
+	max     int32
+	mu      sync.Mutex
+	order   []int
+}
+
+func (s *fakeSizer) size(i int) {
+	n := atomic.AddInt32(&s.running, 1)
+	for {
+		m := atomic.LoadInt32(&s.max)
+		if n <= m || atomic.CompareAndSwapInt32(&s.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	s.mu.Lock()
+	s.order = append(s.order, i)
+	s.mu.Unlock()
+	atomic.AddInt32(&s.running, -1)
+}
+
+func TestRunParallel(t *testing.T) {
+	t.Parallel()
+	for _, limit := range []int{1, 2, 4, 100} {
+		s := &fakeSizer{}
+		runParallel(20, limit, s.size)
+		assert.Equal(t, len(s.order), 20)
+		assert.Assert(t, int(s.max) <= limit, "concurrency %d exceeded the limit %d", s.max, limit)
+		if limit == 1 {
+			for i, v := range s.order {
+				assert.Equal(t, v, i)
+			}
+		}
+	}
+}
+
+func TestPrintCorruptImage(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	x := &imagePrinter{w: &out}
+	// a record with a zero-value target, as in corrupt metadata
+	img := images.Image{Name: "example.com/foo:corrupt", CreatedAt: time.Now()}
+	assert.NilError(t, x.printImage(context.Background(), img))
+	assert.Equal(t, x.rows, 1)
+	fields := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\t")
+	assert.DeepEqual(t, fields[:3], []string{"example.com/foo", "corrupt", corruptDigest})
+
+	out.Reset()
+	x = &imagePrinter{w: &out, quiet: true}
+	assert.NilError(t, x.printImage(context.Background(), img))
+	assert.Equal(t, out.String(), "")
+	assert.Equal(t, x.rows, 0)
+}
+
+func TestPrintRowDelimited(t *testing.T) {
+	t.Parallel()
+	p := imagePrintable{
+		Repository: "example.com/foo",
+		Tag:        "latest",
+		Digest:     "sha256:4f2c4fdb4c1d3f22aa4bd4c98d0ab0f6fbe1791f99e290a7e1e98b3b2bd28d4e",
+		ID:         "sha256:0bcd4b14d1e9a12359e7a9d33b3b2f8e8ad4c4e8e6a1d2c2c6f0a8a6ad8d3f8a",
+		// --all-platforms joins the platforms with commas
+		Platform:     "linux/amd64,linux/arm64",
+		CreatedSince: `2 "weeks" ago`,
+		Size:         "1.0 MiB",
+		BlobSize:     "512.0 KiB",
+	}
+
+	var out bytes.Buffer
+	x := &imagePrinter{w: &out, delimiter: ','}
+	assert.NilError(t, x.writeRecord(x.header()))
+	assert.NilError(t, x.printRow(p))
+	assert.Equal(t, out.String(), "REPOSITORY,TAG,IMAGE ID,CREATED,PLATFORM,SIZE,BLOB SIZE\n"+
+		`example.com/foo,latest,4f2c4fdb4c1d,"2 ""weeks"" ago","linux/amd64,linux/arm64",1.0 MiB,512.0 KiB`+"\n")
+
+	out.Reset()
+	x = &imagePrinter{w: &out, delimiter: '\t'}
+	p.Tag = "with\ttab"
+	assert.NilError(t, x.printRow(p))
+	assert.Equal(t, out.String(),
+		"example.com/foo\t\"with\ttab\"\t4f2c4fdb4c1d\t\"2 \"\"weeks\"\" ago\"\tlinux/amd64,linux/arm64\t1.0 MiB\t512.0 KiB\n")
+}
+
+func TestSortImagesByDigest(t *testing.T) {
+	t.Parallel()
+	// the truncated digests ("4f2c4fdb4c1d") collide, the full digests do not
+	const (
+		digestA = "sha256:4f2c4fdb4c1d0000000000000000000000000000000000000000000000000001"
+		digestB = "sha256:4f2c4fdb4c1d0000000000000000000000000000000000000000000000000002"
+	)
+	imageList := []images.Image{
+		{Name: "example.com/foo:b", Target: v1.Descriptor{Digest: digestB}},
+		{Name: "example.com/foo:a", Target: v1.Descriptor{Digest: digestB}},
+		{Name: "example.com/bar:latest", Target: v1.Descriptor{Digest: digestB}},
+		{Name: "example.com/zzz:latest", Target: v1.Descriptor{Digest: digestA}},
+	}
+	assert.Equal(t, truncateDigest(digestA), truncateDigest(digestB))
+	sortImagesByDigest(imageList)
+	var names []string
+	for _, img := range imageList {
+		names = append(names, img.Name)
+	}
+	assert.DeepEqual(t, names, []string{"example.com/zzz:latest", "example.com/bar:latest", "example.com/foo:a", "example.com/foo:b"})
+}
+
+func TestResolvePlatform(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc, config v1.Platform
+		expected     string
+	}{
+		{
+			desc:     v1.Platform{OS: "linux", Architecture: "arm"},
+			config:   v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+			expected: "linux/arm/v7",
+		},
+		{
+			// the variant of the descriptor takes precedence
+			desc:     v1.Platform{OS: "linux", Architecture: "arm", Variant: "v6"},
+			config:   v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+			expected: "linux/arm/v6",
+		},
+		{
+			// the config of another platform is ignored
+			desc:     v1.Platform{OS: "linux", Architecture: "amd64"},
+			config:   v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+			expected: "linux/amd64",
+		},
+		{
+			// the config could not be read
+			desc:     v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			expected: "linux/arm64/v8",
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, platforms.Format(resolvePlatform(tc.desc, tc.config)), tc.expected)
+	}
+}
+
+func TestCreatedAtConflicts(t *testing.T) {
+	t.Parallel()
+	const (
+		digestA = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+		digestB = "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	)
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	imageList := []images.Image{
+		{Name: "example.com/foo:1", Target: v1.Descriptor{Digest: digestA}, CreatedAt: t0},
+		{Name: "example.com/bar:1", Target: v1.Descriptor{Digest: digestB}, CreatedAt: t0},
+		{Name: "example.com/foo:2", Target: v1.Descriptor{Digest: digestA}, CreatedAt: t0.Add(time.Hour)},
+		// the same instant in another location is not a conflict
+		{Name: "example.com/bar:2", Target: v1.Descriptor{Digest: digestB}, CreatedAt: t0.In(time.FixedZone("JST", 9*60*60))},
+	}
+	conflicts := createdAtConflicts(imageList, "")
+	assert.Equal(t, len(conflicts), 1)
+	assert.Equal(t, conflicts[0].digest.String(), digestA)
+	assert.DeepEqual(t, conflicts[0].names, []string{"example.com/foo:1", "example.com/foo:2"})
+
+	// the created times read from the label are compared
+	imageList[2].Labels = map[string]string{"created": t0.Format(time.RFC3339)}
+	imageList[0].Labels = map[string]string{"created": t0.Format(time.RFC3339)}
+	assert.Equal(t, len(createdAtConflicts(imageList, "created")), 0)
+}
+
+func TestUniqueBlobSize(t *testing.T) {
+	ctx := context.Background()
+	cs, err := local.NewStore(t.TempDir())
+	assert.NilError(t, err)
+	writeJSON := func(mediaType string, v interface{}) v1.Descriptor {
+		b, err := json.Marshal(v)
+		assert.NilError(t, err)
+		desc := v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(b), Size: int64(len(b))}
+		assert.NilError(t, content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(b), desc))
+		return desc
+	}
+	// the layers are not read, so they do not have to exist
+	shared := v1.Descriptor{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.FromString("shared"), Size: 1000}
+	manifest := func(arch string) v1.Descriptor {
+		config := writeJSON(v1.MediaTypeImageConfig, v1.Image{Platform: v1.Platform{OS: "linux", Architecture: arch}})
+		layer := v1.Descriptor{MediaType: v1.MediaTypeImageLayerGzip, Digest: digest.FromString(arch), Size: 100}
+		desc := writeJSON(v1.MediaTypeImageManifest, v1.Manifest{MediaType: v1.MediaTypeImageManifest, Config: config, Layers: []v1.Descriptor{shared, layer}})
+		desc.Platform = &v1.Platform{OS: "linux", Architecture: arch}
+		return desc
+	}
+	amd64, arm64 := manifest("amd64"), manifest("arm64")
+	index := writeJSON(v1.MediaTypeImageIndex, v1.Index{MediaType: v1.MediaTypeImageIndex, Manifests: []v1.Descriptor{amd64, arm64}})
+
+	configSize := func(m v1.Descriptor) int64 {
+		b, err := content.ReadBlob(ctx, cs, m)
+		assert.NilError(t, err)
+		var manifest v1.Manifest
+		assert.NilError(t, json.Unmarshal(b, &manifest))
+		return manifest.Config.Size
+	}
+	amd64Size := amd64.Size + configSize(amd64) + 100
+	arm64Size := arm64.Size + configSize(arm64) + 100
+
+	size, err := uniqueBlobSize(ctx, cs, index, platforms.Any(v1.Platform{OS: "linux", Architecture: "amd64"}, v1.Platform{OS: "linux", Architecture: "arm64"}))
+	assert.NilError(t, err)
+	// the shared layer is counted once
+	assert.Equal(t, size, index.Size+amd64Size+arm64Size+shared.Size)
+
+	size, err = uniqueBlobSize(ctx, cs, index, platforms.Only(v1.Platform{OS: "linux", Architecture: "arm64"}))
+	assert.NilError(t, err)
+	assert.Equal(t, size, index.Size+arm64Size+shared.Size)
+}
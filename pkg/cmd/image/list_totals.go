@@ -0,0 +1,174 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/pkg/progress"
+	"github.com/containerd/containerd/snapshots"
+	"github.com/containerd/log"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// archTotal is the deduplicated sizes of the images of an architecture.
+type archTotal struct {
+	images int
+	// blobs is the sizes of the config and the layer blobs in the content store, by digest
+	blobs map[digest.Digest]int64
+	// snapshots is the sizes of the unpacked layers, by chain ID
+	snapshots map[string]int64
+}
+
+func (t *archTotal) size() int64 {
+	var n int64
+	for _, s := range t.snapshots {
+		n += s
+	}
+	return n
+}
+
+func (t *archTotal) blobSize() int64 {
+	var n int64
+	for _, s := range t.blobs {
+		n += s
+	}
+	return n
+}
+
+// printTotalsByArch prints the sizes of the images grouped by the architecture (`--totals-by=arch`).
+// The blobs and the snapshots shared by the images of an architecture are counted once.
+func printTotalsByArch(ctx context.Context, client *containerd.Client, imageList []images.Image, options types.ImageListOptions) error {
+	switch options.Format {
+	case "", "table":
+	default:
+		return errors.New("--totals-by cannot be combined with --format")
+	}
+	if options.Quiet {
+		return errors.New("--totals-by cannot be combined with --quiet")
+	}
+	cs := client.ContentStore()
+	sn := client.SnapshotService(options.GOptions.Snapshotter)
+	totals := make(map[string]*archTotal)
+	for _, img := range imageList {
+		ociPlatforms, err := images.Platforms(ctx, cs, img.Target)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get the platform list of image %q", img.Name)
+			continue
+		}
+		counted := make(map[string]struct{})
+		for _, p := range uniquePlatforms(ociPlatforms) {
+			// skip the attestation manifests
+			if p.Architecture == "" || p.Architecture == "unknown" {
+				continue
+			}
+			arch := path.Join(p.Architecture, p.Variant)
+			t, ok := totals[arch]
+			if !ok {
+				t = &archTotal{blobs: make(map[digest.Digest]int64), snapshots: make(map[string]int64)}
+				totals[arch] = t
+			}
+			available, err := addArchTotal(ctx, client, cs, sn, img, p, t)
+			if err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to get the size of image %q for platform %q", img.Name, platforms.Format(p))
+				continue
+			}
+			if _, ok := counted[arch]; available && !ok {
+				counted[arch] = struct{}{}
+				t.images++
+			}
+		}
+	}
+
+	archs := make([]string, 0, len(totals))
+	for arch, t := range totals {
+		if t.images > 0 {
+			archs = append(archs, arch)
+		}
+	}
+	sort.Strings(archs)
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "ARCH\tIMAGES\tSIZE\tBLOB SIZE")
+	for _, arch := range archs {
+		t := totals[arch]
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", arch, t.images, progress.Bytes(t.size()).String(), progress.Bytes(t.blobSize()).String())
+	}
+	return w.Flush()
+}
+
+// addArchTotal adds the blobs and the unpacked layers of the platform of the image to the total.
+// It returns false if the platform is not available in the content store.
+// Nothing is added to the total on an error, so that a platform is either counted fully or not at all.
+func addArchTotal(ctx context.Context, client *containerd.Client, cs content.Store, sn snapshots.Snapshotter,
+	img images.Image, p v1.Platform, t *archTotal) (bool, error) {
+	manifest, err := images.Manifest(ctx, cs, img.Target, platforms.OnlyStrict(p))
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	blobs := make(map[digest.Digest]int64)
+	for _, desc := range append([]v1.Descriptor{manifest.Config}, manifest.Layers...) {
+		if _, err := cs.Info(ctx, desc.Digest); err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		blobs[desc.Digest] = desc.Size
+	}
+	if len(blobs) == 0 {
+		return false, nil
+	}
+	diffIDs, err := containerd.NewImageWithPlatform(client, img, platforms.OnlyStrict(p)).RootFS(ctx)
+	if err != nil {
+		return false, err
+	}
+	snapshotSizes := make(map[string]int64)
+	for _, chainID := range identity.ChainIDs(diffIDs) {
+		usage, err := sn.Usage(ctx, chainID.String())
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				// not unpacked
+				break
+			}
+			return false, err
+		}
+		snapshotSizes[chainID.String()] = usage.Size
+	}
+	for dgst, size := range blobs {
+		t.blobs[dgst] = size
+	}
+	for chainID, size := range snapshotSizes {
+		t.snapshots[chainID] = size
+	}
+	return true, nil
+}
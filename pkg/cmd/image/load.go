@@ -94,7 +94,7 @@ func loadImage(ctx context.Context, client *containerd.Client, in io.Reader, pla
 		image := containerd.NewImageWithPlatform(client, img, platMC)
 
 		// TODO: Show unpack status
-		if !quiet {
+		if !quiet && !options.Quiet {
 			fmt.Fprintf(options.Stdout, "unpacking %s (%s)...\n", img.Name, img.Target.Digest)
 		}
 		err = image.Unpack(ctx, options.GOptions.Snapshotter)
@@ -21,12 +21,16 @@ import (
 	"fmt"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
 	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/pkg/progress"
 	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/platforms"
 	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // Prune will remove all dangling images. If all is specified, will also remove all images not referenced by any container.
@@ -41,8 +45,14 @@ func Prune(ctx context.Context, client *containerd.Client, options types.ImagePr
 	if err != nil {
 		return err
 	}
+	if len(options.Filters) > 0 {
+		imageList, err = filterPruneImages(ctx, client, imageList, options.Filters)
+		if err != nil {
+			return err
+		}
+	}
 
-	var filteredImages []images.Image
+	var candidates []pruneCandidate
 
 	if options.All {
 		containerList, err := containerStore.List(ctx)
@@ -59,15 +69,36 @@ func Prune(ctx context.Context, client *containerd.Client, options types.ImagePr
 				continue
 			}
 
-			filteredImages = append(filteredImages, image)
+			reason := pruneReasonUnused
+			if _, tag := imgutil.ParseRepoTag(image.Name); tag == "" {
+				reason = pruneReasonDangling
+			}
+			candidates = append(candidates, pruneCandidate{image: image, reason: reason})
 		}
 	} else {
-		filteredImages = imgutil.FilterDangling(imageList, true)
+		for _, image := range imgutil.FilterDangling(imageList, true) {
+			candidates = append(candidates, pruneCandidate{image: image, reason: pruneReasonDangling})
+		}
+	}
+
+	// The blobs of the images to be removed are candidates for the reclaimable space.
+	// They are collected before deleting the image records, as the record is needed to walk the content.
+	candidateBlobs := make(map[digest.Digest]int64)
+	for i, candidate := range candidates {
+		blobs := make(map[digest.Digest]int64)
+		if err := walkImageBlobs(ctx, contentStore, candidate.image.Target, blobs); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to enumerate blobs of image %s", candidate.image.Name)
+		}
+		for dgst, size := range blobs {
+			candidates[i].size += size
+			candidateBlobs[dgst] = size
+		}
 	}
 
 	delOpts := []images.DeleteOpt{images.SynchronousDelete()}
-	removedImages := make(map[string][]digest.Digest)
-	for _, image := range filteredImages {
+	var removedImages []pruneCandidate
+	for _, candidate := range candidates {
+		image := candidate.image
 		digests, err := image.RootFS(ctx, contentStore, platforms.DefaultStrict())
 		if err != nil {
 			log.G(ctx).WithError(err).Warnf("failed to enumerate rootfs")
@@ -76,18 +107,150 @@ func Prune(ctx context.Context, client *containerd.Client, options types.ImagePr
 			log.G(ctx).WithError(err).Warnf("failed to delete image %s", image.Name)
 			continue
 		}
-		removedImages[image.Name] = digests
+		candidate.digests = digests
+		removedImages = append(removedImages, candidate)
 	}
 
 	if len(removedImages) > 0 {
 		fmt.Fprintln(options.Stdout, "Deleted Images:")
-		for image, digests := range removedImages {
-			fmt.Fprintf(options.Stdout, "Untagged: %s\n", image)
-			for _, digest := range digests {
+		for _, removed := range removedImages {
+			if options.Verbose {
+				fmt.Fprintf(options.Stdout, "Untagged: %s (reason: %s, size: %s)\n", removed.image.Name, removed.reason, progress.Bytes(removed.size))
+			} else {
+				fmt.Fprintf(options.Stdout, "Untagged: %s\n", removed.image.Name)
+			}
+			for _, digest := range removed.digests {
 				fmt.Fprintf(options.Stdout, "deleted: %s\n", digest)
 			}
 		}
 		fmt.Fprintln(options.Stdout, "")
 	}
+
+	reclaimed, err := reclaimedSpace(ctx, client, candidateBlobs)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("failed to compute reclaimed space")
+		return nil
+	}
+	fmt.Fprintf(options.Stdout, "Total reclaimed space: %s\n", progress.Bytes(reclaimed))
 	return nil
 }
+
+// filterPruneImages returns the images matching all the filters.
+// The label and until filters of `docker image prune` are supported, plus the reference filter.
+func filterPruneImages(ctx context.Context, client *containerd.Client, imageList []images.Image, filters []string) ([]images.Image, error) {
+	f, err := imgutil.ParseFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Before) > 0 || len(f.Since) > 0 || f.Dangling != nil || len(f.Size) > 0 || f.Schema != "" || len(f.Tag) > 0 {
+		return nil, fmt.Errorf("unsupported filter in %v (supported: %s, %s, %s)", filters, imgutil.FilterLabelType, imgutil.FilterReferenceType, imgutil.FilterUntilType)
+	}
+	if f.Until != nil {
+		imageList = imgutil.FilterUntil(imageList, *f.Until)
+	}
+	imageList, err = imgutil.FilterByLabel(ctx, client, imageList, f.Labels)
+	if err != nil {
+		return nil, err
+	}
+	imageList, err = imgutil.FilterByReference(imageList, f.Reference)
+	if err != nil {
+		return nil, err
+	}
+	return imgutil.ExcludeByReference(imageList, f.ReferenceExclude)
+}
+
+// Reasons why an image is selected by Prune.
+const (
+	pruneReasonDangling = "dangling"
+	pruneReasonUnused   = "unused" // not referenced by any container, with --all
+)
+
+// pruneCandidate is an image to be removed by Prune, with the reason why it was selected.
+type pruneCandidate struct {
+	image   images.Image
+	reason  string
+	size    int64 // the size of the blobs in the content store, including the blobs shared with other images
+	digests []digest.Digest
+}
+
+// reclaimedSpace returns the total size of the blobs in `candidates` that are no longer
+// referenced by any remaining image, and that are not protected by a lease.
+//
+// Leased blobs (e.g. the layers of an in-progress pull) are kept by the garbage collector
+// even if no image refers to them anymore, so they must not be counted as reclaimed.
+func reclaimedSpace(ctx context.Context, client *containerd.Client, candidates map[digest.Digest]int64) (int64, error) {
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+	contentStore := client.ContentStore()
+	imageList, err := client.ImageService().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	inUse := make(map[digest.Digest]int64)
+	for _, image := range imageList {
+		if err := walkImageBlobs(ctx, contentStore, image.Target, inUse); err != nil {
+			return 0, err
+		}
+	}
+	leased, err := leasedContent(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+	var reclaimed int64
+	for dgst, size := range candidates {
+		if _, ok := inUse[dgst]; ok {
+			continue
+		}
+		if _, ok := leased[dgst]; ok {
+			continue
+		}
+		reclaimed += size
+	}
+	return reclaimed, nil
+}
+
+// leasedContent returns the digests of the content resources referenced by any lease.
+func leasedContent(ctx context.Context, client *containerd.Client) (map[digest.Digest]struct{}, error) {
+	leaseManager := client.LeasesService()
+	leaseList, err := leaseManager.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	leased := make(map[digest.Digest]struct{})
+	for _, lease := range leaseList {
+		resources, err := leaseManager.ListResources(ctx, lease)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			if resource.Type != "content" {
+				continue
+			}
+			dgst, err := digest.Parse(resource.ID)
+			if err != nil {
+				log.G(ctx).WithError(err).Debugf("failed to parse the content resource %q of lease %q", resource.ID, lease.ID)
+				continue
+			}
+			leased[dgst] = struct{}{}
+		}
+	}
+	return leased, nil
+}
+
+// walkImageBlobs records the sizes of the blobs reachable from `target` that are present in the content store.
+// Blobs missing from the content store (e.g., the manifests of the non-pulled platforms) are skipped.
+func walkImageBlobs(ctx context.Context, contentStore content.Store, target ocispec.Descriptor, blobs map[digest.Digest]int64) error {
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		info, err := contentStore.Info(ctx, desc.Digest)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		blobs[desc.Digest] = info.Size
+		return images.Children(ctx, contentStore, desc)
+	})
+	return images.Walk(ctx, handler, target)
+}
@@ -19,17 +19,26 @@ package image
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	refdocker "github.com/containerd/containerd/reference/docker"
+	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/nerdctl/v2/pkg/ipfs"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/containerd/nerdctl/v2/pkg/signutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
+	"github.com/containerd/platforms"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -39,22 +48,174 @@ func Pull(ctx context.Context, client *containerd.Client, rawRef string, options
 	if err != nil {
 		return err
 	}
+	if !options.AllPlatforms && strutil.InStringSlice(options.Platform, platformutil.Auto) {
+		log.G(ctx).Infof("platform %q resolved to %q", platformutil.Auto, platforms.Format(platforms.DefaultSpec()))
+	}
+
+	if _, _, err := referenceutil.ParseIPFSRefWithScheme(rawRef); err == nil && options.StoreMetadata {
+		return errors.New("--store-metadata flag is not supported on IPFS")
+	}
 
 	unpack, err := strutil.ParseBoolOrAuto(options.Unpack)
 	if err != nil {
 		return err
 	}
 
-	_, err = EnsureImage(ctx, client, rawRef, ocispecPlatforms, "always", unpack, options.Quiet, options)
+	labels := strutil.ConvertKVStringsToMap(options.Labels)
+	if options.StoreMetadata {
+		name, upToDate, err := isUpToDate(ctx, client, rawRef, ocispecPlatforms, unpack, options)
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to check whether %q is up to date", rawRef)
+		} else if upToDate {
+			log.G(ctx).Infof("image %q is up to date", name)
+			if len(labels) > 0 {
+				return updateImageLabels(ctx, client, name, labels, nil)
+			}
+			return nil
+		}
+	}
+
+	var (
+		recorder  imgutil.RegistryMetadataRecorder
+		extraOpts []dockerconfigresolver.Opt
+	)
+	if options.StoreMetadata {
+		extraOpts = append(extraOpts, recorder.Opt())
+	}
+	var ensured *imgutil.EnsuredImage
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		ensured, err = EnsureImage(ctx, client, rawRef, ocispecPlatforms, "always", unpack, options.Quiet, options, extraOpts...)
+		if err == nil {
+			break
+		}
+		if attempt > options.Retry || !isRetryablePullError(ctx, err) {
+			return err
+		}
+		delay := pullBackoff(attempt, options.RetryBackoff, options.RetryBackoffMax, rand.Float64())
+		if options.RetryMaxDuration > 0 && time.Since(start)+delay > options.RetryMaxDuration {
+			return fmt.Errorf("%w (giving up after %d attempts, as the next retry would exceed the retry max duration %s)", err, attempt, options.RetryMaxDuration)
+		}
+		log.G(ctx).Infof("failed to pull %q (attempt %d/%d): %v, retrying in %s", rawRef, attempt, options.Retry+1, err, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	var removed []string
+	if options.StoreMetadata {
+		metadata := recorder.Labels()
+		for _, k := range []string{imgutil.RegistryETagLabel, imgutil.RegistryLastModifiedLabel, imgutil.RegistryCacheControlLabel} {
+			if _, ok := labels[k]; ok {
+				continue
+			}
+			if v, ok := metadata[k]; ok {
+				labels[k] = v
+			} else {
+				// drop the stale metadata of a previous pull
+				removed = append(removed, k)
+			}
+		}
+	}
+	if len(labels) > 0 || len(removed) > 0 {
+		return updateImageLabels(ctx, client, ensured.Image.Name(), labels, removed)
+	}
+	return nil
+}
+
+// isUpToDate returns whether the local image record of rawRef is the same as on the registry, according to the
+// registry metadata stored by a previous `nerdctl pull --store-metadata`, and its content is available locally.
+// It also returns the name of the image record.
+func isUpToDate(ctx context.Context, client *containerd.Client, rawRef string, ocispecPlatforms []v1.Platform, unpack *bool, options types.ImagePullOptions) (string, bool, error) {
+	if options.VerifyOptions.Provider != "none" {
+		// the signature has to be verified against the registry anyway
+		return "", false, nil
+	}
+	named, err := refdocker.ParseDockerRef(rawRef)
 	if err != nil {
-		return err
+		return "", false, err
+	}
+	name := named.String()
+	img, err := client.ImageService().Get(ctx, name)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return name, false, nil
+		}
+		return name, false, err
+	}
+	platMC := platformutil.NewMatchComparerFromOCISpecPlatformSlice(ocispecPlatforms)
+	if avail, _, _, _, err := images.Check(ctx, client.ContentStore(), img.Target, platMC); err != nil || !avail {
+		return name, false, err
+	}
+	// same as imgutil.PullImage: unpack if given 1 platform unless specified by `unpack`
+	unpackB := len(ocispecPlatforms) == 1
+	if unpack != nil {
+		unpackB = *unpack && unpackB
+	}
+	if unpackB {
+		unpacked, err := containerd.NewImageWithPlatform(client, img, platforms.OnlyStrict(ocispecPlatforms[0])).IsUnpacked(ctx, options.GOptions.Snapshotter)
+		if err != nil || !unpacked {
+			return name, false, err
+		}
+	}
+	notModified, err := imgutil.CheckNotModified(ctx, name, img.Labels, options.GOptions.InsecureRegistry, options.GOptions.HostsDir)
+	return name, notModified, err
+}
+
+// pullBackoff returns the delay before the retry after the failed `attempt` (1-based),
+// i.e., base * 2^(attempt-1) capped at max, with ±10% jitter taken from `r` in [0, 1).
+func pullBackoff(attempt int, base, max time.Duration, r float64) time.Duration {
+	delay := max
+	if shift := attempt - 1; shift < 32 && base<<shift > 0 && base<<shift < max {
+		delay = base << shift
+	}
+	return time.Duration(float64(delay) * (0.9 + 0.2*r))
+}
+
+// isRetryablePullError returns false for the errors that a retry cannot fix, e.g., a missing image.
+func isRetryablePullError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
 	}
+	return !errdefs.IsNotFound(err) && !errdefs.IsInvalidArgument(err)
+}
 
+// updateImageLabels merges `labels` into the labels of the image record `name`, and removes the labels `removed`.
+func updateImageLabels(ctx context.Context, client *containerd.Client, name string, labels map[string]string, removed []string) error {
+	is := client.ImageService()
+	img, err := is.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if img.Labels == nil {
+		img.Labels = make(map[string]string, len(labels))
+	}
+	fieldpaths := make([]string, 0, len(labels)+len(removed))
+	for k, v := range labels {
+		img.Labels[k] = v
+		fieldpaths = append(fieldpaths, "labels."+k)
+	}
+	for _, k := range removed {
+		if _, ok := img.Labels[k]; !ok {
+			continue
+		}
+		delete(img.Labels, k)
+		fieldpaths = append(fieldpaths, "labels."+k)
+	}
+	if len(fieldpaths) == 0 {
+		return nil
+	}
+	if _, err := is.Update(ctx, img, fieldpaths...); err != nil {
+		return fmt.Errorf("failed to set the labels of image %q: %w", name, err)
+	}
 	return nil
 }
 
 // EnsureImage pulls an image either from ipfs or from registry.
-func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string, ocispecPlatforms []v1.Platform, pull string, unpack *bool, quiet bool, options types.ImagePullOptions) (*imgutil.EnsuredImage, error) {
+// `extraOpts` are passed to dockerconfigresolver for the registry.
+func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string, ocispecPlatforms []v1.Platform, pull string, unpack *bool, quiet bool, options types.ImagePullOptions, extraOpts ...dockerconfigresolver.Opt) (*imgutil.EnsuredImage, error) {
 	var ensured *imgutil.EnsuredImage
 
 	if scheme, ref, err := referenceutil.ParseIPFSRefWithScheme(rawRef); err == nil {
@@ -76,7 +237,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string,
 		}
 
 		ensured, err = ipfs.EnsureImage(ctx, client, options.Stdout, options.Stderr, options.GOptions.Snapshotter, scheme, ref,
-			pull, ocispecPlatforms, unpack, quiet, ipfsPath, options.RFlags)
+			pull, ocispecPlatforms, unpack, quiet, options.ProgressOutput, ipfsPath, options.RFlags)
 		if err != nil {
 			return nil, err
 		}
@@ -89,7 +250,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, rawRef string,
 	}
 
 	ensured, err = imgutil.EnsureImage(ctx, client, options.Stdout, options.Stderr, options.GOptions.Snapshotter, ref,
-		pull, options.GOptions.InsecureRegistry, options.GOptions.HostsDir, ocispecPlatforms, unpack, quiet, options.RFlags)
+		pull, options.GOptions.InsecureRegistry, options.GOptions.HostsDir, ocispecPlatforms, unpack, quiet, options.ProgressOutput, options.RFlags, extraOpts...)
 	if err != nil {
 		return nil, err
 	}
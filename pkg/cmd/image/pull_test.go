@@ -0,0 +1,45 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPullBackoff(t *testing.T) {
+	t.Parallel()
+	const (
+		base = time.Second
+		max  = 30 * time.Second
+	)
+	// without jitter (r = 0.5)
+	var delays []time.Duration
+	for attempt := 1; attempt <= 7; attempt++ {
+		delays = append(delays, pullBackoff(attempt, base, max, 0.5))
+	}
+	assert.DeepEqual(t, delays, []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second})
+
+	// ±10% jitter
+	assert.Equal(t, pullBackoff(3, base, max, 0), 3600*time.Millisecond)
+	assert.Assert(t, pullBackoff(3, base, max, 0.999999) < 4400*time.Millisecond)
+
+	// no overflow for a large attempt
+	assert.Equal(t, pullBackoff(100, base, max, 0.5), max)
+}
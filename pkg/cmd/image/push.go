@@ -18,15 +18,19 @@ package image
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
 	"github.com/containerd/containerd/images"
 	"github.com/containerd/containerd/images/converter"
+	"github.com/containerd/containerd/platforms"
 	"github.com/containerd/containerd/reference"
 	refdocker "github.com/containerd/containerd/reference/docker"
 	"github.com/containerd/containerd/remotes"
@@ -107,6 +111,14 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 			return fmt.Errorf("failed to create a tmp reduced-platform image %q (platform=%v): %w", pushRef, options.Platforms, err)
 		}
 		defer client.ImageService().Delete(ctx, platImg.Name, images.SynchronousDelete())
+		if len(options.Platforms) == 1 {
+			// Push the manifest of the platform alone, not wrapped in an index,
+			// so that the manifests of each platform can be pushed separately and merged into a manifest list later.
+			platImg, err = unwrapSingleManifestIndex(ctx, client, platImg)
+			if err != nil {
+				return fmt.Errorf("failed to create a tmp single-platform image %q (platform=%v): %w", pushRef, options.Platforms, err)
+			}
+		}
 		log.G(ctx).Infof("pushing as a reduced-platform image (%s, %s)", platImg.Target.MediaType, platImg.Target.Digest)
 	}
 
@@ -117,9 +129,28 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 			return fmt.Errorf("failed to convert to eStargz: %v", err)
 		}
 		defer client.ImageService().Delete(ctx, esgzImg.Name, images.SynchronousDelete())
+		if len(options.Platforms) == 1 {
+			esgzImg, err = unwrapSingleManifestIndex(ctx, client, esgzImg)
+			if err != nil {
+				return fmt.Errorf("failed to convert to eStargz: %v", err)
+			}
+		}
 		log.G(ctx).Infof("pushing as an eStargz image (%s, %s)", esgzImg.Target.MediaType, esgzImg.Target.Digest)
 	}
 
+	// With multiple platforms, the manifest of each platform is pushed under its own tag (e.g., "latest-amd64")
+	// before the manifest list, so that the platforms can also be pulled separately.
+	var platformPushes []platformPush
+	if !options.AllPlatforms && len(options.Platforms) > 1 {
+		platformPushes, err = createPlatformImages(ctx, client, named, pushRef, platMC)
+		for _, pp := range platformPushes {
+			defer client.ImageService().Delete(ctx, pp.localRef, images.SynchronousDelete())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create the tmp images of the platforms of %q: %w", ref, err)
+		}
+	}
+
 	// In order to push images where most layers are the same but the
 	// repository name is different, it is necessary to refresh the
 	// PushTracker. Otherwise, the MANIFEST_BLOB_UNKNOWN error will occur due
@@ -128,6 +159,14 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 	pushTracker := docker.NewInMemoryTracker()
 
 	pushFunc := func(r remotes.Resolver) error {
+		for _, pp := range platformPushes {
+			if err := push.Push(ctx, client, r, pushTracker, options.Stdout, pp.localRef, pp.remoteRef, platMC, options.AllowNondistributableArtifacts, options.Quiet); err != nil {
+				return fmt.Errorf("failed to push %q: %w", pp.remoteRef, err)
+			}
+			if options.Quiet {
+				fmt.Fprintln(options.Stdout, pp.remoteRef)
+			}
+		}
 		return push.Push(ctx, client, r, pushTracker, options.Stdout, pushRef, ref, platMC, options.AllowNondistributableArtifacts, options.Quiet)
 	}
 
@@ -196,6 +235,124 @@ func Push(ctx context.Context, client *containerd.Client, rawRef string, options
 	return nil
 }
 
+// unwrapSingleManifestIndex updates the image to point to the manifest of its index, when the index has exactly one manifest.
+// Other images are returned as-is.
+func unwrapSingleManifestIndex(ctx context.Context, client *containerd.Client, img *images.Image) (*images.Image, error) {
+	if !images.IsIndexType(img.Target.MediaType) {
+		return img, nil
+	}
+	b, err := content.ReadBlob(ctx, client.ContentStore(), img.Target)
+	if err != nil {
+		return img, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return img, err
+	}
+	if len(index.Manifests) != 1 || !images.IsManifestType(index.Manifests[0].MediaType) {
+		return img, nil
+	}
+	unwrapped := *img
+	unwrapped.Target = index.Manifests[0]
+	// the platform is a property of the index entry, not of the manifest
+	unwrapped.Target.Platform = nil
+	updated, err := client.ImageService().Update(ctx, unwrapped, "target")
+	if err != nil {
+		return img, err
+	}
+	return &updated, nil
+}
+
+// platformPush is a tmp image of the manifest of a platform, to be pushed under the tag of the platform.
+type platformPush struct {
+	localRef  string
+	remoteRef string
+}
+
+// createPlatformImages creates a tmp image for each manifest of the index of pushRef that matches platMC
+// and is available locally, to push it under the tag of named suffixed with the platform, e.g., "example.com/foo:latest-arm64".
+// Nothing is created when pushRef is not an index.
+func createPlatformImages(ctx context.Context, client *containerd.Client, named refdocker.Named, pushRef string, platMC platforms.MatchComparer) ([]platformPush, error) {
+	tagged, ok := named.(refdocker.Tagged)
+	if !ok {
+		return nil, fmt.Errorf("the manifests of the platforms cannot be tagged without the tag of the image, got %q", named.String())
+	}
+	is := client.ImageService()
+	img, err := is.Get(ctx, pushRef)
+	if err != nil {
+		return nil, err
+	}
+	if !images.IsIndexType(img.Target.MediaType) {
+		return nil, nil
+	}
+	b, err := content.ReadBlob(ctx, client.ContentStore(), img.Target)
+	if err != nil {
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, err
+	}
+	var (
+		pushes []platformPush
+		seen   = make(map[string]struct{})
+	)
+	for _, m := range index.Manifests {
+		if !images.IsManifestType(m.MediaType) || m.Platform == nil || !platMC.Match(*m.Platform) {
+			continue
+		}
+		// BuildKit attaches the attestation manifests as "unknown/unknown", which are not pushed under a tag
+		if m.Platform.OS == "unknown" && m.Platform.Architecture == "unknown" {
+			continue
+		}
+		// an index may be pulled only for some of its platforms
+		if avail, _, _, _, err := images.Check(ctx, client.ContentStore(), m, platforms.All); err != nil || !avail {
+			log.G(ctx).WithError(err).Debugf("skipping the tag of the platform %q, as its content is not available locally", platforms.Format(*m.Platform))
+			continue
+		}
+		suffix := platformTagSuffix(*m.Platform)
+		if _, ok := seen[suffix]; ok {
+			return pushes, fmt.Errorf("multiple manifests for the platform %q", platforms.Format(*m.Platform))
+		}
+		seen[suffix] = struct{}{}
+		remote, err := refdocker.WithTag(refdocker.TrimNamed(named), tagged.Tag()+"-"+suffix)
+		if err != nil {
+			return pushes, err
+		}
+		target := m
+		// the platform is a property of the index entry, not of the manifest
+		target.Platform = nil
+		platImg := images.Image{
+			Name:   pushRef + "-" + suffix,
+			Target: target,
+		}
+		if _, err := is.Create(ctx, platImg); err != nil {
+			if !errdefs.IsAlreadyExists(err) {
+				return pushes, err
+			}
+			if _, err := is.Update(ctx, platImg, "target"); err != nil {
+				return pushes, err
+			}
+		}
+		pushes = append(pushes, platformPush{localRef: platImg.Name, remoteRef: remote.String()})
+	}
+	return pushes, nil
+}
+
+// platformTagSuffix returns the suffix of the tag of a platform, e.g., "amd64", "arm-v7", or "windows-amd64".
+// The OS is omitted for Linux.
+func platformTagSuffix(p ocispec.Platform) string {
+	var parts []string
+	if p.OS != "" && p.OS != "linux" {
+		parts = append(parts, p.OS)
+	}
+	parts = append(parts, p.Architecture)
+	if p.Variant != "" {
+		parts = append(parts, p.Variant)
+	}
+	return strings.Join(parts, "-")
+}
+
 func eStargzConvertFunc() converter.ConvertFunc {
 	convertToESGZ := estargzconvert.LayerConvertFunc()
 	return func(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Descriptor, error) {
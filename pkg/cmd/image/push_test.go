@@ -0,0 +1,38 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestPlatformTagSuffix(t *testing.T) {
+	for _, tc := range []struct {
+		platform ocispec.Platform
+		expected string
+	}{
+		{ocispec.Platform{OS: "linux", Architecture: "amd64"}, "amd64"},
+		{ocispec.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, "arm-v7"},
+		{ocispec.Platform{OS: "windows", Architecture: "amd64"}, "windows-amd64"},
+		{ocispec.Platform{Architecture: "arm64"}, "arm64"},
+	} {
+		assert.Equal(t, platformTagSuffix(tc.platform), tc.expected)
+	}
+}
@@ -28,7 +28,9 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
 )
 
 // Remove removes a list of `images`.
@@ -88,7 +90,14 @@ func Remove(ctx context.Context, client *containerd.Client, args []string, optio
 			for _, digest := range digests {
 				fmt.Fprintf(options.Stdout, "Deleted: %s\n", digest)
 			}
-			return nil
+			if options.NoPrune || len(digests) == 0 {
+				return nil
+			}
+			return removeDanglingParents(ctx, client, found.Image.Target.Digest, digests, func(img images.Image) bool {
+				_, running := runningImages[img.Name]
+				_, used := usedImages[img.Name]
+				return running || used
+			}, delOpts, options)
 		},
 	}
 
@@ -116,3 +125,78 @@ func Remove(ctx context.Context, client *containerd.Client, args []string, optio
 	}
 	return nil
 }
+
+// removeDanglingParents removes the dangling parents of the image `target` that was just removed with the layers `diffIDs`,
+// i.e., the images without any tagged record (e.g., only "name@sha256:...") whose layers are a strict prefix of `diffIDs`.
+// Nothing is removed while another image record still refers to `target`, e.g., another tag of the same image.
+// The images for which `inUse` returns true are kept, along with the other records of the same target.
+func removeDanglingParents(ctx context.Context, client *containerd.Client, target digest.Digest, diffIDs []digest.Digest, inUse func(images.Image) bool, delOpts []images.DeleteOpt, options types.ImageRemoveOptions) error {
+	is := client.ImageService()
+	imageList, err := is.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, img := range imageList {
+		if img.Target.Digest == target {
+			return nil
+		}
+	}
+	// the targets still referred to by a tag or by a container are not dangling
+	kept := taggedTargets(imageList)
+	for _, img := range imageList {
+		if inUse(img) {
+			kept[img.Target.Digest] = struct{}{}
+		}
+	}
+	// whether each of the dangling targets is a parent, and whether it has been printed as deleted
+	parents := make(map[digest.Digest]bool)
+	deleted := make(map[digest.Digest]bool)
+	for _, img := range imageList {
+		if _, ok := kept[img.Target.Digest]; ok {
+			continue
+		}
+		isParent, ok := parents[img.Target.Digest]
+		if !ok {
+			parentDiffIDs, err := img.RootFS(ctx, client.ContentStore(), platforms.DefaultStrict())
+			if err != nil {
+				log.G(ctx).WithError(err).Debugf("failed to enumerate rootfs of %q", img.Name)
+			}
+			isParent = err == nil && isStrictPrefix(parentDiffIDs, diffIDs)
+			parents[img.Target.Digest] = isParent
+		}
+		if !isParent {
+			continue
+		}
+		if err := is.Delete(ctx, img.Name, delOpts...); err != nil {
+			return err
+		}
+		if !deleted[img.Target.Digest] {
+			deleted[img.Target.Digest] = true
+			fmt.Fprintf(options.Stdout, "Deleted: %s\n", img.Target.Digest)
+		}
+	}
+	return nil
+}
+
+// taggedTargets returns the set of the target digests referred to by any tagged image record.
+func taggedTargets(imageList []images.Image) map[digest.Digest]struct{} {
+	tagged := make(map[digest.Digest]struct{})
+	for _, img := range imageList {
+		if _, tag := imgutil.ParseRepoTag(img.Name); tag != "" {
+			tagged[img.Target.Digest] = struct{}{}
+		}
+	}
+	return tagged
+}
+
+func isStrictPrefix(prefix, s []digest.Digest) bool {
+	if len(prefix) == 0 || len(prefix) >= len(s) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
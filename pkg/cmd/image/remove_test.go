@@ -0,0 +1,47 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"testing"
+
+	"github.com/containerd/containerd/images"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestTaggedTargets(t *testing.T) {
+	parent := digest.FromString("parent")
+	child := digest.FromString("child")
+	imageList := []images.Image{
+		// a dangling parent, only with the digest-pinned record
+		{Name: "docker.io/library/parent@" + parent.String(), Target: ocispec.Descriptor{Digest: parent}},
+		{Name: "docker.io/library/child@" + child.String(), Target: ocispec.Descriptor{Digest: child}},
+		{Name: "docker.io/library/child:latest", Target: ocispec.Descriptor{Digest: child}},
+	}
+	assert.DeepEqual(t, taggedTargets(imageList), map[digest.Digest]struct{}{child: {}})
+}
+
+func TestIsStrictPrefix(t *testing.T) {
+	a, b, c := digest.FromString("a"), digest.FromString("b"), digest.FromString("c")
+	assert.Assert(t, isStrictPrefix([]digest.Digest{a}, []digest.Digest{a, b}))
+	assert.Assert(t, isStrictPrefix([]digest.Digest{a, b}, []digest.Digest{a, b, c}))
+	assert.Assert(t, !isStrictPrefix([]digest.Digest{a, b}, []digest.Digest{a, b}))
+	assert.Assert(t, !isStrictPrefix([]digest.Digest{b}, []digest.Digest{a, b}))
+	assert.Assert(t, !isStrictPrefix(nil, []digest.Digest{a}))
+}
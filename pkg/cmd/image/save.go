@@ -17,11 +17,16 @@
 package image
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
 
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/images/archive"
+	"github.com/containerd/containerd/pkg/progress"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
@@ -62,5 +67,71 @@ func Save(ctx context.Context, client *containerd.Client, images []string, optio
 		return err
 	}
 
-	return client.Export(ctx, options.Stdout, exportOpts...)
+	var (
+		out          = options.Stdout
+		stopProgress = func() {}
+	)
+	if options.ProgressOutput != nil {
+		// The progress counts the bytes actually written, i.e., after the compression
+		w := &countingWriter{w: out}
+		stopProgress = showSaveProgress(w, options.ProgressOutput)
+		out = w
+	}
+	if options.Compress {
+		gw := gzip.NewWriter(out)
+		err = client.Export(ctx, gw, exportOpts...)
+		// Close flushes the remaining compressed data and writes the gzip footer
+		if closeErr := gw.Close(); err == nil {
+			err = closeErr
+		}
+	} else {
+		err = client.Export(ctx, out, exportOpts...)
+	}
+	stopProgress()
+	return err
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n atomic.Int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n.Add(int64(n))
+	return n, err
+}
+
+// showSaveProgress displays the bytes written to cw on out, until the returned func is called.
+func showSaveProgress(cw *countingWriter, out io.Writer) func() {
+	var (
+		fw     = progress.NewWriter(out)
+		start  = time.Now()
+		done   = make(chan struct{})
+		exited = make(chan struct{})
+	)
+	display := func() {
+		n := cw.n.Load()
+		fmt.Fprintf(fw, "saving: %v (%v)\n", progress.Bytes(n), progress.NewBytesPerSecond(n, time.Since(start)))
+		fw.Flush()
+	}
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				display()
+			case <-done:
+				display()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
 }
@@ -18,17 +18,20 @@ package image
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 )
 
+// Tag creates the tags `options.Target` and `options.Targets` that refer to `options.Source`.
+// An error on a target is reported without aborting the remaining targets.
 func Tag(ctx context.Context, client *containerd.Client, options types.ImageTagOptions) error {
-	imageService := client.ImageService()
 	var srcName string
 	imagewalker := &imagewalker.ImageWalker{
 		Client: client,
@@ -47,33 +50,75 @@ func Tag(ctx context.Context, client *containerd.Client, options types.ImageTagO
 		return fmt.Errorf("%s: not found", options.Source)
 	}
 
-	target, err := referenceutil.ParseDockerRef(options.Target)
+	ctx, done, err := client.WithLease(ctx)
 	if err != nil {
 		return err
 	}
+	defer done(ctx)
 
-	ctx, done, err := client.WithLease(ctx)
+	var errs []error
+	targets := options.Targets
+	if options.Target != "" {
+		targets = append([]string{options.Target}, targets...)
+	}
+	for _, target := range targets {
+		if err := tag(ctx, client, srcName, target, options); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func tag(ctx context.Context, client *containerd.Client, srcName, targetName string, options types.ImageTagOptions) error {
+	imageService := client.ImageService()
+	target, err := referenceutil.ParseDockerRef(targetName)
 	if err != nil {
 		return err
 	}
-	defer done(ctx)
 
 	image, err := imageService.Get(ctx, srcName)
 	if err != nil {
 		return err
 	}
 	image.Name = target.String()
-	if _, err = imageService.Create(ctx, image); err != nil {
-		if errdefs.IsAlreadyExists(err) {
-			if err = imageService.Delete(ctx, image.Name); err != nil {
-				return err
-			}
-			if _, err = imageService.Create(ctx, image); err != nil {
-				return err
-			}
-		} else {
+	// The tag may be created, moved, or removed by a concurrent command between the Get and the Create (or the Update),
+	// so such a conflict is retried against the current state of the tag, instead of failing.
+	for attempt := 1; ; attempt++ {
+		err = tagImage(ctx, imageService, image, options)
+		if attempt < maxTagAttempts && (errdefs.IsAlreadyExists(err) || errdefs.IsNotFound(err)) {
+			continue
+		}
+		return err
+	}
+}
+
+// maxTagAttempts is the number of the attempts of tagImage on the conflicts with the concurrent commands.
+const maxTagAttempts = 5
+
+// tagImage creates the tag `image.Name`, or moves it with `--force`.
+// It returns an AlreadyExists or a NotFound error when the tag was created or removed concurrently.
+func tagImage(ctx context.Context, imageService images.Store, image images.Image, options types.ImageTagOptions) error {
+	existing, err := imageService.Get(ctx, image.Name)
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
 			return err
 		}
+		if _, err = imageService.Create(ctx, image); err != nil {
+			return err
+		}
+		fmt.Fprintf(options.Stdout, "Created tag %s (%s)\n", image.Name, image.Target.Digest)
+		return nil
+	}
+	if existing.Target.Digest == image.Target.Digest {
+		fmt.Fprintf(options.Stdout, "Tag %s is unchanged (%s)\n", image.Name, image.Target.Digest)
+		return nil
+	}
+	if !options.Force {
+		return fmt.Errorf("tag %s already refers to %s, not to %s (use --force to move it)", image.Name, existing.Target.Digest, image.Target.Digest)
+	}
+	if _, err = imageService.Update(ctx, image); err != nil {
+		return err
 	}
+	fmt.Fprintf(options.Stdout, "Moved tag %s (%s -> %s)\n", image.Name, existing.Target.Digest, image.Target.Digest)
 	return nil
 }
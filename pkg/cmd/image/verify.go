@@ -0,0 +1,84 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/containerd/containerd"
+	refdocker "github.com/containerd/containerd/reference/docker"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/signutil"
+)
+
+// Verify verifies the signatures of the local images against the trust policy, and prints the result of each image.
+// An error is returned if any of the images fails the verification.
+func Verify(ctx context.Context, client *containerd.Client, reqs []string, options types.ImageVerifyPolicyOptions) error {
+	policy, err := signutil.LoadPolicy(options.Policy)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(options.Stdout, 4, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tRESULT\tDETAIL")
+	failed := 0
+	for _, req := range reqs {
+		rule, err := verifyImage(ctx, client, policy, req, options)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\tFAILED\t%v\n", req, err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\tVERIFIED\t%s (scope %q)\n", req, rule.Provider, rule.Scope)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images failed the verification", failed, len(reqs))
+	}
+	return nil
+}
+
+// verifyImage verifies the local image `req` with the rule of the policy applying to it.
+// The image is verified by its local digest, not by the digest of the tag in the registry.
+func verifyImage(ctx context.Context, client *containerd.Client, policy *signutil.Policy, req string, options types.ImageVerifyPolicyOptions) (*signutil.PolicyRule, error) {
+	img, err := findImage(ctx, client, req)
+	if err != nil {
+		return nil, err
+	}
+	named, err := refdocker.ParseDockerRef(img.Name)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := policy.Match(img.Name)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("no rule of the trust policy applies to %q", named.Name())
+	}
+	digested, err := refdocker.WithDigest(refdocker.TrimNamed(named), img.Target.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := signutil.Verify(ctx, digested.String(), options.GOptions.HostsDir, options.GOptions.Experimental, rule.VerifyOptions()); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
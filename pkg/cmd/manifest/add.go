@@ -0,0 +1,80 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/imagewalker"
+	"github.com/containerd/platforms"
+)
+
+// Add adds the manifests of the images to the manifest list.
+// A manifest replaces the one of the same platform already in the list.
+func Add(ctx context.Context, client *containerd.Client, list string, imgs []string, options types.ManifestAddOptions) error {
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	listImg, index, err := getList(ctx, client, list)
+	if err != nil {
+		return err
+	}
+	cs := client.ContentStore()
+	for _, req := range imgs {
+		walker := &imagewalker.ImageWalker{
+			Client: client,
+			OnFound: func(ctx context.Context, found imagewalker.Found) error {
+				if found.UniqueImages > 1 {
+					return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+				}
+				if found.MatchIndex > 0 {
+					return nil
+				}
+				descs, err := platformManifests(ctx, cs, found.Image)
+				if err != nil {
+					return err
+				}
+				for _, desc := range descs {
+					index.Manifests = addManifest(index.Manifests, desc)
+					fmt.Fprintf(options.Stdout, "Added %s (%s) to %s\n", desc.Digest, platforms.Format(*desc.Platform), listImg.Name)
+				}
+				return nil
+			},
+		}
+		n, err := walker.Walk(ctx, req)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("%s: not found", req)
+		}
+	}
+
+	desc, err := writeIndex(ctx, client, index)
+	if err != nil {
+		return err
+	}
+	listImg.Target = desc
+	_, err = client.ImageService().Update(ctx, listImg, "target")
+	return err
+}
@@ -0,0 +1,80 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/log"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Create creates a manifest list with the manifests of `options.Images`.
+// The list is removed again if the manifests cannot be added, so that it can be created again.
+func Create(ctx context.Context, client *containerd.Client, list string, options types.ManifestCreateOptions) error {
+	named, err := referenceutil.ParseDockerRef(list)
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := client.WithLease(ctx)
+	if err != nil {
+		return err
+	}
+	defer done(ctx)
+
+	desc, err := writeIndex(ctx, client, &ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{},
+	})
+	if err != nil {
+		return err
+	}
+	img := images.Image{
+		Name:   named.String(),
+		Target: desc,
+		Labels: map[string]string{ListLabel: "true"},
+	}
+	if _, err := client.ImageService().Create(ctx, img); err != nil {
+		if errdefs.IsAlreadyExists(err) {
+			return fmt.Errorf("image %s already exists", img.Name)
+		}
+		return err
+	}
+	fmt.Fprintf(options.Stdout, "Created manifest list %s\n", img.Name)
+	if len(options.Images) == 0 {
+		return nil
+	}
+	if err := Add(ctx, client, img.Name, options.Images, types.ManifestAddOptions{
+		Stdout:   options.Stdout,
+		GOptions: options.GOptions,
+	}); err != nil {
+		if delErr := client.ImageService().Delete(ctx, img.Name, images.SynchronousDelete()); delErr != nil {
+			log.G(ctx).WithError(delErr).Warnf("failed to remove the manifest list %s", img.Name)
+		}
+		return err
+	}
+	return nil
+}
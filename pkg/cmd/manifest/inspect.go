@@ -0,0 +1,40 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// Inspect prints the index of the manifest list.
+func Inspect(ctx context.Context, client *containerd.Client, list string, options types.ManifestInspectOptions) error {
+	_, index, err := getList(ctx, client, list)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(index, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(options.Stdout, string(b))
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package manifest implements `nerdctl manifest`.
+//
+// A manifest list is stored as an image whose target is an OCI index, labeled with ListLabel.
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+	"github.com/containerd/platforms"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ListLabel is the label of the images created by `nerdctl manifest create`.
+const ListLabel = "nerdctl/manifest-list"
+
+// getList returns the manifest list image and its index.
+func getList(ctx context.Context, client *containerd.Client, rawRef string) (images.Image, *ocispec.Index, error) {
+	named, err := referenceutil.ParseDockerRef(rawRef)
+	if err != nil {
+		return images.Image{}, nil, err
+	}
+	img, err := client.ImageService().Get(ctx, named.String())
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return images.Image{}, nil, fmt.Errorf("no such manifest list: %s", rawRef)
+		}
+		return images.Image{}, nil, err
+	}
+	if _, ok := img.Labels[ListLabel]; !ok {
+		return images.Image{}, nil, fmt.Errorf("%s is not a manifest list created by `nerdctl manifest create`", rawRef)
+	}
+	b, err := content.ReadBlob(ctx, client.ContentStore(), img.Target)
+	if err != nil {
+		return images.Image{}, nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return images.Image{}, nil, err
+	}
+	return img, &index, nil
+}
+
+// writeIndex writes the index to the content store, with the GC labels of the manifests.
+func writeIndex(ctx context.Context, client *containerd.Client, index *ocispec.Index) (ocispec.Descriptor, error) {
+	b, err := json.Marshal(index)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(b),
+		Size:      int64(len(b)),
+	}
+	labels := make(map[string]string)
+	for i, m := range index.Manifests {
+		labels[fmt.Sprintf("containerd.io/gc.ref.content.m.%d", i)] = m.Digest.String()
+	}
+	if err := content.WriteBlob(ctx, client.ContentStore(), desc.Digest.String(), bytes.NewReader(b), desc, content.WithLabels(labels)); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// platformManifests returns the descriptors of the manifests of the image available in the local store,
+// with their platforms.
+func platformManifests(ctx context.Context, cs content.Store, img images.Image) ([]ocispec.Descriptor, error) {
+	switch {
+	case images.IsManifestType(img.Target.MediaType):
+		desc := img.Target
+		if desc.Platform == nil {
+			p, err := manifestPlatform(ctx, cs, desc)
+			if err != nil {
+				return nil, err
+			}
+			desc.Platform = p
+		}
+		return []ocispec.Descriptor{desc}, nil
+	case images.IsIndexType(img.Target.MediaType):
+		b, err := content.ReadBlob(ctx, cs, img.Target)
+		if err != nil {
+			return nil, err
+		}
+		var index ocispec.Index
+		if err := json.Unmarshal(b, &index); err != nil {
+			return nil, err
+		}
+		var descs []ocispec.Descriptor
+		for _, desc := range index.Manifests {
+			if !images.IsManifestType(desc.MediaType) {
+				continue
+			}
+			if _, err := cs.Info(ctx, desc.Digest); err != nil {
+				if errdefs.IsNotFound(err) {
+					// not pulled for this platform
+					continue
+				}
+				return nil, err
+			}
+			if desc.Platform == nil {
+				p, err := manifestPlatform(ctx, cs, desc)
+				if err != nil {
+					return nil, err
+				}
+				desc.Platform = p
+			}
+			descs = append(descs, desc)
+		}
+		if len(descs) == 0 {
+			return nil, fmt.Errorf("image %q has no manifest available in the local store", img.Name)
+		}
+		return descs, nil
+	default:
+		return nil, fmt.Errorf("image %q has an unsupported media type %q", img.Name, img.Target.MediaType)
+	}
+}
+
+// manifestPlatform reads the platform of the manifest from its config.
+func manifestPlatform(ctx context.Context, cs content.Store, desc ocispec.Descriptor) (*ocispec.Platform, error) {
+	b, err := content.ReadBlob(ctx, cs, desc)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+	b, err = content.ReadBlob(ctx, cs, manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, err
+	}
+	p := platforms.Normalize(config.Platform)
+	return &p, nil
+}
+
+// addManifest adds the manifest to the list, replacing the manifest of the same platform if any.
+func addManifest(manifests []ocispec.Descriptor, desc ocispec.Descriptor) []ocispec.Descriptor {
+	for i, m := range manifests {
+		if m.Platform != nil && desc.Platform != nil && platforms.Format(*m.Platform) == platforms.Format(*desc.Platform) {
+			manifests[i] = desc
+			return manifests
+		}
+	}
+	return append(manifests, desc)
+}
@@ -0,0 +1,52 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+)
+
+// Push pushes the manifest list, with the manifests and the blobs of all the platforms in it.
+func Push(ctx context.Context, client *containerd.Client, list string, options types.ManifestPushOptions) error {
+	img, index, err := getList(ctx, client, list)
+	if err != nil {
+		return err
+	}
+	if len(index.Manifests) == 0 {
+		return fmt.Errorf("manifest list %s is empty (hint: use `nerdctl manifest add`)", img.Name)
+	}
+	if err := image.Push(ctx, client, img.Name, types.ImagePushOptions{
+		Stdout:       options.Stdout,
+		GOptions:     options.GOptions,
+		AllPlatforms: true,
+		Quiet:        options.Quiet,
+	}); err != nil {
+		return err
+	}
+	if options.Purge {
+		return Remove(ctx, client, []string{img.Name}, types.ManifestRemoveOptions{
+			Stdout:   options.Stdout,
+			GOptions: options.GOptions,
+		})
+	}
+	return nil
+}
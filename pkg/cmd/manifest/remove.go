@@ -0,0 +1,42 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// Remove removes the manifest lists from the local store.
+// The manifests in the lists are kept, as they belong to the images they were added from.
+func Remove(ctx context.Context, client *containerd.Client, lists []string, options types.ManifestRemoveOptions) error {
+	for _, list := range lists {
+		img, _, err := getList(ctx, client, list)
+		if err != nil {
+			return err
+		}
+		if err := client.ImageService().Delete(ctx, img.Name, images.SynchronousDelete()); err != nil {
+			return err
+		}
+		fmt.Fprintf(options.Stdout, "Deleted: %s\n", img.Name)
+	}
+	return nil
+}
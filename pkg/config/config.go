@@ -28,6 +28,8 @@ import (
 type Config struct {
 	Debug            bool     `toml:"debug"`
 	DebugFull        bool     `toml:"debug_full"`
+	LogLevel         string   `toml:"log_level"`
+	LogFormat        string   `toml:"log_format"`
 	Address          string   `toml:"address"`
 	Namespace        string   `toml:"namespace"`
 	Snapshotter      string   `toml:"snapshotter"`
@@ -39,6 +41,21 @@ type Config struct {
 	HostsDir         []string `toml:"hosts_dir"`
 	Experimental     bool     `toml:"experimental"`
 	HostGatewayIP    string   `toml:"host_gateway_ip"`
+	Images           Images   `toml:"images"`
+}
+
+// Images corresponds to the [images] section of nerdctl.toml,
+// which holds the default flags of `nerdctl images`.
+type Images struct {
+	// Filters is the default value of `--filter`
+	Filters []string `toml:"filters"`
+	// Hide is the list of references (same patterns as `--filter=reference=...`) to hide,
+	// unless `--filter` is specified
+	Hide    []string `toml:"hide"`
+	Digests bool     `toml:"digests"`
+	Names   bool     `toml:"names"`
+	NoTrunc bool     `toml:"no_trunc"`
+	Format  string   `toml:"format"`
 }
 
 // New creates a default Config object statically,
@@ -47,6 +64,8 @@ func New() *Config {
 	return &Config{
 		Debug:            false,
 		DebugFull:        false,
+		LogLevel:         "info",
+		LogFormat:        "text",
 		Address:          defaults.DefaultAddress,
 		Namespace:        namespaces.Default,
 		Snapshotter:      containerd.DefaultSnapshotter,
@@ -42,7 +42,14 @@ func HandleConsoleResize(ctx gocontext.Context, task resizer, con console.Consol
 	s := make(chan os.Signal, 16)
 	signal.Notify(s, unix.SIGWINCH)
 	go func() {
-		for range s {
+		// stop resizing when the caller is done with the task, e.g., after `nerdctl exec -t` exits
+		defer signal.Stop(s)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s:
+			}
 			size, err := con.Size()
 			if err != nil {
 				log.G(ctx).WithError(err).Error("get pty size")
@@ -271,7 +271,7 @@ func Start(ctx context.Context, container containerd.Container, flagA bool, clie
 		}
 	}
 	detachC := make(chan struct{})
-	task, err := taskutil.NewTask(ctx, client, container, flagA, false, flagT, true, con, logURI, detachKeys, namespace, detachC)
+	task, err := taskutil.NewTask(ctx, client, container, flagA, false, flagT, true, con, nil, logURI, detachKeys, namespace, detachC)
 	if err != nil {
 		return err
 	}
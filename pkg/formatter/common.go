@@ -22,7 +22,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
 	"text/template"
+	"unicode"
 
 	"github.com/docker/cli/templates"
 )
@@ -36,20 +40,36 @@ type Flusher interface {
 //
 // --format="" (default): JSON
 // --format='{{json .}}': JSON lines
+// --format='table {{.Field1}}\t{{.Field2}}': a table with a header row, across all the elements
 //
 // FormatSlice is expected to be only used for `nerdctl OBJECT inspect` commands.
 func FormatSlice(format string, writer io.Writer, x []interface{}) error {
+	return formatSlice(format, writer, x, false)
+}
+
+// FormatSliceCompact is the same as FormatSlice, except that the default JSON output
+// is printed on a single line instead of being indented.
+func FormatSliceCompact(format string, writer io.Writer, x []interface{}) error {
+	return formatSlice(format, writer, x, true)
+}
+
+func formatSlice(format string, writer io.Writer, x []interface{}, compact bool) error {
 	var tmpl *template.Template
 	switch format {
 	case "":
-		b, err := json.MarshalIndent(x, "", "    ")
-		if err != nil {
+		encoder := json.NewEncoder(writer)
+		if !compact {
+			encoder.SetIndent("", "    ")
+		}
+		if err := encoder.Encode(x); err != nil {
 			return err
 		}
-		fmt.Fprintln(writer, string(b))
 	case "raw", "table", "wide":
 		return errors.New("unsupported format: \"raw\", \"table\", and \"wide\"")
 	default:
+		if IsTableFormat(format) {
+			return formatSliceTable(format, writer, x)
+		}
 		var err error
 		tmpl, err = ParseTemplate(format)
 		if err != nil {
@@ -73,6 +93,63 @@ func FormatSlice(format string, writer io.Writer, x []interface{}) error {
 	return nil
 }
 
+// IsTableFormat returns true for a "table {{.Field}}..." format.
+func IsTableFormat(format string) bool {
+	return strings.HasPrefix(format, "table ")
+}
+
+func formatSliceTable(format string, writer io.Writer, x []interface{}) error {
+	// Like Docker, accept a literal "\t" as the column separator
+	format = strings.ReplaceAll(strings.TrimPrefix(format, "table "), `\t`, "\t")
+	tmpl, err := ParseTemplate(format)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 4, 8, 4, ' ', 0)
+	if _, err := fmt.Fprintln(w, tableHeader(format)); err != nil {
+		return err
+	}
+	for _, f := range x {
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, f); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+var (
+	templateActionRegexp = regexp.MustCompile(`{{[^}]*}}`)
+	templateFieldRegexp  = regexp.MustCompile(`\.([A-Za-z0-9_]+)`)
+)
+
+// tableHeader returns the header row for a table format, by replacing each action
+// with the upper-cased name of the last field it refers to (e.g., "{{.RepoTags}}" -> "REPO TAGS").
+func tableHeader(format string) string {
+	return templateActionRegexp.ReplaceAllStringFunc(format, func(action string) string {
+		fields := templateFieldRegexp.FindAllStringSubmatch(action, -1)
+		if len(fields) == 0 {
+			return ""
+		}
+		return headerName(fields[len(fields)-1][1])
+	})
+}
+
+func headerName(field string) string {
+	var b strings.Builder
+	runes := []rune(field)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
 func tryRawFormat(b *bytes.Buffer, f interface{}, tmpl *template.Template) error {
 	m, err := json.MarshalIndent(f, "", "    ")
 	if err != nil {
@@ -82,10 +82,7 @@ func InspectContainerCommand(spec *oci.Spec, trunc, quote bool) string {
 		return ""
 	}
 
-	command := spec.Process.CommandLine + strings.Join(spec.Process.Args, " ")
-	if trunc {
-		command = Ellipsis(command, 20)
-	}
+	command := TruncateCommand(spec.Process.CommandLine+strings.Join(spec.Process.Args, " "), CommandWidth, !trunc)
 	if quote {
 		command = strconv.Quote(command)
 	}
@@ -96,6 +93,23 @@ func InspectContainerCommandTrunc(spec *oci.Spec) string {
 	return InspectContainerCommand(spec, true, true)
 }
 
+const (
+	// CommandWidth is the width of the COMMAND column of `nerdctl ps`.
+	CommandWidth = 20
+	// CreatedByWidth is the width of the CREATED BY column of `nerdctl image history`.
+	CreatedByWidth = 45
+)
+
+// TruncateCommand shortens a long command-like field (COMMAND, CREATED BY) to width,
+// unless noTrunc is set, i.e., unless `--no-trunc` is specified.
+// All the commands with such a field must consult this function, so that `--no-trunc` behaves the same.
+func TruncateCommand(command string, width int, noTrunc bool) string {
+	if noTrunc {
+		return command
+	}
+	return Ellipsis(command, width)
+}
+
 func Ellipsis(str string, maxDisplayWidth int) string {
 	if maxDisplayWidth <= 0 {
 		return ""
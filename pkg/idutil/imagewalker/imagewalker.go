@@ -23,9 +23,12 @@ import (
 	"strings"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
 	"github.com/containerd/containerd/images"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type Found struct {
@@ -43,8 +46,13 @@ type ImageWalker struct {
 	OnFound OnFound
 }
 
+// idPattern matches an ID or its prefix, with or without the "sha256:" algorithm.
+var idPattern = regexp.MustCompile(`^(sha256:)?[a-f0-9]+$`)
+
 // Walk walks images and calls w.OnFound .
 // Req is name, short ID, or long ID.
+// The ID is the digest of either the target (i.e., the index or the manifest) or the config, as printed by
+// `nerdctl images --format '{{.ID}}'` and `nerdctl image inspect`.
 // Returns the number of the found entries.
 func (w *ImageWalker) Walk(ctx context.Context, req string) (int, error) {
 	var filters []string
@@ -61,6 +69,12 @@ func (w *ImageWalker) Walk(ctx context.Context, req string) (int, error) {
 	if err != nil {
 		return -1, err
 	}
+	if len(images) == 0 && idPattern.MatchString(req) {
+		images, err = w.listByConfigDigest(ctx, req)
+		if err != nil {
+			return -1, err
+		}
+	}
 
 	matchCount := len(images)
 	// to handle the `rmi -f` case where returned images are different but
@@ -85,6 +99,62 @@ func (w *ImageWalker) Walk(ctx context.Context, req string) (int, error) {
 	return matchCount, nil
 }
 
+// listByConfigDigest returns the images with a config digest (of any platform available locally) matching the ID `req`.
+func (w *ImageWalker) listByConfigDigest(ctx context.Context, req string) ([]images.Image, error) {
+	imageList, err := w.Client.ImageService().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []images.Image
+	for _, img := range imageList {
+		configs, err := configDigests(ctx, w.Client.ContentStore(), img.Target)
+		if err != nil {
+			continue
+		}
+		for _, dgst := range configs {
+			if strings.HasPrefix(dgst.String(), req) || strings.HasPrefix(dgst.Encoded(), req) {
+				res = append(res, img)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// configDigests returns the config digests of the platforms of `target` whose manifests are available in `provider`.
+func configDigests(ctx context.Context, provider content.Provider, target ocispec.Descriptor) ([]digest.Digest, error) {
+	var res []digest.Digest
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if images.IsConfigType(desc.MediaType) {
+			res = append(res, desc.Digest)
+			return nil, nil
+		}
+		if !images.IsIndexType(desc.MediaType) && !images.IsManifestType(desc.MediaType) {
+			return nil, nil
+		}
+		children, err := images.Children(ctx, provider, desc)
+		if err != nil {
+			if errdefs.IsNotFound(err) && desc.Digest != target.Digest {
+				// the manifest of a platform that is not pulled
+				return nil, nil
+			}
+			return nil, err
+		}
+		// the layers are not read
+		var descs []ocispec.Descriptor
+		for _, child := range children {
+			if !images.IsLayerType(child.MediaType) {
+				descs = append(descs, child)
+			}
+		}
+		return descs, nil
+	})
+	if err := images.Walk(ctx, handler, target); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // WalkAll calls `Walk` for each req in `reqs`.
 //
 // It can be used when the matchCount is not important (e.g., only care if there
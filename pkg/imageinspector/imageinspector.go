@@ -46,6 +46,9 @@ func Inspect(ctx context.Context, client *containerd.Client, image images.Image,
 	} else {
 		n.ManifestDesc = maniDesc
 		n.Manifest = mani
+		if n.IndexDesc != nil && maniDesc != nil {
+			n.Platform = maniDesc.Platform
+		}
 	}
 
 	imageConfig, imageConfigDesc, err := imgutil.ReadImageConfig(ctx, img)
@@ -21,6 +21,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/containerd/containerd/remotes"
@@ -40,6 +41,8 @@ type opts struct {
 	skipVerifyCerts bool
 	hostsDirs       []string
 	authCreds       AuthCreds
+	requestHook     func(*http.Request)
+	responseHook    func(*http.Response)
 }
 
 // Opt for New
@@ -88,6 +91,41 @@ func WithAuthCreds(ac AuthCreds) Opt {
 	}
 }
 
+// WithRequestHook calls fn for every HTTP request to the registry, e.g., for setting the conditional request headers.
+// fn receives a clone of the request, so it may modify the request.
+func WithRequestHook(fn func(*http.Request)) Opt {
+	return func(o *opts) {
+		o.requestHook = fn
+	}
+}
+
+// WithResponseHook calls fn for every HTTP response from the registry, e.g., for reading the response headers
+func WithResponseHook(fn func(*http.Response)) Opt {
+	return func(o *opts) {
+		o.responseHook = fn
+	}
+}
+
+// hookTransport calls the hooks for every request and response of the underlying transport.
+type hookTransport struct {
+	http.RoundTripper
+	requestHook  func(*http.Request)
+	responseHook func(*http.Response)
+}
+
+func (t *hookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.requestHook != nil {
+		// a RoundTripper must not modify the request of the caller
+		req = req.Clone(req.Context())
+		t.requestHook(req)
+	}
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err == nil && t.responseHook != nil {
+		t.responseHook(resp)
+	}
+	return resp, err
+}
+
 // NewHostOptions instantiates a HostOptions struct using $DOCKER_CONFIG/config.json .
 //
 // $DOCKER_CONFIG defaults to "~/.docker".
@@ -140,6 +178,16 @@ func NewHostOptions(ctx context.Context, refHostname string, optFuncs ...Opt) (*
 		// https://github.com/containerd/containerd/issues/9208
 		ho.DefaultTLS = nil
 	}
+	if o.requestHook != nil || o.responseHook != nil {
+		ho.UpdateClient = func(client *http.Client) error {
+			rt := client.Transport
+			if rt == nil {
+				rt = http.DefaultTransport
+			}
+			client.Transport = &hookTransport{RoundTripper: rt, requestHook: o.requestHook, responseHook: o.responseHook}
+			return nil
+		}
+	}
 	return &ho, nil
 }
 
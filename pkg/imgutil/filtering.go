@@ -19,6 +19,7 @@ package imgutil
 import (
 	"context"
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -28,6 +29,8 @@ import (
 	dockerreference "github.com/containerd/containerd/reference/docker"
 	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/go-units"
 )
 
 // Filter types supported to filter images.
@@ -37,6 +40,10 @@ var (
 	FilterLabelType     = "label"
 	FilterReferenceType = "reference"
 	FilterDanglingType  = "dangling"
+	FilterSizeType      = "size"
+	FilterSchemaType    = "schema"
+	FilterUntilType     = "until"
+	FilterTagType       = "tag"
 )
 
 // Filters contains all types of filters to filter images.
@@ -45,13 +52,81 @@ type Filters struct {
 	Since     []string
 	Labels    map[string]string
 	Reference []string
-	Dangling  *bool
+	// ReferenceExclude is for `reference!=<pattern>` and `reference=!<pattern>`
+	ReferenceExclude []string
+	Dangling         *bool
+	Size             []SizeFilter
+	// Schema is "1" for the images with the deprecated Docker schema1 manifest, "2" for the others
+	Schema string
+	// Until is for `until=<timestamp>`, where the timestamp is absolute (e.g., 2006-01-02T15:04:05) or relative (e.g., 24h)
+	Until *time.Time
+	// Tag is for `tag=<tag>`, where "none" matches the images without a tag
+	Tag []string
+}
+
+// SizeFilter is a predicate like `size>500MB` on the unpacked size of an image.
+type SizeFilter struct {
+	Op   string
+	Size int64
+}
+
+// sizeFilterOps is ordered so that the two-character operators are tried first.
+var sizeFilterOps = []string{">=", "<=", "==", ">", "<"}
+
+// Match returns whether `size` satisfies the predicate.
+func (f SizeFilter) Match(size int64) bool {
+	switch f.Op {
+	case ">":
+		return size > f.Size
+	case "<":
+		return size < f.Size
+	case ">=":
+		return size >= f.Size
+	case "<=":
+		return size <= f.Size
+	case "==":
+		return size == f.Size
+	}
+	return false
+}
+
+// MatchSize returns whether `size` satisfies all the `filters`.
+func MatchSize(filters []SizeFilter, size int64) bool {
+	for _, f := range filters {
+		if !f.Match(size) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseSizeFilter(filter string) (SizeFilter, error) {
+	s := strings.TrimPrefix(filter, FilterSizeType)
+	for _, op := range sizeFilterOps {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		size, err := units.FromHumanSize(strings.TrimSpace(s[len(op):]))
+		if err != nil {
+			return SizeFilter{}, fmt.Errorf("invalid filter %q: %w", filter, err)
+		}
+		return SizeFilter{Op: op, Size: size}, nil
+	}
+	return SizeFilter{}, fmt.Errorf("invalid filter %q", filter)
 }
 
 // ParseFilters parse filter strings.
 func ParseFilters(filters []string) (*Filters, error) {
 	f := &Filters{Labels: make(map[string]string)}
 	for _, filter := range filters {
+		if strings.HasPrefix(filter, FilterSizeType) {
+			sizeFilter, err := parseSizeFilter(filter)
+			if err != nil {
+				return nil, err
+			}
+			f.Size = append(f.Size, sizeFilter)
+			continue
+		}
 		tempFilterToken := strings.Split(filter, "=")
 		switch len(tempFilterToken) {
 		case 1:
@@ -86,7 +161,29 @@ func ParseFilters(filters []string) (*Filters, error) {
 				// To support filtering labels by keys.
 				f.Labels[tempFilterToken[1]] = ""
 			} else if tempFilterToken[0] == FilterReferenceType {
-				f.Reference = append(f.Reference, tempFilterToken[1])
+				if exclude, ok := strings.CutPrefix(tempFilterToken[1], "!"); ok {
+					f.ReferenceExclude = append(f.ReferenceExclude, exclude)
+				} else {
+					f.Reference = append(f.Reference, tempFilterToken[1])
+				}
+			} else if tempFilterToken[0] == FilterReferenceType+"!" {
+				f.ReferenceExclude = append(f.ReferenceExclude, tempFilterToken[1])
+			} else if tempFilterToken[0] == FilterSchemaType {
+				if tempFilterToken[1] != "1" && tempFilterToken[1] != "2" {
+					return nil, fmt.Errorf("invalid filter %q", filter)
+				}
+				f.Schema = tempFilterToken[1]
+			} else if tempFilterToken[0] == FilterTagType {
+				if tempFilterToken[1] == "" {
+					return nil, fmt.Errorf("invalid filter %q", filter)
+				}
+				f.Tag = append(f.Tag, tempFilterToken[1])
+			} else if tempFilterToken[0] == FilterUntilType {
+				until, err := parseUntil(tempFilterToken[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+				}
+				f.Until = &until
 			} else {
 				return nil, fmt.Errorf("invalid filter %q", filter)
 			}
@@ -103,6 +200,30 @@ func ParseFilters(filters []string) (*Filters, error) {
 	return f, nil
 }
 
+// parseUntil parses the timestamp of the `until` filter.
+func parseUntil(value string) (time.Time, error) {
+	ts, err := timetypes.GetTimestamp(value, time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// FilterUntil filters images created before `until`.
+func FilterUntil(imageList []images.Image, until time.Time) []images.Image {
+	var filtered []images.Image
+	for _, image := range imageList {
+		if image.CreatedAt.Before(until) {
+			filtered = append(filtered, image)
+		}
+	}
+	return filtered
+}
+
 // FilterImages returns images in `labelImages` that are created
 // before MAX(beforeImages.CreatedAt) and after MIN(sinceImages.CreatedAt).
 func FilterImages(labelImages []images.Image, beforeImages []images.Image, sinceImages []images.Image) []images.Image {
@@ -134,39 +255,208 @@ func FilterImages(labelImages []images.Image, beforeImages []images.Image, since
 }
 
 // FilterByReference filters images using references given in `filters`.
+// An image is kept when it matches any of the filters.
+// A filter like `*:latest` only matches the tag, regardless of the repository.
+// A filter like `nginx:{1.24,1.25}` is expanded to `nginx:1.24` and `nginx:1.25`, see expandBraces.
 func FilterByReference(imageList []images.Image, filters []string) ([]images.Image, error) {
+	if len(filters) == 0 {
+		return imageList, nil
+	}
 	var filteredImageList []images.Image
 	log.L.Debug(filters)
 	for _, image := range imageList {
 		log.L.Debug(image.Name)
-		var matches int
+		var match bool
 		for _, f := range filters {
-			var ref dockerreference.Reference
-			var err error
-			ref, err = dockerreference.ParseAnyReference(image.Name)
-			if err != nil {
-				return nil, fmt.Errorf("unable to parse image name: %s while filtering by reference because of %s", image.Name, err.Error())
+			for _, pattern := range expandBraces(f) {
+				var err error
+				match, err = matchReference(pattern, image)
+				if err != nil {
+					return nil, err
+				}
+				if match {
+					break
+				}
+			}
+			if match {
+				break
 			}
+		}
+		if match {
+			filteredImageList = append(filteredImageList, image)
+		}
+	}
+	return filteredImageList, nil
+}
 
-			familiarMatch, err := dockerreference.FamiliarMatch(f, ref)
-			if err != nil {
-				return nil, err
+// SuggestReference returns the normalized form of the reference filter `pattern`
+// (e.g., "docker.io/nginx*" -> "docker.io/library/nginx*", "example/foo" -> "docker.io/example/foo"),
+// when the normalized form matches any of the images in `imageList`.
+// It is used for printing a hint when a reference filter does not match any image.
+func SuggestReference(imageList []images.Image, pattern string) (string, bool) {
+	normalized := normalizeReferencePattern(pattern)
+	if normalized == pattern {
+		return "", false
+	}
+	for _, image := range imageList {
+		if ok, _ := path.Match(normalized, image.Name); ok {
+			return normalized, true
+		}
+		if named, err := dockerreference.ParseDockerRef(image.Name); err == nil {
+			if ok, _ := path.Match(normalized, named.Name()); ok {
+				return normalized, true
 			}
-			regexpMatch, err := regexp.MatchString(f, image.Name)
-			if err != nil {
-				return nil, err
+		}
+	}
+	return "", false
+}
+
+// normalizeReferencePattern adds the default domain ("docker.io") and the "library/" prefix of
+// the official images to `pattern`, like dockerreference.ParseNormalizedNamed does for a reference.
+func normalizeReferencePattern(pattern string) string {
+	domain, remainder := "docker.io", pattern
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		if first := pattern[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			domain, remainder = first, pattern[i+1:]
+		}
+	}
+	if domain == "docker.io" && !strings.Contains(remainder, "/") {
+		remainder = "library/" + remainder
+	}
+	return domain + "/" + remainder
+}
+
+// ExcludeByReference removes the images matching any of the references in `patterns`,
+// using the same patterns as FilterByReference.
+func ExcludeByReference(imageList []images.Image, patterns []string) ([]images.Image, error) {
+	if len(patterns) == 0 {
+		return imageList, nil
+	}
+	var filteredImageList []images.Image
+	for _, image := range imageList {
+		var exclude bool
+		for _, p := range patterns {
+			for _, pattern := range expandBraces(p) {
+				match, err := matchReference(pattern, image)
+				if err != nil {
+					return nil, err
+				}
+				if match {
+					exclude = true
+					break
+				}
 			}
-			if familiarMatch || regexpMatch {
-				matches++
+			if exclude {
+				break
 			}
 		}
-		if matches == len(filters) {
+		if !exclude {
 			filteredImageList = append(filteredImageList, image)
 		}
 	}
 	return filteredImageList, nil
 }
 
+func matchReference(filter string, image images.Image) (bool, error) {
+	if tagPattern, ok := tagOnlyPattern(filter); ok {
+		_, tag := ParseRepoTag(image.Name)
+		return path.Match(tagPattern, tag)
+	}
+	ref, err := dockerreference.ParseAnyReference(image.Name)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse image name: %s while filtering by reference because of %s", image.Name, err.Error())
+	}
+
+	familiarMatch, err := dockerreference.FamiliarMatch(filter, ref)
+	if err != nil {
+		return false, err
+	}
+	regexpMatch, err := regexp.MatchString(filter, image.Name)
+	if err != nil {
+		return false, err
+	}
+	return familiarMatch || regexpMatch || canonicalMatch(filter, ref), nil
+}
+
+// expandBraces expands the brace patterns in `pattern`, e.g., `nginx:{1.24,1.25}` to `nginx:1.24` and `nginx:1.25`.
+// Only comma-separated lists are supported; ranges (`{1..3}`) and nested braces are not.
+// Multiple brace patterns expand to all the combinations.
+// A pattern without a complete brace pair is returned as is, and so is a regexp repetition like `fo{2,3}`.
+func expandBraces(pattern string) []string {
+	openIdx := strings.Index(pattern, "{")
+	if openIdx < 0 {
+		return []string{pattern}
+	}
+	closeIdx := strings.Index(pattern[openIdx:], "}")
+	if closeIdx < 0 {
+		return []string{pattern}
+	}
+	closeIdx += openIdx
+	prefix, body, suffix := pattern[:openIdx], pattern[openIdx+1:closeIdx], pattern[closeIdx+1:]
+	if !strings.Contains(body, ",") || isRegexpRepetition(prefix, body) {
+		// not a list, e.g. a regexp repetition like `a{2}` or `a{2,3}`
+		var res []string
+		for _, s := range expandBraces(suffix) {
+			res = append(res, pattern[:closeIdx+1]+s)
+		}
+		return res
+	}
+	var res []string
+	for _, alt := range strings.Split(body, ",") {
+		for _, s := range expandBraces(suffix) {
+			res = append(res, prefix+alt+s)
+		}
+	}
+	return res
+}
+
+// regexpRepetitionBody matches the body of a regexp repetition with a comma, e.g., `2,3` of `{2,3}` and `2,` of `{2,}`.
+var regexpRepetitionBody = regexp.MustCompile(`^[0-9]+,[0-9]*$`)
+
+// isRegexpRepetition returns whether the braces of `body` following `prefix` are a regexp repetition rather than a list.
+// Numbers right after the ":" of the tag (e.g., `nginx:{1,2}`) are a list, as a repetition of ":" is meaningless for references.
+func isRegexpRepetition(prefix, body string) bool {
+	if prefix == "" || strings.HasSuffix(prefix, ":") {
+		return false
+	}
+	return regexpRepetitionBody.MatchString(body)
+}
+
+// canonicalMatch compares the normalized names of `filter` and `ref`, so that a short name (`nginx`)
+// and a canonical name (`docker.io/library/nginx`) match the image records named in either form.
+// The tag (or the digest) is only compared when `filter` has it.
+func canonicalMatch(filter string, ref dockerreference.Reference) bool {
+	filterRef, err := dockerreference.ParseNormalizedNamed(filter)
+	if err != nil {
+		// e.g., wildcard patterns
+		return false
+	}
+	named, ok := ref.(dockerreference.Named)
+	if !ok {
+		return false
+	}
+	if filterRef.Name() != named.Name() {
+		return false
+	}
+	if dockerreference.IsNameOnly(filterRef) {
+		return true
+	}
+	return filterRef.String() == named.String()
+}
+
+// tagOnlyPattern returns the tag portion of a reference filter like `*:latest`,
+// which matches the tag of the images regardless of the repository.
+func tagOnlyPattern(filter string) (string, bool) {
+	idx := strings.LastIndex(filter, ":")
+	if idx < 0 || strings.Contains(filter[idx:], "/") {
+		return "", false
+	}
+	if filter[:idx] != "*" {
+		return "", false
+	}
+	return filter[idx+1:], true
+}
+
 // FilterDangling filters dangling images (or keeps if `dangling` == false).
 func FilterDangling(imageList []images.Image, dangling bool) []images.Image {
 	var filtered []images.Image
@@ -183,6 +473,44 @@ func FilterDangling(imageList []images.Image, dangling bool) []images.Image {
 	return filtered
 }
 
+// tagNone is the value of the `tag` filter for the images without a tag, e.g., digest-only references.
+const tagNone = "none"
+
+// FilterByTag filters images by the exact tag, parsed with ParseRepoTag.
+// An image matches if its tag equals any of `tags`, or if it has no tag and `tags` contains "none".
+func FilterByTag(imageList []images.Image, tags []string) []images.Image {
+	var filtered []images.Image
+	for _, image := range imageList {
+		_, tag := ParseRepoTag(image.Name)
+		if tag == "" {
+			tag = tagNone
+		}
+		for _, t := range tags {
+			if t == tag {
+				filtered = append(filtered, image)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// IsSchema1 returns whether `image` uses the deprecated Docker schema1 manifest.
+func IsSchema1(image images.Image) bool {
+	return image.Target.MediaType == images.MediaTypeDockerSchema1Manifest
+}
+
+// FilterBySchema filters images by the manifest schema, "1" or "2".
+func FilterBySchema(imageList []images.Image, schema string) []images.Image {
+	var filtered []images.Image
+	for _, image := range imageList {
+		if IsSchema1(image) == (schema == "1") {
+			filtered = append(filtered, image)
+		}
+	}
+	return filtered
+}
+
 // FilterByLabel filters images based on labels given in `filters`.
 func FilterByLabel(ctx context.Context, client *containerd.Client, imageList []images.Image, filters map[string]string) ([]images.Image, error) {
 	for lk, lv := range filters {
@@ -193,10 +521,13 @@ func FilterByLabel(ctx context.Context, client *containerd.Client, imageList []i
 			if err != nil {
 				return nil, err
 			}
-			if val, ok := cfg.Config.Labels[lk]; ok {
-				if val == lv || lv == "" {
-					imageLabels = append(imageLabels, img)
-				}
+			val, ok := cfg.Config.Labels[lk]
+			if !ok {
+				// the labels of the image record, e.g., set by `nerdctl pull --label`
+				val, ok = img.Labels[lk]
+			}
+			if ok && (val == lv || lv == "") {
+				imageLabels = append(imageLabels, img)
 			}
 		}
 		imageList = imageLabels
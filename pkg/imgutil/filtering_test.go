@@ -0,0 +1,418 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/images"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestFilterByReference(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "docker.io/library/alpine:latest"},
+		{Name: "docker.io/library/alpine:3.13"},
+		{Name: "docker.io/foo/bar:latest"},
+		{Name: "registry.example.com:5000/foo/baz:v1"},
+	}
+	type testCase struct {
+		filters  []string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			// tag-only pattern
+			filters:  []string{"*:latest"},
+			expected: []string{"docker.io/library/alpine:latest", "docker.io/foo/bar:latest"},
+		},
+		{
+			// tag-only pattern with a wildcard in the tag
+			filters:  []string{"*:v*"},
+			expected: []string{"registry.example.com:5000/foo/baz:v1"},
+		},
+		{
+			// repo-only pattern
+			filters:  []string{"alpine"},
+			expected: []string{"docker.io/library/alpine:latest", "docker.io/library/alpine:3.13"},
+		},
+		{
+			// repo-only pattern with a namespace
+			filters:  []string{"foo/bar"},
+			expected: []string{"docker.io/foo/bar:latest"},
+		},
+		{
+			filters:  []string{"alpine:3.13"},
+			expected: []string{"docker.io/library/alpine:3.13"},
+		},
+		{
+			// multiple filters are ORed
+			filters:  []string{"alpine", "*:latest"},
+			expected: []string{"docker.io/library/alpine:latest", "docker.io/library/alpine:3.13", "docker.io/foo/bar:latest"},
+		},
+	}
+	for _, tc := range testCases {
+		filtered, err := FilterByReference(imageList, tc.filters)
+		assert.NilError(t, err)
+		var names []string
+		for _, img := range filtered {
+			names = append(names, img.Name)
+		}
+		assert.DeepEqual(t, tc.expected, names)
+	}
+}
+
+func TestFilterByReferenceShortAndCanonicalNames(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "nginx:latest"},
+		{Name: "docker.io/library/nginx:alpine"},
+		{Name: "docker.io/library/alpine:latest"},
+	}
+	type testCase struct {
+		filters  []string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			filters:  []string{"nginx"},
+			expected: []string{"nginx:latest", "docker.io/library/nginx:alpine"},
+		},
+		{
+			filters:  []string{"docker.io/library/nginx"},
+			expected: []string{"nginx:latest", "docker.io/library/nginx:alpine"},
+		},
+		{
+			filters:  []string{"docker.io/library/nginx:latest"},
+			expected: []string{"nginx:latest"},
+		},
+		{
+			filters:  []string{"nginx:alpine"},
+			expected: []string{"docker.io/library/nginx:alpine"},
+		},
+	}
+	for _, tc := range testCases {
+		filtered, err := FilterByReference(imageList, tc.filters)
+		assert.NilError(t, err)
+		var names []string
+		for _, img := range filtered {
+			names = append(names, img.Name)
+		}
+		assert.DeepEqual(t, tc.expected, names)
+	}
+}
+
+func TestParseSizeFilter(t *testing.T) {
+	type testCase struct {
+		filter   string
+		expected SizeFilter
+		err      bool
+	}
+	testCases := []testCase{
+		{filter: "size>500MB", expected: SizeFilter{Op: ">", Size: 500 * 1000 * 1000}},
+		{filter: "size<10MB", expected: SizeFilter{Op: "<", Size: 10 * 1000 * 1000}},
+		{filter: "size>=1GB", expected: SizeFilter{Op: ">=", Size: 1000 * 1000 * 1000}},
+		{filter: "size<=42", expected: SizeFilter{Op: "<=", Size: 42}},
+		{filter: "size==1KB", expected: SizeFilter{Op: "==", Size: 1000}},
+		{filter: "size=1KB", err: true},
+		{filter: "size>foo", err: true},
+	}
+	for _, tc := range testCases {
+		f, err := ParseFilters([]string{tc.filter})
+		if tc.err {
+			assert.Assert(t, err != nil, tc.filter)
+			continue
+		}
+		assert.NilError(t, err, tc.filter)
+		assert.DeepEqual(t, []SizeFilter{tc.expected}, f.Size)
+	}
+}
+
+func TestMatchSize(t *testing.T) {
+	// a mix of image sizes: 2MB, 8MB, 600MB
+	sizes := []int64{2 * 1000 * 1000, 8 * 1000 * 1000, 600 * 1000 * 1000}
+	type testCase struct {
+		filters  []string
+		expected []int64
+	}
+	testCases := []testCase{
+		{filters: []string{"size>500MB"}, expected: []int64{600 * 1000 * 1000}},
+		{filters: []string{"size<10MB"}, expected: []int64{2 * 1000 * 1000, 8 * 1000 * 1000}},
+		{filters: []string{"size>=8MB", "size<=8MB"}, expected: []int64{8 * 1000 * 1000}},
+		{filters: []string{"size==2MB"}, expected: []int64{2 * 1000 * 1000}},
+		{filters: []string{"size>1MB", "size<2MB"}, expected: nil},
+		{filters: nil, expected: sizes},
+	}
+	for _, tc := range testCases {
+		f, err := ParseFilters(tc.filters)
+		assert.NilError(t, err)
+		var actual []int64
+		for _, size := range sizes {
+			if MatchSize(f.Size, size) {
+				actual = append(actual, size)
+			}
+		}
+		assert.DeepEqual(t, tc.expected, actual)
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	type testCase struct {
+		pattern  string
+		expected []string
+	}
+	testCases := []testCase{
+		{pattern: "nginx", expected: []string{"nginx"}},
+		{pattern: "nginx:{1.24,1.25}", expected: []string{"nginx:1.24", "nginx:1.25"}},
+		{pattern: "{nginx,httpd}:{1,2}", expected: []string{"nginx:1", "nginx:2", "httpd:1", "httpd:2"}},
+		{pattern: "nginx:{1.24}", expected: []string{"nginx:{1.24}"}},
+		{pattern: "nginx:{1.24,", expected: []string{"nginx:{1.24,"}},
+		// regexp repetitions are not lists
+		{pattern: "fo{2,3}bar", expected: []string{"fo{2,3}bar"}},
+		{pattern: "fo{2,}bar:{1,2}", expected: []string{"fo{2,}bar:1", "fo{2,}bar:2"}},
+		{pattern: "nginx:{1,2}", expected: []string{"nginx:1", "nginx:2"}},
+		{pattern: "nginx:1.{24,25}", expected: []string{"nginx:1.{24,25}"}},
+	}
+	for _, tc := range testCases {
+		assert.DeepEqual(t, tc.expected, expandBraces(tc.pattern))
+	}
+}
+
+func TestFilterByReferenceBraceExpansion(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "docker.io/library/nginx:1.23"},
+		{Name: "docker.io/library/nginx:1.24"},
+		{Name: "docker.io/library/nginx:1.25"},
+		{Name: "docker.io/library/alpine:3.13"},
+	}
+	type testCase struct {
+		filters  []string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			filters:  []string{"nginx:{1.24,1.25}"},
+			expected: []string{"docker.io/library/nginx:1.24", "docker.io/library/nginx:1.25"},
+		},
+		{
+			filters:  []string{"*:{1.23,3.13}"},
+			expected: []string{"docker.io/library/nginx:1.23", "docker.io/library/alpine:3.13"},
+		},
+		{
+			// combined with another filter via OR
+			filters:  []string{"nginx:{1.24,1.25}", "*:3.13"},
+			expected: []string{"docker.io/library/nginx:1.24", "docker.io/library/nginx:1.25", "docker.io/library/alpine:3.13"},
+		},
+	}
+	for _, tc := range testCases {
+		filtered, err := FilterByReference(imageList, tc.filters)
+		assert.NilError(t, err)
+		var actual []string
+		for _, img := range filtered {
+			actual = append(actual, img.Name)
+		}
+		assert.DeepEqual(t, tc.expected, actual)
+	}
+}
+
+func TestExcludeByReference(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "docker.io/library/nginx:1.25"},
+		{Name: "k8s.gcr.io/pause:3.9"},
+		{Name: "k8s.gcr.io/coredns/coredns:v1.11.1"},
+		{Name: "docker.io/library/alpine:3.13"},
+	}
+	type testCase struct {
+		patterns []string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			patterns: nil,
+			expected: []string{"docker.io/library/nginx:1.25", "k8s.gcr.io/pause:3.9", "k8s.gcr.io/coredns/coredns:v1.11.1", "docker.io/library/alpine:3.13"},
+		},
+		{
+			patterns: []string{"k8s.gcr.io/*"},
+			expected: []string{"docker.io/library/nginx:1.25", "docker.io/library/alpine:3.13"},
+		},
+		{
+			patterns: []string{"{nginx,alpine}", "*:3.9"},
+			expected: []string{"k8s.gcr.io/coredns/coredns:v1.11.1"},
+		},
+	}
+	for _, tc := range testCases {
+		filtered, err := ExcludeByReference(imageList, tc.patterns)
+		assert.NilError(t, err)
+		var actual []string
+		for _, img := range filtered {
+			actual = append(actual, img.Name)
+		}
+		assert.DeepEqual(t, tc.expected, actual)
+	}
+}
+
+func TestSuggestReference(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "docker.io/library/nginx:1.25"},
+		{Name: "docker.io/example/foo:latest"},
+		{Name: "ghcr.io/example/bar:latest"},
+	}
+	type testCase struct {
+		pattern   string
+		suggested string
+		ok        bool
+	}
+	testCases := []testCase{
+		{pattern: "docker.io/nginx*", suggested: "docker.io/library/nginx*", ok: true},
+		{pattern: "docker.io/nginx", suggested: "docker.io/library/nginx", ok: true},
+		{pattern: "nginx:*", suggested: "docker.io/library/nginx:*", ok: true},
+		{pattern: "example/f?o", suggested: "docker.io/example/f?o", ok: true},
+		// already normalized
+		{pattern: "docker.io/library/nginx*", ok: false},
+		{pattern: "ghcr.io/example/bar", ok: false},
+		// the normalized form does not match either
+		{pattern: "docker.io/alpine*", ok: false},
+	}
+	for _, tc := range testCases {
+		suggested, ok := SuggestReference(imageList, tc.pattern)
+		assert.Equal(t, tc.ok, ok, tc.pattern)
+		assert.Equal(t, tc.suggested, suggested, tc.pattern)
+	}
+}
+
+func TestReferenceIncludeAndExclude(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "docker.io/library/alpine:latest"},
+		{Name: "docker.io/library/alpine:3.13"},
+		{Name: "k8s.gcr.io/pause:3.9"},
+		{Name: "k8s.gcr.io/coredns:1.9"},
+		{Name: "registry.example.com:5000/foo/baz:v1"},
+	}
+	type testCase struct {
+		filters  []string
+		expected []string
+	}
+	testCases := []testCase{
+		{
+			filters:  []string{"reference=!k8s.gcr.io/*"},
+			expected: []string{"docker.io/library/alpine:latest", "docker.io/library/alpine:3.13", "registry.example.com:5000/foo/baz:v1"},
+		},
+		{
+			filters:  []string{"reference!=k8s.gcr.io/*"},
+			expected: []string{"docker.io/library/alpine:latest", "docker.io/library/alpine:3.13", "registry.example.com:5000/foo/baz:v1"},
+		},
+		{
+			// includes are ORed, then excludes are subtracted
+			filters:  []string{"reference=alpine", "reference=k8s.gcr.io/*", "reference=!*:3.13", "reference!=k8s.gcr.io/pause"},
+			expected: []string{"docker.io/library/alpine:latest", "k8s.gcr.io/coredns:1.9"},
+		},
+	}
+	for _, tc := range testCases {
+		f, err := ParseFilters(tc.filters)
+		assert.NilError(t, err)
+		filtered, err := FilterByReference(imageList, f.Reference)
+		assert.NilError(t, err)
+		filtered, err = ExcludeByReference(filtered, f.ReferenceExclude)
+		assert.NilError(t, err)
+		var names []string
+		for _, img := range filtered {
+			names = append(names, img.Name)
+		}
+		assert.DeepEqual(t, tc.expected, names)
+	}
+}
+
+func TestFilterBySchema(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "docker.io/library/legacy:1", Target: ocispec.Descriptor{MediaType: images.MediaTypeDockerSchema1Manifest}},
+		{Name: "docker.io/library/docker:1", Target: ocispec.Descriptor{MediaType: images.MediaTypeDockerSchema2Manifest}},
+		{Name: "docker.io/library/oci:1", Target: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex}},
+	}
+	names := func(imageList []images.Image) []string {
+		var res []string
+		for _, img := range imageList {
+			res = append(res, img.Name)
+		}
+		return res
+	}
+
+	f, err := ParseFilters([]string{"schema=1"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names(FilterBySchema(imageList, f.Schema)), []string{"docker.io/library/legacy:1"})
+
+	f, err = ParseFilters([]string{"schema=2"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names(FilterBySchema(imageList, f.Schema)), []string{"docker.io/library/docker:1", "docker.io/library/oci:1"})
+
+	_, err = ParseFilters([]string{"schema=3"})
+	assert.ErrorContains(t, err, "invalid filter")
+}
+
+func TestFilterUntil(t *testing.T) {
+	now := time.Now()
+	imageList := []images.Image{
+		{Name: "docker.io/library/old:1", CreatedAt: now.Add(-48 * time.Hour)},
+		{Name: "docker.io/library/new:1", CreatedAt: now.Add(-1 * time.Hour)},
+	}
+
+	f, err := ParseFilters([]string{"until=24h"})
+	assert.NilError(t, err)
+	filtered := FilterUntil(imageList, *f.Until)
+	assert.Equal(t, len(filtered), 1)
+	assert.Equal(t, filtered[0].Name, "docker.io/library/old:1")
+
+	f, err = ParseFilters([]string{"until=" + now.Format(time.RFC3339Nano)})
+	assert.NilError(t, err)
+	assert.Equal(t, len(FilterUntil(imageList, *f.Until)), 2)
+
+	_, err = ParseFilters([]string{"until=foo"})
+	assert.ErrorContains(t, err, "invalid filter")
+}
+
+func TestFilterByTag(t *testing.T) {
+	imageList := []images.Image{
+		{Name: "docker.io/library/alpine:latest"},
+		{Name: "docker.io/library/alpine:3.19"},
+		{Name: "docker.io/library/busybox:latest"},
+		{Name: "docker.io/library/alpine@sha256:4f2c4fdb4c1d3f22aa4bd4c98d0ab0f6fbe1791f99e290a7e1e98b3b2bd28d4e"},
+	}
+	names := func(imageList []images.Image) []string {
+		var res []string
+		for _, img := range imageList {
+			res = append(res, img.Name)
+		}
+		return res
+	}
+
+	f, err := ParseFilters([]string{"tag=none"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names(FilterByTag(imageList, f.Tag)),
+		[]string{"docker.io/library/alpine@sha256:4f2c4fdb4c1d3f22aa4bd4c98d0ab0f6fbe1791f99e290a7e1e98b3b2bd28d4e"})
+
+	f, err = ParseFilters([]string{"tag=latest"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names(FilterByTag(imageList, f.Tag)), []string{"docker.io/library/alpine:latest", "docker.io/library/busybox:latest"})
+
+	// an exact match, not a pattern
+	f, err = ParseFilters([]string{"tag=3"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(FilterByTag(imageList, f.Tag)), 0)
+
+	_, err = ParseFilters([]string{"tag="})
+	assert.ErrorContains(t, err, "invalid filter")
+}
@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/containerd/containerd"
 	"github.com/containerd/containerd/content"
@@ -38,6 +40,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/pull"
 	"github.com/containerd/platforms"
+	"github.com/containerd/stargz-snapshotter/estargz"
 	"github.com/docker/docker/errdefs"
 	"github.com/opencontainers/image-spec/identity"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -55,6 +58,25 @@ type EnsuredImage struct {
 // PullMode is either one of "always", "missing", "never"
 type PullMode = string
 
+// ValidatePullMode returns an error if mode is not a valid PullMode.
+func ValidatePullMode(mode PullMode) error {
+	switch mode {
+	case "always", "missing", "never":
+		return nil
+	default:
+		return fmt.Errorf("invalid pull mode %q: must be one of \"always\", \"missing\", or \"never\"", mode)
+	}
+}
+
+// configPlatform returns the platform of the image config.
+// A config without the os or the architecture (e.g., an image built by an old tool) is assumed to be for the default platform.
+func configPlatform(img ocispec.Image) ocispec.Platform {
+	if img.OS == "" || img.Architecture == "" {
+		return platforms.DefaultSpec()
+	}
+	return platforms.Normalize(ocispec.Platform{OS: img.OS, Architecture: img.Architecture, Variant: img.Variant})
+}
+
 // GetExistingImage returns the specified image if exists in containerd. Return errdefs.NotFound() if not exists.
 func GetExistingImage(ctx context.Context, client *containerd.Client, snapshotter, rawRef string, platform ocispec.Platform) (*EnsuredImage, error) {
 	var res *EnsuredImage
@@ -65,16 +87,23 @@ func GetExistingImage(ctx context.Context, client *containerd.Client, snapshotte
 				return nil
 			}
 			image := containerd.NewImageWithPlatform(client, found.Image, platforms.OnlyStrict(platform))
-			imgConfig, err := getImageConfig(ctx, image)
+			ocispecImage, err := getImage(ctx, image)
 			if err != nil {
 				// Image found but blob not found for foreign arch
 				// Ignore err and return nil, so that the walker can visit the next candidate.
 				return nil
 			}
+			// A non-indexed image is not filtered by the platform matcher, so check the platform of the config too.
+			// Ignore a mismatch and return nil, so that the walker can visit the next candidate.
+			imgPlatform := configPlatform(*ocispecImage)
+			if !platforms.OnlyStrict(platform).Match(imgPlatform) {
+				log.G(ctx).Debugf("image %q is for platform %q, not for %q", found.Image.Name, platforms.Format(imgPlatform), platforms.Format(platform))
+				return nil
+			}
 			res = &EnsuredImage{
 				Ref:         found.Image.Name,
 				Image:       image,
-				ImageConfig: *imgConfig,
+				ImageConfig: ocispecImage.Config,
 				Snapshotter: snapshotter,
 				Remote:      getSnapshotterOpts(snapshotter).isRemote(),
 			}
@@ -103,13 +132,12 @@ func GetExistingImage(ctx context.Context, client *containerd.Client, snapshotte
 //
 // # When insecure is set, skips verifying certs, and also falls back to HTTP when the registry does not speak HTTPS
 //
+// `extraOpts` are passed to dockerconfigresolver, e.g., for recording the registry metadata.
+//
 // FIXME: this func has too many args
-func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr io.Writer, snapshotter, rawRef string, mode PullMode, insecure bool, hostsDirs []string, ocispecPlatforms []ocispec.Platform, unpack *bool, quiet bool, rFlags types.RemoteSnapshotterFlags) (*EnsuredImage, error) {
-	switch mode {
-	case "always", "missing", "never":
-		// NOP
-	default:
-		return nil, fmt.Errorf("unexpected pull mode: %q", mode)
+func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr io.Writer, snapshotter, rawRef string, mode PullMode, insecure bool, hostsDirs []string, ocispecPlatforms []ocispec.Platform, unpack *bool, quiet bool, progressFormat string, rFlags types.RemoteSnapshotterFlags, extraOpts ...dockerconfigresolver.Opt) (*EnsuredImage, error) {
+	if err := ValidatePullMode(mode); err != nil {
+		return nil, err
 	}
 
 	// if not `always` pull and given one platform and image found locally, return existing image directly.
@@ -122,7 +150,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr
 	}
 
 	if mode == "never" {
-		return nil, fmt.Errorf("image not available: %q", rawRef)
+		return nil, errdefs.NotFound(fmt.Errorf("image %q is not present locally, and the pull mode is \"never\" (Hint: pull it with `nerdctl pull` first)", rawRef))
 	}
 
 	named, err := refdocker.ParseDockerRef(rawRef)
@@ -132,18 +160,13 @@ func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr
 	ref := named.String()
 	refDomain := refdocker.Domain(named)
 
-	var dOpts []dockerconfigresolver.Opt
-	if insecure {
-		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", refDomain)
-		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
-	}
-	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(hostsDirs))
+	dOpts := resolverOpts(ctx, refDomain, insecure, hostsDirs, extraOpts)
 	resolver, err := dockerconfigresolver.New(ctx, refDomain, dOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	img, err := PullImage(ctx, client, stdout, stderr, snapshotter, resolver, ref, ocispecPlatforms, unpack, quiet, rFlags)
+	img, err := PullImage(ctx, client, stdout, stderr, snapshotter, resolver, ref, ocispecPlatforms, unpack, quiet, progressFormat, rFlags)
 	if err != nil {
 		// In some circumstance (e.g. people just use 80 port to support pure http), the error will contain message like "dial tcp <port>: connection refused".
 		if !errutil.IsErrHTTPResponseToHTTPSClient(err) && !errutil.IsErrConnectionRefused(err) {
@@ -156,7 +179,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr
 			if err != nil {
 				return nil, err
 			}
-			return PullImage(ctx, client, stdout, stderr, snapshotter, resolver, ref, ocispecPlatforms, unpack, quiet, rFlags)
+			return PullImage(ctx, client, stdout, stderr, snapshotter, resolver, ref, ocispecPlatforms, unpack, quiet, progressFormat, rFlags)
 		}
 		log.G(ctx).WithError(err).Errorf("server %q does not seem to support HTTPS", refDomain)
 		log.G(ctx).Info("Hint: you may want to try --insecure-registry to allow plain HTTP (if you are in a trusted network)")
@@ -166,36 +189,55 @@ func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr
 	return img, nil
 }
 
-// ResolveDigest resolves `rawRef` and returns its descriptor digest.
-func ResolveDigest(ctx context.Context, rawRef string, insecure bool, hostsDirs []string) (string, error) {
-	named, err := refdocker.ParseDockerRef(rawRef)
-	if err != nil {
-		return "", err
-	}
-	ref := named.String()
-	refDomain := refdocker.Domain(named)
-
+// resolverOpts returns the options of dockerconfigresolver for the registry `refDomain`, followed by `extraOpts`.
+func resolverOpts(ctx context.Context, refDomain string, insecure bool, hostsDirs []string, extraOpts []dockerconfigresolver.Opt) []dockerconfigresolver.Opt {
 	var dOpts []dockerconfigresolver.Opt
 	if insecure {
 		log.G(ctx).Warnf("skipping verifying HTTPS certs for %q", refDomain)
 		dOpts = append(dOpts, dockerconfigresolver.WithSkipVerifyCerts(true))
 	}
 	dOpts = append(dOpts, dockerconfigresolver.WithHostsDirs(hostsDirs))
-	resolver, err := dockerconfigresolver.New(ctx, refDomain, dOpts...)
+	return append(dOpts, extraOpts...)
+}
+
+// resolve resolves `rawRef` and returns its descriptor.
+// When insecure is set, falls back to plain HTTP like EnsureImage.
+func resolve(ctx context.Context, rawRef string, insecure bool, hostsDirs []string, extraOpts ...dockerconfigresolver.Opt) (ocispec.Descriptor, error) {
+	named, err := refdocker.ParseDockerRef(rawRef)
 	if err != nil {
-		return "", err
+		return ocispec.Descriptor{}, err
 	}
+	ref := named.String()
+	refDomain := refdocker.Domain(named)
 
+	dOpts := resolverOpts(ctx, refDomain, insecure, hostsDirs, extraOpts)
+	resolver, err := dockerconfigresolver.New(ctx, refDomain, dOpts...)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
 	_, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil && insecure && (errutil.IsErrHTTPResponseToHTTPSClient(err) || errutil.IsErrConnectionRefused(err)) {
+		log.G(ctx).WithError(err).Warnf("server %q does not seem to support HTTPS, falling back to plain HTTP", refDomain)
+		resolver, err = dockerconfigresolver.New(ctx, refDomain, append(dOpts, dockerconfigresolver.WithPlainHTTP(true))...)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		_, desc, err = resolver.Resolve(ctx, ref)
+	}
+	return desc, err
+}
+
+// ResolveDigest resolves `rawRef` and returns its descriptor digest.
+func ResolveDigest(ctx context.Context, rawRef string, insecure bool, hostsDirs []string) (string, error) {
+	desc, err := resolve(ctx, rawRef, insecure, hostsDirs)
 	if err != nil {
 		return "", err
 	}
-
 	return desc.Digest.String(), nil
 }
 
 // PullImage pulls an image using the specified resolver.
-func PullImage(ctx context.Context, client *containerd.Client, stdout, stderr io.Writer, snapshotter string, resolver remotes.Resolver, ref string, ocispecPlatforms []ocispec.Platform, unpack *bool, quiet bool, rFlags types.RemoteSnapshotterFlags) (*EnsuredImage, error) {
+func PullImage(ctx context.Context, client *containerd.Client, stdout, stderr io.Writer, snapshotter string, resolver remotes.Resolver, ref string, ocispecPlatforms []ocispec.Platform, unpack *bool, quiet bool, progressFormat string, rFlags types.RemoteSnapshotterFlags) (*EnsuredImage, error) {
 	ctx, done, err := client.WithLease(ctx)
 	if err != nil {
 		return nil, err
@@ -210,6 +252,11 @@ func PullImage(ctx context.Context, client *containerd.Client, stdout, stderr io
 	}
 	if !quiet {
 		config.ProgressOutput = stderr
+		if progressFormat == pull.ProgressFormatJSON {
+			// JSON events are meant to be parsed, so they are printed to stdout
+			config.ProgressOutput = stdout
+		}
+		config.ProgressFormat = progressFormat
 	}
 
 	// unpack(B) if given 1 platform unless specified by `unpack`
@@ -256,6 +303,14 @@ func PullImage(ctx context.Context, client *containerd.Client, stdout, stderr io
 }
 
 func getImageConfig(ctx context.Context, image containerd.Image) (*ocispec.ImageConfig, error) {
+	ocispecImage, err := getImage(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return &ocispecImage.Config, nil
+}
+
+func getImage(ctx context.Context, image containerd.Image) (*ocispec.Image, error) {
 	desc, err := image.Config(ctx)
 	if err != nil {
 		return nil, err
@@ -271,7 +326,7 @@ func getImageConfig(ctx context.Context, image containerd.Image) (*ocispec.Image
 		if err := json.Unmarshal(b, &ocispecImage); err != nil {
 			return nil, err
 		}
-		return &ocispecImage.Config, nil
+		return &ocispecImage, nil
 	default:
 		return nil, fmt.Errorf("unknown media type %q", desc.MediaType)
 	}
@@ -357,6 +412,28 @@ func ReadImageConfig(ctx context.Context, img containerd.Image) (ocispec.Image,
 	return config, configDesc, nil
 }
 
+// ReadImageStopTimeout reads the StopTimeout (in seconds) from the config of img.platform.
+// StopTimeout is a Docker extension to the config, so nil is returned for most of the images.
+func ReadImageStopTimeout(ctx context.Context, img containerd.Image) (*int, error) {
+	configDesc, err := img.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b, err := content.ReadBlob(ctx, img.ContentStore(), configDesc)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Config struct {
+			StopTimeout *int `json:"StopTimeout,omitempty"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, err
+	}
+	return config.Config.StopTimeout, nil
+}
+
 // ParseRepoTag parses raw `imgName` to repository and tag.
 func ParseRepoTag(imgName string) (string, string) {
 	log.L.Debugf("raw image name=%q", imgName)
@@ -377,6 +454,25 @@ func ParseRepoTag(imgName string) (string, string) {
 	return repository, tag
 }
 
+// CreatedAt returns the creation time of `img`.
+// When `labelKey` is set and the image has a label with that key holding an RFC3339 timestamp,
+// the timestamp is used instead of the CreatedAt field of the image record.
+func CreatedAt(img images.Image, labelKey string) time.Time {
+	if labelKey == "" {
+		return img.CreatedAt
+	}
+	v, ok := img.Labels[labelKey]
+	if !ok {
+		return img.CreatedAt
+	}
+	created, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		log.L.WithError(err).Debugf("failed to parse label %q of image %q as RFC3339, falling back to CreatedAt", labelKey, img.Name)
+		return img.CreatedAt
+	}
+	return created
+}
+
 type snapshotKey string
 
 // recursive function to calculate total usage of key's parent
@@ -409,7 +505,7 @@ func UnpackedImageSize(ctx context.Context, s snapshots.Snapshotter, img contain
 	}
 
 	chainID := identity.ChainID(diffIDs).String()
-	usage, err := s.Usage(ctx, chainID)
+	size, err := unpackedChainSize(ctx, s, chainID)
 	if err != nil {
 		if errdefs.IsNotFound(err) {
 			log.G(ctx).WithError(err).Debugf("image %q seems not unpacked", img.Name())
@@ -417,6 +513,42 @@ func UnpackedImageSize(ctx context.Context, s snapshots.Snapshotter, img contain
 		}
 		return 0, err
 	}
+	return size, nil
+}
+
+// LogicalImageSize returns the logical size of the layers of `img`, regardless of whether the layers are
+// stored on the disk or mounted lazily by a remote snapshotter (e.g., stargz, SOCI).
+func LogicalImageSize(ctx context.Context, img containerd.Image) (int64, error) {
+	manifest, err := images.Manifest(ctx, img.ContentStore(), img.Target(), img.Platform())
+	if err != nil {
+		return 0, err
+	}
+	return layersLogicalSize(manifest.Layers), nil
+}
+
+// layersLogicalSize sums the uncompressed sizes of the layers recorded in the eStargz annotation,
+// falling back to the (compressed) blob size for the layers without the annotation.
+func layersLogicalSize(layers []ocispec.Descriptor) int64 {
+	var size int64
+	for _, l := range layers {
+		if s, ok := l.Annotations[estargz.StoreUncompressedSizeAnnotation]; ok {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil && n >= 0 {
+				size += n
+				continue
+			}
+		}
+		size += l.Size
+	}
+	return size
+}
+
+// unpackedChainSize returns the size of the snapshot `chainID` and its parents.
+// Returns a NotFound error if `s` does not have the snapshot.
+func unpackedChainSize(ctx context.Context, s snapshots.Snapshotter, chainID string) (int64, error) {
+	usage, err := s.Usage(ctx, chainID)
+	if err != nil {
+		return 0, err
+	}
 
 	info, err := s.Stat(ctx, chainID)
 	if err != nil {
@@ -18,7 +18,12 @@ package imgutil
 
 import (
 	"testing"
+	"time"
 
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/platforms"
+	"github.com/containerd/stargz-snapshotter/estargz"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"gotest.tools/v3/assert"
 )
 
@@ -57,3 +62,81 @@ func TestParseRepoTag(t *testing.T) {
 		assert.Equal(t, tc.tag, tag)
 	}
 }
+
+func TestValidatePullMode(t *testing.T) {
+	for _, mode := range []string{"always", "missing", "never"} {
+		assert.NilError(t, ValidatePullMode(mode))
+	}
+	for _, mode := range []string{"", "Always", "if-not-present"} {
+		assert.ErrorContains(t, ValidatePullMode(mode), "invalid pull mode")
+	}
+}
+
+func TestCreatedAt(t *testing.T) {
+	const labelKey = "org.example.created"
+	recordCreated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	labelCreated := time.Date(2020, 6, 7, 8, 9, 10, 0, time.UTC)
+	type testCase struct {
+		labels   map[string]string
+		labelKey string
+		expected time.Time
+	}
+	testCases := []testCase{
+		{
+			labels:   nil,
+			labelKey: labelKey,
+			expected: recordCreated,
+		},
+		{
+			labels:   map[string]string{labelKey: labelCreated.Format(time.RFC3339)},
+			labelKey: labelKey,
+			expected: labelCreated,
+		},
+		{
+			labels:   map[string]string{labelKey: labelCreated.Format(time.RFC3339)},
+			labelKey: "",
+			expected: recordCreated,
+		},
+		{
+			labels:   map[string]string{labelKey: "not a timestamp"},
+			labelKey: labelKey,
+			expected: recordCreated,
+		},
+	}
+	for _, tc := range testCases {
+		img := images.Image{
+			Name:      "docker.io/library/alpine:latest",
+			Labels:    tc.labels,
+			CreatedAt: recordCreated,
+		}
+		assert.Assert(t, CreatedAt(img, tc.labelKey).Equal(tc.expected))
+	}
+}
+
+func TestLayersLogicalSize(t *testing.T) {
+	layers := []ocispec.Descriptor{
+		{
+			// lazily pulled eStargz layer
+			Size:        100,
+			Annotations: map[string]string{estargz.StoreUncompressedSizeAnnotation: "1000"},
+		},
+		{
+			// regular layer
+			Size: 200,
+		},
+		{
+			// broken annotation
+			Size:        300,
+			Annotations: map[string]string{estargz.StoreUncompressedSizeAnnotation: "foo"},
+		},
+	}
+	assert.Equal(t, layersLogicalSize(layers), int64(1500))
+	assert.Equal(t, layersLogicalSize(nil), int64(0))
+}
+
+func TestConfigPlatform(t *testing.T) {
+	arm64 := ocispec.Image{Platform: ocispec.Platform{OS: "linux", Architecture: "aarch64"}}
+	assert.DeepEqual(t, ocispec.Platform{OS: "linux", Architecture: "arm64"}, configPlatform(arm64))
+	assert.DeepEqual(t, platforms.DefaultSpec(), configPlatform(ocispec.Image{}))
+	assert.DeepEqual(t, platforms.DefaultSpec(), configPlatform(ocispec.Image{Platform: ocispec.Platform{OS: "linux"}}))
+}
@@ -18,8 +18,10 @@ package jobs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"text/tabwriter"
 	"time"
@@ -38,10 +40,87 @@ import (
 //
 // From https://github.com/containerd/containerd/blob/v1.7.0-rc.2/cmd/ctr/commands/content/fetch.go#L219-L336
 func ShowProgress(ctx context.Context, ongoing *Jobs, cs content.Store, out io.Writer) {
+	var (
+		fw    = progress.NewWriter(out)
+		start = time.Now()
+	)
+	watchProgress(ctx, ongoing, cs, start, func(ordered []StatusInfo, done bool) {
+		fw.Flush()
+
+		tw := tabwriter.NewWriter(fw, 1, 8, 1, ' ', 0)
+		Display(tw, ordered, start)
+		tw.Flush()
+
+		if done {
+			fw.Flush()
+		}
+	})
+}
+
+// ProgressEvent is printed by ShowJSONProgress.
+//
+// The "id", "status", and "progressDetail" fields can be decoded as a JSON message of `docker pull`.
+type ProgressEvent struct {
+	// ID is the digest of the blob, or the name of the image
+	ID             string           `json:"id"`
+	Status         StatusInfoStatus `json:"status"`
+	Current        int64            `json:"current,omitempty"`
+	Total          int64            `json:"total,omitempty"`
+	ProgressDetail *ProgressDetail  `json:"progressDetail,omitempty"`
+}
+
+// ProgressDetail is the same as the "progressDetail" field of a JSON message of `docker pull`.
+type ProgressDetail struct {
+	Current int64 `json:"current"`
+	Total   int64 `json:"total"`
+}
+
+// ShowJSONProgress is like ShowProgress, but prints a ProgressEvent per line
+// instead of progress bars, each time the status of a job changes.
+func ShowJSONProgress(ctx context.Context, ongoing *Jobs, cs content.Store, out io.Writer) {
+	var (
+		enc  = json.NewEncoder(out)
+		last = map[string]StatusInfo{}
+	)
+	watchProgress(ctx, ongoing, cs, time.Now(), func(ordered []StatusInfo, _ bool) {
+		for _, status := range ordered {
+			if l, ok := last[status.Ref]; ok && l.Status == status.Status && l.Offset == status.Offset && l.Total == status.Total {
+				continue
+			}
+			last[status.Ref] = status
+			ev := ProgressEvent{
+				ID:      progressEventID(status.Ref),
+				Status:  status.Status,
+				Current: status.Offset,
+				Total:   status.Total,
+			}
+			if status.Total > 0 {
+				ev.ProgressDetail = &ProgressDetail{Current: status.Offset, Total: status.Total}
+			}
+			if err := enc.Encode(ev); err != nil {
+				log.G(ctx).WithError(err).Error("failed to print the progress")
+				return
+			}
+		}
+	})
+}
+
+// progressEventID returns the digest in a key made by remotes.MakeRefKey (e.g., "layer-sha256:..."),
+// or the key itself (e.g., the name of the image).
+func progressEventID(ref string) string {
+	if _, s, ok := strings.Cut(ref, "-"); ok {
+		if d, err := digest.Parse(s); err == nil {
+			return d.String()
+		}
+	}
+	return ref
+}
+
+// watchProgress calls display with the status of the jobs every 100 milliseconds,
+// and once more with done=true after ctx is done.
+func watchProgress(ctx context.Context, ongoing *Jobs, cs content.Store, start time.Time, display func(ordered []StatusInfo, done bool)) {
 	var (
 		ticker   = time.NewTicker(100 * time.Millisecond)
-		fw       = progress.NewWriter(out)
-		start    = time.Now()
 		statuses = map[string]StatusInfo{}
 		done     bool
 	)
@@ -51,10 +130,6 @@ outer:
 	for {
 		select {
 		case <-ticker.C:
-			fw.Flush()
-
-			tw := tabwriter.NewWriter(fw, 1, 8, 1, ' ', 0)
-
 			resolved := StatusResolved
 			if !ongoing.IsResolved() {
 				resolved = StatusResolving
@@ -140,11 +215,9 @@ outer:
 				ordered = append(ordered, statuses[key])
 			}
 
-			Display(tw, ordered, start)
-			tw.Flush()
+			display(ordered, done)
 
 			if done {
-				fw.Flush()
 				return
 			}
 		case <-ctx.Done():
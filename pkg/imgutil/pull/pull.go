@@ -19,14 +19,20 @@ package pull
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/errdefs"
 	"github.com/containerd/containerd/images"
 	"github.com/containerd/containerd/remotes"
 	"github.com/containerd/log"
 	"github.com/containerd/nerdctl/v2/pkg/imgutil/jobs"
 	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+	"github.com/containerd/platforms"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -36,6 +42,8 @@ type Config struct {
 	Resolver remotes.Resolver
 	// ProgressOutput to display progress
 	ProgressOutput io.Writer
+	// ProgressFormat is the format of the progress (ProgressFormatAuto or ProgressFormatJSON)
+	ProgressFormat string
 	// RemoteOpts, e.g. containerd.WithPullUnpack.
 	//
 	// Regardless to RemoteOpts, the following opts are always set:
@@ -46,6 +54,13 @@ type Config struct {
 	Platforms  []ocispec.Platform // empty for all-platforms
 }
 
+const (
+	// ProgressFormatAuto shows progress bars.
+	ProgressFormatAuto = "auto"
+	// ProgressFormatJSON prints a JSON progress event per line, for scripts.
+	ProgressFormatJSON = "json"
+)
+
 // Pull loads all resources into the content store and returns the image
 func Pull(ctx context.Context, client *containerd.Client, ref string, config *Config) (containerd.Image, error) {
 	ongoing := jobs.New(ref)
@@ -56,7 +71,11 @@ func Pull(ctx context.Context, client *containerd.Client, ref string, config *Co
 	go func() {
 		if config.ProgressOutput != nil {
 			// no progress bar, because it hides some debug logs
-			jobs.ShowProgress(pctx, ongoing, client.ContentStore(), config.ProgressOutput)
+			if config.ProgressFormat == ProgressFormatJSON {
+				jobs.ShowJSONProgress(pctx, ongoing, client.ContentStore(), config.ProgressOutput)
+			} else {
+				jobs.ShowProgress(pctx, ongoing, client.ContentStore(), config.ProgressOutput)
+			}
 		}
 		close(progress)
 	}()
@@ -94,9 +113,69 @@ func Pull(ctx context.Context, client *containerd.Client, ref string, config *Co
 	}
 	stopProgress()
 	if err != nil {
+		if errdefs.IsNotFound(err) && len(config.Platforms) > 0 {
+			// The remote index may lack the requested platform, so list the available ones
+			if perr := checkRemotePlatforms(ctx, config.Resolver, ref, config.Platforms); perr != nil {
+				return nil, perr
+			}
+		}
 		return nil, err
 	}
 
 	<-progress
 	return img, nil
 }
+
+// checkRemotePlatforms fetches the index of ref and returns an error listing the available platforms
+// when none of the requested platforms is in the index.
+// Returns nil when the index cannot be fetched, or when ref is not an index.
+func checkRemotePlatforms(ctx context.Context, resolver remotes.Resolver, ref string, requested []ocispec.Platform) error {
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil || !images.IsIndexType(desc.MediaType) {
+		return nil
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return nil
+	}
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	var idx ocispec.Index
+	if err := json.NewDecoder(rc).Decode(&idx); err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to decode the index of %q", ref)
+		return nil
+	}
+	return checkIndexPlatforms(idx, requested)
+}
+
+// checkIndexPlatforms returns an error like "requested linux/arm/v6 not found; available: linux/amd64, linux/arm64"
+// when none of the manifests of idx matches the requested platforms.
+func checkIndexPlatforms(idx ocispec.Index, requested []ocispec.Platform) error {
+	mc := platformutil.NewMatchComparerFromOCISpecPlatformSlice(requested)
+	seen := make(map[string]struct{})
+	var available []string
+	for _, m := range idx.Manifests {
+		// skip the attestation manifests of BuildKit, which have "unknown/unknown" as the platform
+		if m.Platform == nil || m.Platform.OS == "unknown" {
+			continue
+		}
+		if mc.Match(*m.Platform) {
+			return nil
+		}
+		p := platforms.Format(platforms.Normalize(*m.Platform))
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		available = append(available, p)
+	}
+	sort.Strings(available)
+	var req []string
+	for _, p := range requested {
+		req = append(req, platforms.Format(platforms.Normalize(p)))
+	}
+	return fmt.Errorf("requested %s not found; available: %s", strings.Join(req, ", "), strings.Join(available, ", "))
+}
@@ -0,0 +1,42 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pull
+
+import (
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestCheckIndexPlatforms(t *testing.T) {
+	t.Parallel()
+	idx := ocispec.Index{
+		Manifests: []ocispec.Descriptor{
+			{Platform: &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+			{Platform: &ocispec.Platform{OS: "linux", Architecture: "amd64"}},
+			// attestation manifests are not listed
+			{Platform: &ocispec.Platform{OS: "unknown", Architecture: "unknown"}},
+			{Platform: &ocispec.Platform{OS: "linux", Architecture: "amd64"}},
+		},
+	}
+
+	err := checkIndexPlatforms(idx, []ocispec.Platform{{OS: "linux", Architecture: "arm", Variant: "v6"}})
+	assert.Error(t, err, "requested linux/arm/v6 not found; available: linux/amd64, linux/arm64")
+
+	assert.NilError(t, checkIndexPlatforms(idx, []ocispec.Platform{{OS: "linux", Architecture: "amd64"}}))
+}
@@ -0,0 +1,133 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/containerd/nerdctl/v2/pkg/imgutil/dockerconfigresolver"
+)
+
+// The labels of the image records pulled with `nerdctl pull --store-metadata`,
+// copied from the HTTP response headers of the registry for the manifest.
+const (
+	RegistryETagLabel         = "nerdctl/registry.etag"
+	RegistryLastModifiedLabel = "nerdctl/registry.last-modified"
+	RegistryCacheControlLabel = "nerdctl/registry.cache-control"
+)
+
+// registryMetadataHeaders maps the HTTP response headers to the labels.
+var registryMetadataHeaders = map[string]string{
+	"ETag":          RegistryETagLabel,
+	"Last-Modified": RegistryLastModifiedLabel,
+	"Cache-Control": RegistryCacheControlLabel,
+}
+
+// registryMetadataLabels returns the labels for the registry metadata headers present in `header`.
+func registryMetadataLabels(header http.Header) map[string]string {
+	labels := make(map[string]string)
+	for h, label := range registryMetadataHeaders {
+		if v := header.Get(h); v != "" {
+			labels[label] = v
+		}
+	}
+	return labels
+}
+
+// conditionalHeader returns the conditional request headers (If-None-Match, If-Modified-Since)
+// for the registry metadata `labels` of an image record.
+func conditionalHeader(labels map[string]string) http.Header {
+	header := make(http.Header)
+	if v := labels[RegistryETagLabel]; v != "" {
+		header.Set("If-None-Match", v)
+	}
+	if v := labels[RegistryLastModifiedLabel]; v != "" {
+		header.Set("If-Modified-Since", v)
+	}
+	return header
+}
+
+func isManifestRequest(req *http.Request) bool {
+	return (req.Method == http.MethodHead || req.Method == http.MethodGet) && strings.Contains(req.URL.Path, "/manifests/")
+}
+
+// RegistryMetadataRecorder records the registry metadata of the first manifest response, i.e., the one
+// for resolving the reference, when its Opt is passed to EnsureImage.
+type RegistryMetadataRecorder struct {
+	mu     sync.Mutex // protects labels, as the manifests may be fetched in parallel
+	labels map[string]string
+}
+
+// Opt returns the option of dockerconfigresolver that records the metadata.
+func (r *RegistryMetadataRecorder) Opt() dockerconfigresolver.Opt {
+	return dockerconfigresolver.WithResponseHook(func(resp *http.Response) {
+		if resp.StatusCode != http.StatusOK || !isManifestRequest(resp.Request) {
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.labels == nil {
+			r.labels = registryMetadataLabels(resp.Header)
+		}
+	})
+}
+
+// Labels returns the labels of the recorded metadata, or nil if no manifest was fetched.
+func (r *RegistryMetadataRecorder) Labels() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.labels
+}
+
+// CheckNotModified resolves `rawRef` with the conditional request headers made of the registry metadata `labels`
+// of the local image record, and returns whether the registry reports the manifest as not modified since.
+// It returns false when `labels` have neither the ETag nor the Last-Modified.
+func CheckNotModified(ctx context.Context, rawRef string, labels map[string]string, insecure bool, hostsDirs []string) (bool, error) {
+	header := conditionalHeader(labels)
+	if len(header) == 0 {
+		return false, nil
+	}
+	var (
+		mu          sync.Mutex
+		notModified bool
+	)
+	requestHook := dockerconfigresolver.WithRequestHook(func(req *http.Request) {
+		if isManifestRequest(req) {
+			for k, v := range header {
+				req.Header[k] = v
+			}
+		}
+	})
+	responseHook := dockerconfigresolver.WithResponseHook(func(resp *http.Response) {
+		if resp.StatusCode == http.StatusNotModified && isManifestRequest(resp.Request) {
+			mu.Lock()
+			notModified = true
+			mu.Unlock()
+		}
+	})
+	_, err := resolve(ctx, rawRef, insecure, hostsDirs, requestHook, responseHook)
+	mu.Lock()
+	defer mu.Unlock()
+	if notModified {
+		// the resolver fails on "304 Not Modified", as it has no descriptor to return
+		return true, nil
+	}
+	return false, err
+}
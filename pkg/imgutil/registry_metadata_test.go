@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRegistryMetadataLabels(t *testing.T) {
+	header := http.Header{}
+	header.Set("ETag", `"sha256:abc"`)
+	header.Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+	header.Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+	assert.DeepEqual(t, registryMetadataLabels(header), map[string]string{
+		RegistryETagLabel:         `"sha256:abc"`,
+		RegistryLastModifiedLabel: "Mon, 01 Jan 2024 00:00:00 GMT",
+	})
+	assert.Equal(t, len(registryMetadataLabels(http.Header{})), 0)
+}
+
+func TestCheckNotModified(t *testing.T) {
+	const (
+		dgst = "sha256:4f7a5e1f6f4b3e9d0a3b2e6c9a0d8f1e2c3b4a5968778695a4b3c2d1e0f9a8b7"
+		etag = `"` + dgst + `"`
+	)
+	var manifestRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/manifests/latest") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		manifestRequests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Docker-Content-Digest", dgst)
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Content-Length", "2")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	// the registry on localhost is accessed over plain HTTP
+	ref := strings.TrimPrefix(ts.URL, "http://") + "/foo:latest"
+	ctx := context.Background()
+
+	// nothing to compare without the metadata
+	notModified, err := CheckNotModified(ctx, ref, nil, false, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !notModified)
+	assert.Equal(t, manifestRequests, 0)
+
+	notModified, err = CheckNotModified(ctx, ref, map[string]string{RegistryETagLabel: etag}, false, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, notModified)
+
+	notModified, err = CheckNotModified(ctx, ref, map[string]string{RegistryETagLabel: `"sha256:stale"`}, false, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !notModified)
+
+	resolved, err := ResolveDigest(ctx, ref, false, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, resolved, dgst)
+
+	// the recorder keeps the metadata of the response for resolving the reference
+	var recorder RegistryMetadataRecorder
+	assert.Assert(t, recorder.Labels() == nil)
+	_, err = resolve(ctx, ref, false, nil, recorder.Opt())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, recorder.Labels(), map[string]string{RegistryETagLabel: etag})
+}
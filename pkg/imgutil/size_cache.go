@@ -0,0 +1,185 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/snapshots"
+	"github.com/containerd/log"
+	"github.com/opencontainers/image-spec/identity"
+)
+
+// SizeCache persists the unpacked sizes of the snapshot chains, so that `nerdctl images` does not need to
+// walk the parents of every snapshot with the Usage and Stat calls on every run.
+//
+// A committed snapshot is immutable, so an entry stays valid as long as the snapshot has the same creation time.
+// A snapshot removed and unpacked again (e.g., `nerdctl rmi` followed by `nerdctl pull`) has a new creation time,
+// and a removed snapshot drops its entry. The entries of the images removed since are dropped by PruneUnvisited.
+type SizeCache struct {
+	path    string
+	mu      sync.Mutex                // protects entries, visited, and dirty, as the sizes may be computed in parallel
+	entries map[string]sizeCacheEntry // "<snapshotter>/<chain ID>" -> entry
+	visited map[string]struct{}       // the keys read or written since loaded
+	dirty   bool
+}
+
+type sizeCacheEntry struct {
+	Size    int64     `json:"size"`
+	Created time.Time `json:"created"`
+}
+
+// LoadSizeCache loads the cache from the JSON file `path`.
+// A missing or corrupted file results in an empty cache, as the sizes can always be computed again.
+func LoadSizeCache(ctx context.Context, path string) *SizeCache {
+	c := &SizeCache{
+		path:    path,
+		entries: make(map[string]sizeCacheEntry),
+		visited: make(map[string]struct{}),
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.G(ctx).WithError(err).Debugf("failed to read the size cache %q", path)
+		}
+		return c
+	}
+	if err := json.Unmarshal(b, &c.entries); err != nil {
+		log.G(ctx).WithError(err).Debugf("ignoring the corrupted size cache %q", path)
+		c.entries = make(map[string]sizeCacheEntry)
+		c.dirty = true
+	}
+	return c
+}
+
+// PruneUnvisited drops the entries that were not visited since the cache was loaded, e.g., of the removed images.
+// It should only be called after visiting all the images, i.e., not for a filtered list.
+func (c *SizeCache) PruneUnvisited() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if _, ok := c.visited[key]; !ok {
+			delete(c.entries, key)
+			c.dirty = true
+		}
+	}
+}
+
+// Save writes the cache back to the file, if it was modified.
+func (c *SizeCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	b, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	// write to a temporary file and rename it, so that a concurrent `nerdctl images` never reads a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// UnpackedImageSize is the same as the UnpackedImageSize function, but reads the size from the cache when possible.
+// `snName` is the name of the snapshotter `s`.
+func (c *SizeCache) UnpackedImageSize(ctx context.Context, s snapshots.Snapshotter, snName string, img containerd.Image) (int64, error) {
+	diffIDs, err := img.RootFS(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := c.ChainSize(ctx, s, snName, identity.ChainID(diffIDs).String())
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Debugf("image %q seems not unpacked", img.Name())
+			return 0, nil
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+// ChainSize returns the size of the snapshot `chainID` and its parents in the snapshotter `s` named `snName`.
+// A nil cache always computes the size.
+func (c *SizeCache) ChainSize(ctx context.Context, s snapshots.Snapshotter, snName, chainID string) (int64, error) {
+	if c == nil {
+		return unpackedChainSize(ctx, s, chainID)
+	}
+	key := snName + "/" + chainID
+	info, err := s.Stat(ctx, chainID)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			c.mu.Lock()
+			if _, ok := c.entries[key]; ok {
+				delete(c.entries, key)
+				c.dirty = true
+			}
+			c.mu.Unlock()
+		}
+		return 0, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.visited[key] = struct{}{}
+	c.mu.Unlock()
+	if ok && entry.Created.Equal(info.Created) {
+		return entry.Size, nil
+	}
+
+	size, err := unpackedChainSize(ctx, s, chainID)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.entries[key] = sizeCacheEntry{Size: size, Created: info.Created}
+	c.dirty = true
+	c.mu.Unlock()
+	return size, nil
+}
@@ -0,0 +1,129 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/errdefs"
+	"gotest.tools/v3/assert"
+)
+
+func TestSizeCache(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sn := &fakeSnapshotter{
+		sizes:   map[string]int64{"chain-1": 10, "chain-2": 5},
+		parents: map[string]string{"chain-2": "chain-1"},
+		created: map[string]time.Time{"chain-1": created, "chain-2": created},
+	}
+	path := filepath.Join(t.TempDir(), "image-sizes", "default.json")
+
+	c := LoadSizeCache(ctx, path)
+	size, err := c.ChainSize(ctx, sn, "overlayfs", "chain-2")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(15), size)
+	assert.NilError(t, c.Save())
+
+	// a cache loaded from the file only stats the snapshot
+	c = LoadSizeCache(ctx, path)
+	sn.calls = 0
+	size, err = c.ChainSize(ctx, sn, "overlayfs", "chain-2")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(15), size)
+	assert.Equal(t, 1, sn.calls)
+
+	// the same chain ID in another snapshotter is not a hit
+	sn.calls = 0
+	_, err = c.ChainSize(ctx, sn, "stargz", "chain-2")
+	assert.NilError(t, err)
+	assert.Assert(t, sn.calls > 1)
+
+	// a re-created snapshot is recomputed
+	sn.sizes["chain-2"] = 7
+	sn.created["chain-2"] = created.Add(time.Hour)
+	size, err = c.ChainSize(ctx, sn, "overlayfs", "chain-2")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(17), size)
+
+	// a removed snapshot drops the entry
+	delete(sn.sizes, "chain-2")
+	_, err = c.ChainSize(ctx, sn, "overlayfs", "chain-2")
+	assert.Assert(t, errdefs.IsNotFound(err))
+	assert.NilError(t, c.Save())
+	c = LoadSizeCache(ctx, path)
+	_, ok := c.entries["overlayfs/chain-2"]
+	assert.Assert(t, !ok)
+	_, ok = c.entries["stargz/chain-2"]
+	assert.Assert(t, ok)
+}
+
+func TestSizeCacheCorrupted(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "default.json")
+	assert.NilError(t, os.WriteFile(path, []byte("{"), 0600))
+	sn := &fakeSnapshotter{sizes: map[string]int64{"chain-1": 10}}
+
+	c := LoadSizeCache(ctx, path)
+	size, err := c.ChainSize(ctx, sn, "overlayfs", "chain-1")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(10), size)
+	assert.NilError(t, c.Save())
+	assert.Equal(t, 1, len(LoadSizeCache(ctx, path).entries))
+
+	// a nil cache computes the size
+	var nilCache *SizeCache
+	size, err = nilCache.ChainSize(ctx, sn, "overlayfs", "chain-1")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(10), size)
+	assert.NilError(t, nilCache.Save())
+}
+
+func TestSizeCachePruneUnvisited(t *testing.T) {
+	ctx := context.Background()
+	sn := &fakeSnapshotter{sizes: map[string]int64{"chain-1": 10, "chain-2": 5}}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "default.json")
+
+	c := LoadSizeCache(ctx, path)
+	for _, chainID := range []string{"chain-1", "chain-2"} {
+		_, err := c.ChainSize(ctx, sn, "overlayfs", chainID)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, c.Save())
+
+	// the image of chain-2 has been removed, so chain-2 is not visited
+	c = LoadSizeCache(ctx, path)
+	_, err := c.ChainSize(ctx, sn, "overlayfs", "chain-1")
+	assert.NilError(t, err)
+	c.PruneUnvisited()
+	assert.NilError(t, c.Save())
+	c = LoadSizeCache(ctx, path)
+	_, ok := c.entries["overlayfs/chain-1"]
+	assert.Assert(t, ok)
+	_, ok = c.entries["overlayfs/chain-2"]
+	assert.Assert(t, !ok)
+
+	// no temporary file is left behind
+	files, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(files))
+}
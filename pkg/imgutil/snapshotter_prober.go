@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/snapshots"
+	"github.com/containerd/log"
+	"github.com/opencontainers/image-spec/identity"
+)
+
+// SnapshotterProber computes the unpacked size of images by probing multiple snapshotters,
+// e.g., on a host migrating from overlayfs to stargz, where images are unpacked under different snapshotters.
+type SnapshotterProber struct {
+	names        []string // in the probing order
+	snapshotters map[string]snapshots.Snapshotter
+	mu           sync.Mutex        // protects cache, as the sizes may be computed in parallel
+	cache        map[string]string // chain ID -> snapshotter name
+	sizes        *SizeCache        // nil for computing the sizes on every call
+}
+
+// NewSnapshotterProber creates a SnapshotterProber that probes the snapshotters in the order of `names`.
+// `sizes` may be nil.
+func NewSnapshotterProber(snapshotters map[string]snapshots.Snapshotter, names []string, sizes *SizeCache) *SnapshotterProber {
+	return &SnapshotterProber{
+		names:        names,
+		snapshotters: snapshotters,
+		cache:        make(map[string]string),
+		sizes:        sizes,
+	}
+}
+
+// UnpackedImageSize is the same as the UnpackedImageSize function, but also returns the name of the snapshotter
+// that holds the snapshots of `img`. The name is empty if none of the snapshotters holds them.
+func (p *SnapshotterProber) UnpackedImageSize(ctx context.Context, img containerd.Image) (int64, string, error) {
+	diffIDs, err := img.RootFS(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	size, name, err := p.ChainSize(ctx, identity.ChainID(diffIDs).String())
+	if name == "" && err == nil {
+		log.G(ctx).Debugf("image %q seems not unpacked in any of the snapshotters %v", img.Name(), p.names)
+	}
+	return size, name, err
+}
+
+// ChainSize returns the size of the snapshot `chainID` in the first snapshotter that holds it, and the name of the snapshotter.
+func (p *SnapshotterProber) ChainSize(ctx context.Context, chainID string) (int64, string, error) {
+	p.mu.Lock()
+	name, ok := p.cache[chainID]
+	p.mu.Unlock()
+	if ok {
+		size, err := p.sizes.ChainSize(ctx, p.snapshotters[name], name, chainID)
+		return size, name, err
+	}
+	for _, name := range p.names {
+		s, ok := p.snapshotters[name]
+		if !ok {
+			continue
+		}
+		size, err := p.sizes.ChainSize(ctx, s, name, chainID)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return 0, "", err
+		}
+		p.mu.Lock()
+		p.cache[chainID] = name
+		p.mu.Unlock()
+		return size, name, nil
+	}
+	return 0, "", nil
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package imgutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/snapshots"
+	"gotest.tools/v3/assert"
+)
+
+// fakeSnapshotter implements only Stat and Usage of snapshots.Snapshotter.
+type fakeSnapshotter struct {
+	snapshots.Snapshotter
+	sizes   map[string]int64
+	parents map[string]string
+	created map[string]time.Time
+	calls   int
+}
+
+func (s *fakeSnapshotter) Stat(ctx context.Context, key string) (snapshots.Info, error) {
+	s.calls++
+	if _, ok := s.sizes[key]; !ok {
+		return snapshots.Info{}, fmt.Errorf("snapshot %q: %w", key, errdefs.ErrNotFound)
+	}
+	return snapshots.Info{Name: key, Parent: s.parents[key], Created: s.created[key]}, nil
+}
+
+func (s *fakeSnapshotter) Usage(ctx context.Context, key string) (snapshots.Usage, error) {
+	s.calls++
+	size, ok := s.sizes[key]
+	if !ok {
+		return snapshots.Usage{}, fmt.Errorf("snapshot %q: %w", key, errdefs.ErrNotFound)
+	}
+	return snapshots.Usage{Size: size}, nil
+}
+
+func TestSnapshotterProber(t *testing.T) {
+	ctx := context.Background()
+	overlayfs := &fakeSnapshotter{
+		sizes:   map[string]int64{"chain-a1": 10, "chain-a2": 5},
+		parents: map[string]string{"chain-a2": "chain-a1"},
+	}
+	stargz := &fakeSnapshotter{
+		sizes: map[string]int64{"chain-b1": 100},
+	}
+	prober := NewSnapshotterProber(map[string]snapshots.Snapshotter{
+		"overlayfs": overlayfs,
+		"stargz":    stargz,
+	}, []string{"overlayfs", "stargz"}, nil)
+
+	type testCase struct {
+		chainID     string
+		size        int64
+		snapshotter string
+	}
+	testCases := []testCase{
+		{chainID: "chain-a2", size: 15, snapshotter: "overlayfs"},
+		{chainID: "chain-b1", size: 100, snapshotter: "stargz"},
+		{chainID: "chain-c1", size: 0, snapshotter: ""},
+	}
+	for _, tc := range testCases {
+		size, name, err := prober.ChainSize(ctx, tc.chainID)
+		assert.NilError(t, err)
+		assert.Equal(t, tc.size, size, tc.chainID)
+		assert.Equal(t, tc.snapshotter, name, tc.chainID)
+	}
+
+	// the snapshotter of chain-b1 is cached, so overlayfs is not probed again
+	overlayfsCalls := overlayfs.calls
+	size, name, err := prober.ChainSize(ctx, "chain-b1")
+	assert.NilError(t, err)
+	assert.Equal(t, int64(100), size)
+	assert.Equal(t, "stargz", name)
+	assert.Equal(t, overlayfsCalls, overlayfs.calls)
+}
@@ -20,8 +20,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/containerd/cgroups/v3"
 	"github.com/containerd/nerdctl/v2/pkg/apparmorutil"
+	"github.com/containerd/nerdctl/v2/pkg/cgrouputil"
 	"github.com/containerd/nerdctl/v2/pkg/defaults"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
@@ -32,11 +32,7 @@ import (
 const UnameO = "GNU/Linux"
 
 func CgroupsVersion() string {
-	if cgroups.Mode() == cgroups.Unified {
-		return "2"
-	}
-
-	return "1"
+	return cgrouputil.Version()
 }
 
 func fulfillSecurityOptions(info *dockercompat.Info) {
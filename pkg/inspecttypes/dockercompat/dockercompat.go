@@ -42,6 +42,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/imgutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/platforms"
 	"github.com/docker/go-connections/nat"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/tidwall/gjson"
@@ -62,13 +63,27 @@ type Image struct {
 	Author       string
 	Config       *Config
 	Architecture string
-	// TODO: Variant       string `json:",omitempty"`
-	Os string
-	// TODO: OsVersion     string `json:",omitempty"`
-	Size int64 // Size is the unpacked size of the image
+	Variant      string `json:",omitempty"`
+	Os           string
+	OsVersion    string   `json:",omitempty"`
+	OsFeatures   []string `json:",omitempty"` // nerdctl extension
+	Size         int64    // Size is the unpacked size of the image
 	// TODO: GraphDriver     GraphDriverData
 	RootFS   RootFS
 	Metadata ImageMetadata
+	Leases   *[]native.Lease `json:",omitempty"` // nerdctl extension
+	// Manifests lists the children of the index, without resolving their configs (nerdctl extension).
+	// Empty for a non-indexed image.
+	Manifests []ImageManifestSummary `json:",omitempty"`
+	// DownloadSizes is the compressed sizes of the layers (nerdctl extension), unlike Size.
+	DownloadSizes *native.DownloadSizes `json:",omitempty"`
+}
+
+// ImageManifestSummary is the summary of a child manifest of an index, read from the descriptor.
+type ImageManifestSummary struct {
+	Digest   string
+	Platform string `json:",omitempty"` // e.g., "linux/arm64/v8"; empty if the descriptor has no platform
+	Size     int64  // the size of the manifest blob
 }
 
 type RootFS struct {
@@ -282,7 +297,7 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 }
 
 func ImageFromNative(n *native.Image) (*Image, error) {
-	i := &Image{}
+	i := &Image{Leases: n.Leases, DownloadSizes: n.DownloadSizes}
 
 	imgoci := n.ImageConfig
 
@@ -297,7 +312,22 @@ func ImageFromNative(n *native.Image) (*Image, error) {
 		i.Author = imgoci.History[len(imgoci.History)-1].Author
 	}
 	i.Architecture = imgoci.Architecture
+	i.Variant = imgoci.Variant
 	i.Os = imgoci.OS
+	i.OsVersion = imgoci.OSVersion
+	i.OsFeatures = imgoci.OSFeatures
+	// The platform of the index entry takes precedence, as os.version is often set only there (e.g., Windows)
+	if p := n.Platform; p != nil {
+		if p.Variant != "" {
+			i.Variant = p.Variant
+		}
+		if p.OSVersion != "" {
+			i.OsVersion = p.OSVersion
+		}
+		if len(p.OSFeatures) > 0 {
+			i.OsFeatures = p.OSFeatures
+		}
+	}
 
 	portSet := make(nat.PortSet)
 	for k := range imgoci.Config.ExposedPorts {
@@ -322,6 +352,15 @@ func ImageFromNative(n *native.Image) (*Image, error) {
 	i.RepoTags = []string{fmt.Sprintf("%s:%s", repository, tag)}
 	i.RepoDigests = []string{fmt.Sprintf("%s@%s", repository, n.Image.Target.Digest.String())}
 	i.Size = n.Size
+	if n.Index != nil {
+		for _, m := range n.Index.Manifests {
+			s := ImageManifestSummary{Digest: m.Digest.String(), Size: m.Size}
+			if m.Platform != nil {
+				s.Platform = platforms.Format(*m.Platform)
+			}
+			i.Manifests = append(i.Manifests, s)
+		}
+	}
 	return i, nil
 }
 func statusFromNative(x containerd.Status, labels map[string]string) string {
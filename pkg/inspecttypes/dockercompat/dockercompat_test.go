@@ -0,0 +1,127 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockercompat
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestImageFromNativePlatform(t *testing.T) {
+	type testCase struct {
+		name       string
+		native     *native.Image
+		arch       string
+		variant    string
+		os         string
+		osVersion  string
+		osFeatures []string
+	}
+	testCases := []testCase{
+		{
+			// os.version is set only in the index entry, as in the Windows images on Docker Hub
+			name: "windows",
+			native: &native.Image{
+				Image: images.Image{Name: "mcr.microsoft.com/windows/nanoserver:ltsc2019"},
+				Platform: &ocispec.Platform{
+					OS:           "windows",
+					Architecture: "amd64",
+					OSVersion:    "10.0.17763.5458",
+					OSFeatures:   []string{"win32k"},
+				},
+				ImageConfig: ocispec.Image{
+					Platform: ocispec.Platform{OS: "windows", Architecture: "amd64"},
+				},
+			},
+			arch:       "amd64",
+			os:         "windows",
+			osVersion:  "10.0.17763.5458",
+			osFeatures: []string{"win32k"},
+		},
+		{
+			// not an index, so the platform comes from the config
+			name: "arm/v7",
+			native: &native.Image{
+				Image: images.Image{Name: "docker.io/library/alpine:3.13"},
+				ImageConfig: ocispec.Image{
+					Platform: ocispec.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+				},
+			},
+			arch:    "arm",
+			variant: "v7",
+			os:      "linux",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := ImageFromNative(tc.native)
+			assert.NilError(t, err)
+			assert.Equal(t, img.Architecture, tc.arch)
+			assert.Equal(t, img.Variant, tc.variant)
+			assert.Equal(t, img.Os, tc.os)
+			assert.Equal(t, img.OsVersion, tc.osVersion)
+			assert.DeepEqual(t, img.OsFeatures, tc.osFeatures)
+
+			b, err := json.Marshal(img)
+			assert.NilError(t, err)
+			assert.Equal(t, strings.Contains(string(b), `"OsVersion"`), tc.osVersion != "")
+			assert.Equal(t, strings.Contains(string(b), `"Variant"`), tc.variant != "")
+		})
+	}
+}
+
+func TestImageFromNativeManifests(t *testing.T) {
+	n := &native.Image{
+		Image: images.Image{Name: "docker.io/library/alpine:3.13"},
+		Index: &ocispec.Index{
+			Manifests: []ocispec.Descriptor{
+				{
+					MediaType: ocispec.MediaTypeImageManifest,
+					Digest:    "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+					Size:      528,
+					Platform:  &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+				},
+				{
+					MediaType: ocispec.MediaTypeImageManifest,
+					Digest:    "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+					Size:      529,
+					Platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+				},
+			},
+		},
+	}
+	img, err := ImageFromNative(n)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, img.Manifests, []ImageManifestSummary{
+		{Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111", Platform: "linux/amd64", Size: 528},
+		{Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222", Platform: "linux/arm64/v8", Size: 529},
+	})
+
+	// not an index
+	n.Index = nil
+	img, err = ImageFromNative(n)
+	assert.NilError(t, err)
+	b, err := json.Marshal(img)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(b), `"Manifests"`))
+}
@@ -17,6 +17,8 @@
 package native
 
 import (
+	"time"
+
 	"github.com/containerd/containerd/images"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -29,8 +31,37 @@ type Image struct {
 	Index        *ocispec.Index      `json:"Index,omitempty"`
 	ManifestDesc *ocispec.Descriptor `json:"ManifestDesc,omitempty"`
 	Manifest     *ocispec.Manifest   `json:"Manifest,omitempty"`
+	// Platform is the platform of the manifest resolved from the index
+	Platform *ocispec.Platform `json:"Platform,omitempty"`
 	// e.g., "application/vnd.docker.container.image.v1+json"
 	ImageConfigDesc ocispec.Descriptor `json:"ImageConfigDesc"`
 	ImageConfig     ocispec.Image      `json:"ImageConfig"`
 	Size            int64              `json:"size"`
+	// Leases is set only with `nerdctl image inspect --show-lease`, and is empty when no lease protects the image
+	Leases *[]Lease `json:"Leases,omitempty"`
+	// DownloadSizes is set only with `nerdctl image inspect --download-sizes`
+	DownloadSizes *DownloadSizes `json:"DownloadSizes,omitempty"`
+}
+
+// DownloadSizes is the compressed sizes of the layers, read from the manifest.
+type DownloadSizes struct {
+	// Total is the sum of the sizes of the layers, i.e., the size to be transferred by a pull or a push
+	Total  int64
+	Layers []LayerDownloadSize
+}
+
+// LayerDownloadSize is the compressed size of a layer, read from the layer descriptor.
+type LayerDownloadSize struct {
+	Digest    string
+	MediaType string
+	Size      int64
+}
+
+// Lease is a lease that protects the content of an image from the garbage collection.
+type Lease struct {
+	ID        string
+	CreatedAt time.Time
+	// Expiration is empty for a lease that does not expire
+	Expiration string            `json:",omitempty"`
+	Labels     map[string]string `json:",omitempty"`
 }
@@ -41,12 +41,9 @@ import (
 const ipfsPathEnv = "IPFS_PATH"
 
 // EnsureImage pull the specified image from IPFS.
-func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr io.Writer, snapshotter string, scheme string, ref string, mode imgutil.PullMode, ocispecPlatforms []ocispec.Platform, unpack *bool, quiet bool, ipfsPath string, rFlags types.RemoteSnapshotterFlags) (*imgutil.EnsuredImage, error) {
-	switch mode {
-	case "always", "missing", "never":
-		// NOP
-	default:
-		return nil, fmt.Errorf("unexpected pull mode: %q", mode)
+func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr io.Writer, snapshotter string, scheme string, ref string, mode imgutil.PullMode, ocispecPlatforms []ocispec.Platform, unpack *bool, quiet bool, progressFormat string, ipfsPath string, rFlags types.RemoteSnapshotterFlags) (*imgutil.EnsuredImage, error) {
+	if err := imgutil.ValidatePullMode(mode); err != nil {
+		return nil, err
 	}
 	switch scheme {
 	case "ipfs", "ipns":
@@ -65,7 +62,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr
 	}
 
 	if mode == "never" {
-		return nil, fmt.Errorf("image %q is not available", ref)
+		return nil, errdefs.NotFound(fmt.Errorf("image %q is not present locally, and the pull mode is \"never\"", ref))
 	}
 	r, err := ipfs.NewResolver(ipfs.ResolverOptions{
 		Scheme:   scheme,
@@ -74,7 +71,7 @@ func EnsureImage(ctx context.Context, client *containerd.Client, stdout, stderr
 	if err != nil {
 		return nil, err
 	}
-	return imgutil.PullImage(ctx, client, stdout, stderr, snapshotter, r, ref, ocispecPlatforms, unpack, quiet, rFlags)
+	return imgutil.PullImage(ctx, client, stdout, stderr, snapshotter, r, ref, ocispecPlatforms, unpack, quiet, progressFormat, rFlags)
 }
 
 // Push pushes the specified image to IPFS.
@@ -76,6 +76,13 @@ const (
 	// Platform is the normalized platform string like "linux/ppc64le".
 	Platform = Prefix + "platform"
 
+	// Pod is the name of the pod the container belongs to, for grouping the containers with `nerdctl ps --pod`.
+	Pod = Prefix + "pod"
+
+	// ImageDigest is the digest of the image (index or manifest) the container was created from,
+	// like "sha256:...".
+	ImageDigest = Prefix + "image-digest"
+
 	// Mounts is the mount points for the container.
 	Mounts = Prefix + "mounts"
 
@@ -49,10 +49,13 @@ func NewMatchComparer(all bool, ss []string) (platforms.MatchComparer, error) {
 	return platforms.Ordered(op...), err
 }
 
+// Auto is the platform string for the platform detected from the host, i.e., DefaultSpec.
+const Auto = "auto"
+
 // NewOCISpecPlatformSlice returns a slice of ocispec.Platform
 // If all is true, NewOCISpecPlatformSlice always returns an empty slice, regardless to the value of ss.
 // If all is false and ss is empty, NewOCISpecPlatformSlice returns DefaultSpec.
-// Otherwise NewOCISpecPlatformSlice returns the slice that correspond to ss.
+// Otherwise NewOCISpecPlatformSlice returns the slice that correspond to ss, with "auto" resolved to DefaultSpec.
 func NewOCISpecPlatformSlice(all bool, ss []string) ([]ocispec.Platform, error) {
 	if all {
 		return nil, nil
@@ -60,6 +63,11 @@ func NewOCISpecPlatformSlice(all bool, ss []string) ([]ocispec.Platform, error)
 	if dss := strutil.DedupeStrSlice(ss); len(dss) > 0 {
 		var op []ocispec.Platform
 		for _, s := range dss {
+			if s == Auto {
+				// DefaultSpec detects the variant of ARM (v6, v7, v8) from /proc/cpuinfo
+				op = append(op, platforms.DefaultSpec())
+				continue
+			}
 			p, err := platforms.Parse(s)
 			if err != nil {
 				return nil, fmt.Errorf("invalid platform: %q", s)
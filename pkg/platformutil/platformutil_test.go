@@ -0,0 +1,35 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platformutil
+
+import (
+	"testing"
+
+	"github.com/containerd/platforms"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestNewOCISpecPlatformSliceAuto(t *testing.T) {
+	t.Parallel()
+	op, err := NewOCISpecPlatformSlice(false, []string{Auto, "linux/arm/v6"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, op, []ocispec.Platform{platforms.DefaultSpec(), {OS: "linux", Architecture: "arm", Variant: "v6"}})
+
+	_, err = NewOCISpecPlatformSlice(false, []string{"auto/foo/bar/baz"})
+	assert.ErrorContains(t, err, "invalid platform")
+}
@@ -0,0 +1,118 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package signutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	refdocker "github.com/containerd/containerd/reference/docker"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+)
+
+// Policy is a trust policy for `nerdctl image verify --policy`, e.g.,
+//
+//	{
+//	  "rules": [
+//	    {"scope": "ghcr.io/example", "provider": "cosign", "cosignKey": "/etc/nerdctl/cosign.pub"},
+//	    {"scope": "docker.io", "provider": "notation"}
+//	  ]
+//	}
+//
+// The images not in the scope of any rule fail the verification.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule specifies how to verify the images in the scope.
+type PolicyRule struct {
+	// Scope is a registry (e.g., "ghcr.io"), a namespace of a registry (e.g., "ghcr.io/example"), or a repository.
+	// The most specific scope of the rules applies.
+	Scope string `json:"scope"`
+	// Provider is the verifier (none|cosign|notation). "none" accepts the images without verification.
+	Provider                          string `json:"provider"`
+	CosignKey                         string `json:"cosignKey,omitempty"`
+	CosignCertificateIdentity         string `json:"cosignCertificateIdentity,omitempty"`
+	CosignCertificateIdentityRegexp   string `json:"cosignCertificateIdentityRegexp,omitempty"`
+	CosignCertificateOidcIssuer       string `json:"cosignCertificateOidcIssuer,omitempty"`
+	CosignCertificateOidcIssuerRegexp string `json:"cosignCertificateOidcIssuerRegexp,omitempty"`
+}
+
+// LoadPolicy loads and validates the trust policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse the trust policy %q: %w", path, err)
+	}
+	scopes := make(map[string]struct{})
+	for _, r := range p.Rules {
+		// "docker.io/" is the same scope as "docker.io" for Match
+		scope := strings.TrimSuffix(r.Scope, "/")
+		if scope == "" {
+			return nil, fmt.Errorf("invalid trust policy %q: a rule without scope", path)
+		}
+		if _, ok := scopes[scope]; ok {
+			return nil, fmt.Errorf("invalid trust policy %q: multiple rules for scope %q", path, scope)
+		}
+		scopes[scope] = struct{}{}
+		switch r.Provider {
+		case "none", "cosign", "notation":
+		default:
+			return nil, fmt.Errorf("invalid trust policy %q: unknown provider %q for scope %q (must be none, cosign, or notation)", path, r.Provider, r.Scope)
+		}
+	}
+	return &p, nil
+}
+
+// Match returns the rule with the most specific scope for the image reference, or nil.
+// A scope matches the repository itself and the repositories under it, at the boundaries of the path components.
+func (p *Policy) Match(rawRef string) (*PolicyRule, error) {
+	named, err := refdocker.ParseDockerRef(rawRef)
+	if err != nil {
+		return nil, err
+	}
+	repo := named.Name()
+	var matched *PolicyRule
+	for i, r := range p.Rules {
+		scope := strings.TrimSuffix(r.Scope, "/")
+		if repo != scope && !strings.HasPrefix(repo, scope+"/") {
+			continue
+		}
+		if matched == nil || len(scope) > len(strings.TrimSuffix(matched.Scope, "/")) {
+			matched = &p.Rules[i]
+		}
+	}
+	return matched, nil
+}
+
+// VerifyOptions returns the options of Verify for the rule.
+func (r *PolicyRule) VerifyOptions() types.ImageVerifyOptions {
+	return types.ImageVerifyOptions{
+		Provider:                          r.Provider,
+		CosignKey:                         r.CosignKey,
+		CosignCertificateIdentity:         r.CosignCertificateIdentity,
+		CosignCertificateIdentityRegexp:   r.CosignCertificateIdentityRegexp,
+		CosignCertificateOidcIssuer:       r.CosignCertificateOidcIssuer,
+		CosignCertificateOidcIssuerRegexp: r.CosignCertificateOidcIssuerRegexp,
+	}
+}
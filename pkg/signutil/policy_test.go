@@ -0,0 +1,81 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package signutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPolicyMatch(t *testing.T) {
+	p := &Policy{Rules: []PolicyRule{
+		{Scope: "docker.io", Provider: "notation"},
+		{Scope: "ghcr.io/example", Provider: "cosign", CosignKey: "example.pub"},
+		{Scope: "ghcr.io/example/keyless/", Provider: "cosign"},
+	}}
+	for _, tc := range []struct {
+		ref   string
+		scope string // empty for no match
+	}{
+		{"alpine", "docker.io"},
+		{"ghcr.io/example/foo:latest", "ghcr.io/example"},
+		{"ghcr.io/example:latest", "ghcr.io/example"},
+		{"ghcr.io/example/keyless/foo", "ghcr.io/example/keyless/"},
+		// not at the boundary of a path component
+		{"ghcr.io/example-other/foo", ""},
+		{"example.com/foo", ""},
+	} {
+		rule, err := p.Match(tc.ref)
+		assert.NilError(t, err)
+		if tc.scope == "" {
+			assert.Assert(t, rule == nil, tc.ref)
+			continue
+		}
+		assert.Assert(t, rule != nil, tc.ref)
+		assert.Equal(t, rule.Scope, tc.scope, tc.ref)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		content string
+		err     string // empty for a valid policy
+	}{
+		{`{"rules": [{"scope": "docker.io", "provider": "cosign", "cosignKey": "key.pub"}]}`, ""},
+		{`{"rules": [{"provider": "cosign"}]}`, "a rule without scope"},
+		{`{"rules": [{"scope": "docker.io", "provider": "gpg"}]}`, `unknown provider "gpg"`},
+		{`{"rules": [{"scope": "docker.io", "provider": "none"}, {"scope": "docker.io", "provider": "cosign"}]}`, "multiple rules"},
+		{`{"rules": [{"scope": "docker.io", "provider": "none"}, {"scope": "docker.io/", "provider": "cosign"}]}`, "multiple rules"},
+		{`{"rules": [{"scope": "/", "provider": "cosign"}]}`, "a rule without scope"},
+		{`{"rules": `, "failed to parse"},
+	} {
+		path := filepath.Join(dir, "policy.json")
+		assert.NilError(t, os.WriteFile(path, []byte(tc.content), 0600))
+		p, err := LoadPolicy(path)
+		if tc.err == "" {
+			assert.NilError(t, err)
+			assert.Equal(t, len(p.Rules), 1)
+			assert.Equal(t, p.Rules[0].VerifyOptions().CosignKey, "key.pub")
+			continue
+		}
+		assert.ErrorContains(t, err, tc.err)
+	}
+}
@@ -38,8 +38,10 @@ import (
 )
 
 // NewTask is from https://github.com/containerd/containerd/blob/v1.4.3/cmd/ctr/commands/tasks/tasks_unix.go#L70-L108
+//
+// stdin is read when flagI is set without flagT. os.Stdin is used when stdin is nil.
 func NewTask(ctx context.Context, client *containerd.Client, container containerd.Container,
-	flagA, flagI, flagT, flagD bool, con console.Console, logURI, detachKeys, namespace string, detachC chan<- struct{}) (containerd.Task, error) {
+	flagA, flagI, flagT, flagD bool, con console.Console, stdin *os.File, logURI, detachKeys, namespace string, detachC chan<- struct{}) (containerd.Task, error) {
 	var t containerd.Task
 	closer := func() {
 		if detachC != nil {
@@ -125,8 +127,11 @@ func NewTask(ctx context.Context, client *containerd.Client, container container
 			} else if sv.LessThan(semver.MustParse("1.6.0-0")) {
 				log.G(ctx).Warnf("`nerdctl (run|exec) -i` without `-t` expects containerd 1.6 or later, got containerd %v", sv)
 			}
+			if stdin == nil {
+				stdin = os.Stdin
+			}
 			var stdinC io.ReadCloser = &StdinCloser{
-				Stdin: os.Stdin,
+				Stdin: stdin,
 				Closer: func() {
 					if t, err := container.Task(ctx, nil); err != nil {
 						log.G(ctx).WithError(err).Debugf("failed to get task for StdinCloser")